@@ -3,6 +3,7 @@ package api
 import (
 	"bank-account-manager/routes"
 	s "bank-account-manager/server"
+	"log"
 	"net/http"
 
 	"github.com/gofiber/adaptor/v2"
@@ -13,7 +14,15 @@ var (
 )
 
 func init() {
-	server = s.Create()
+	config, err := s.LoadConfig()
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	server, err = s.Create(config)
+	if err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
 
 	routes.ConfigRoutes(server)
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"bank-account-manager/routes"
 	"bank-account-manager/server"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
 	"os"
 	"log"
 
@@ -21,10 +23,32 @@ func main() {
 		port = "3000"
 	}
 
-	server := server.Create()
+	config, err := server.LoadConfig()
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	server, err := server.Create(config)
+	if err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
 
 	routes.ConfigRoutes(server)
 
+	// Delete expired idempotency keys in the background while the server runs
+	if config.IdempotencySweep > 0 {
+		idempotency := services.CreateIdempotencyService(server.Storage)
+		stop := idempotency.Schedule(config.IdempotencySweep)
+		defer stop()
+	}
+
+	// Accrue interest in the background while the server runs
+	if config.InterestInterval > 0 {
+		interest := services.CreateInterestService(server.Storage, utils.SystemClock{})
+		stop := interest.Schedule(config.InterestInterval)
+		defer stop()
+	}
+
 	if err := server.Listen(port); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
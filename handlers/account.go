@@ -3,6 +3,7 @@ package handlers
 
 // Import necessary packages for handling HTTP requests, responses, and services
 import (
+	"bank-account-manager/models"
 	"bank-account-manager/requests"
 	"bank-account-manager/responses"
 	"bank-account-manager/server"
@@ -30,9 +31,13 @@ func CreateAccountHandler(server *server.Server) *AccountHandler {
 // @Tags Accounts
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
 // @Param account body requests.AccountRequest true "Account details"
 // @Success 201 {object} responses.Account
 // @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
 // @Failure 500 {object} responses.Error
 // @Router /accounts [post]
 func (handler *AccountHandler) Create(context *fiber.Ctx) error {
@@ -51,6 +56,31 @@ func (handler *AccountHandler) Create(context *fiber.Ctx) error {
 	// Attempt to create account using service layer
 	account, err := handler.AccountService.Create(request)
 	if err != nil {
+		// Handle invalid amounts and currencies with a client error
+		switch err {
+		case utils.ErrInvalidAmount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+		case utils.ErrAmountPrecision:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+		case utils.ErrInvalidCurrency:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCurrency)
+		case utils.ErrInvalidAccountType:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+		case utils.ErrInvalidMaturity:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidMaturity)
+		case utils.ErrOverdraftNotAllowed:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgOverdraftNotAllowed)
+		case utils.ErrInvalidInterestRate:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidInterestRate)
+		case utils.ErrInvalidDayCount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+		case utils.ErrInterestNotAllowed:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInterestNotAllowed)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCustomerUUID)
+		case utils.ErrCustomerNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgCustomerNotFound)
+		}
 		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedCreateAccount)
 	}
 
@@ -95,21 +125,263 @@ func (handler *AccountHandler) ReadOne(context *fiber.Ctx) error {
 }
 
 // ReadAccounts godoc
-// @Summary Get all bank accounts
-// @Description Retrieves all bank accounts' details
+// @Summary Get bank accounts
+// @Description Retrieves one page of bank accounts, sorted by creation time or balance.
+// @Description Filtering or sorting by balance requires a currency.
+// @Description Pass the returned next_cursor as cursor to fetch the following page
 // @Tags Accounts
 // @Accept json
 // @Produce json
-// @Success 200 {array} responses.Account
+// @Param query query requests.AccountQuery false "Search, filters, sort order and page"
+// @Success 200 {object} responses.AccountPage
+// @Failure 400 {object} responses.Error
 // @Failure 500 {object} responses.Error
 // @Router /accounts [get]
 func (handler *AccountHandler) ReadAll(context *fiber.Ctx) error {
-	// Attempt to retrieve all accounts using service layer
-	accounts, err := handler.AccountService.ReadAll()
+	// Parse and validate the filters of the listing
+	query := requests.AccountQuery{}
+	if err := context.QueryParser(&query); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidQuery)
+	}
+	if err := query.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to retrieve the accounts using service layer
+	page, err := handler.AccountService.Search(query)
 	if err != nil {
+		switch err {
+		case utils.ErrInvalidAmount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+		case utils.ErrAmountPrecision:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+		case utils.ErrInvalidCurrency:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCurrency)
+		case utils.ErrCurrencyRequired:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgCurrencyRequired)
+		case utils.ErrInvalidQuery:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidQuery)
+		case utils.ErrInvalidCursor:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCursor)
+		}
 		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedRetrieveAccounts)
 	}
 
-	// Return successful response with all accounts
-	return responses.AccountResponses(context, http.StatusOK, accounts)
+	// Return successful response with the page of accounts
+	return responses.AccountPageResponse(context, http.StatusOK, page)
+}
+
+// UpdateAccount godoc
+// @Summary Update a bank account
+// @Description Changes the owner and customer of a bank account that is not closed.
+// @Description Only an owner of an account, or its customer while it has no holders, may link it to another customer
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Customer linking the account to another customer"
+// @Param id path string true "Account ID"
+// @Param account body requests.AccountUpdateRequest true "Account details"
+// @Success 200 {object} responses.Account
+// @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id} [patch]
+func (handler *AccountHandler) Update(context *fiber.Ctx) error {
+	// Extract account ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Parse request body into AccountUpdateRequest struct
+	request := requests.AccountUpdateRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+
+	// Validate the request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+	request.ActingCustomerID = context.Get(requests.ActingCustomerHeader)
+
+	// Attempt to update the account using service layer
+	account, err := handler.AccountService.Update(id, request)
+	if err != nil {
+		return accountChangeError(context, err)
+	}
+
+	// Return successful response with updated account details
+	return responses.AccountResponse(context, http.StatusOK, account)
+}
+
+// SetOverdraft godoc
+// @Summary Set the overdraft limit of a bank account
+// @Description Lets the balance of a checking account go below zero down to the limit. Zero removes the overdraft.
+// @Description The limit cannot be lower than what the account already overdrew
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param id path string true "Account ID"
+// @Param overdraft body requests.OverdraftRequest true "Overdraft limit"
+// @Success 200 {object} responses.Account
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/overdraft [put]
+func (handler *AccountHandler) SetOverdraft(context *fiber.Ctx) error {
+	// Extract account ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Parse request body into OverdraftRequest struct
+	request := requests.OverdraftRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+
+	// Validate the request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to change the overdraft limit using service layer
+	account, err := handler.AccountService.SetOverdraft(id, request)
+	if err != nil {
+		return accountChangeError(context, err)
+	}
+
+	// Return successful response with updated account details
+	return responses.AccountResponse(context, http.StatusOK, account)
+}
+
+// FreezeAccount godoc
+// @Summary Freeze a bank account
+// @Description Stops an active account from sending money. It can still receive deposits and transfers
+// @Tags Accounts
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param id path string true "Account ID"
+// @Success 200 {object} responses.Account
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/freeze [post]
+func (handler *AccountHandler) Freeze(context *fiber.Ctx) error {
+	return handler.transition(context, handler.AccountService.Freeze)
+}
+
+// UnfreezeAccount godoc
+// @Summary Unfreeze a bank account
+// @Description Lets a frozen account send money again
+// @Tags Accounts
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param id path string true "Account ID"
+// @Success 200 {object} responses.Account
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/unfreeze [post]
+func (handler *AccountHandler) Unfreeze(context *fiber.Ctx) error {
+	return handler.transition(context, handler.AccountService.Unfreeze)
+}
+
+// CloseAccount godoc
+// @Summary Close a bank account
+// @Description Closes an active or frozen account with a zero balance and no pending holds. Closed accounts accept no transactions
+// @Tags Accounts
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param id path string true "Account ID"
+// @Success 200 {object} responses.Account
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/close [post]
+func (handler *AccountHandler) Close(context *fiber.Ctx) error {
+	return handler.transition(context, handler.AccountService.Close)
+}
+
+// ReopenAccount godoc
+// @Summary Reopen a bank account
+// @Description Makes a closed account active again
+// @Tags Accounts
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param id path string true "Account ID"
+// @Success 200 {object} responses.Account
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/reopen [post]
+func (handler *AccountHandler) Reopen(context *fiber.Ctx) error {
+	return handler.transition(context, handler.AccountService.Reopen)
+}
+
+// transition changes the status of the account in the path with the given
+// service method
+func (handler *AccountHandler) transition(context *fiber.Ctx, change func(id string) (models.Account, error)) error {
+	// Extract account ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to change the status using service layer
+	account, err := change(id)
+	if err != nil {
+		return accountChangeError(context, err)
+	}
+
+	// Return successful response with updated account details
+	return responses.AccountResponse(context, http.StatusOK, account)
+}
+
+// accountChangeError maps the errors of account changes to responses
+func accountChangeError(context *fiber.Ctx, err error) error {
+	switch err {
+	case utils.ErrInvalidUUID:
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+	case utils.ErrAccountNotFound:
+		return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
+	case utils.ErrCustomerNotFound:
+		return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgCustomerNotFound)
+	case utils.ErrAccountClosed:
+		return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountClosed)
+	case utils.ErrAccountNotEmpty:
+		return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountNotEmpty)
+	case utils.ErrAccountHasHolds:
+		return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountHasHolds)
+	case utils.ErrHolderNotPermitted:
+		return responses.ErrorResponse(context, http.StatusForbidden, utils.MsgHolderNotPermitted)
+	case utils.ErrInvalidStatusTransition:
+		return responses.ErrorResponse(context, http.StatusConflict, utils.MsgInvalidStatusChange)
+	case utils.ErrInvalidAmount:
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+	case utils.ErrAmountPrecision:
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+	case utils.ErrOverdraftNotAllowed:
+		return responses.ErrorResponse(context, http.StatusConflict, utils.MsgOverdraftNotAllowed)
+	case utils.ErrOverdraftInUse:
+		return responses.ErrorResponse(context, http.StatusConflict, utils.MsgOverdraftInUse)
+	}
+	return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedUpdateAccount)
 }
@@ -0,0 +1,266 @@
+// Package handlers contains HTTP request handlers for the bank account manager
+package handlers
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/server"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CustomerHandler struct holds the customer service
+type CustomerHandler struct {
+	CustomerService *services.CustomerService
+}
+
+// CreateCustomerHandler initializes a new CustomerHandler with the server's
+// storage and rate table
+func CreateCustomerHandler(server *server.Server) *CustomerHandler {
+	customerService := services.CreateCustomerService(server.Storage)
+	customerService.Exchange = server.Exchange
+	return &CustomerHandler{
+		CustomerService: customerService,
+	}
+}
+
+// CreateCustomer godoc
+// @Summary Create a new customer
+// @Description Creates a new customer who can hold accounts
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param customer body requests.CustomerRequest true "Customer details"
+// @Success 201 {object} responses.Customer
+// @Failure 400 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /customers [post]
+func (handler *CustomerHandler) Create(context *fiber.Ctx) error {
+	// Parse request body into CustomerRequest struct
+	request := requests.CustomerRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+
+	// Validate the request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to create customer using service layer
+	customer, err := handler.CustomerService.Create(request)
+	if err != nil {
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedCreateCustomer)
+	}
+
+	// Return successful response with created customer details
+	return responses.CustomerResponse(context, http.StatusCreated, customer)
+}
+
+// ReadCustomer godoc
+// @Summary Get a customer by ID
+// @Description Retrieves a customer's details by their ID
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} responses.Customer
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /customers/{id} [get]
+func (handler *CustomerHandler) ReadOne(context *fiber.Ctx) error {
+	// Extract customer ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to retrieve customer using service layer
+	customer, err := handler.CustomerService.ReadOne(id)
+	if err != nil {
+		return customerError(context, err, utils.MsgFailedRetrieveCustomer)
+	}
+
+	// Return successful response with customer details
+	return responses.CustomerResponse(context, http.StatusOK, customer)
+}
+
+// ReadCustomers godoc
+// @Summary Get all customers
+// @Description Retrieves every customer in creation order
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Success 200 {array} responses.Customer
+// @Failure 500 {object} responses.Error
+// @Router /customers [get]
+func (handler *CustomerHandler) ReadAll(context *fiber.Ctx) error {
+	// Attempt to retrieve the customers using service layer
+	customers, err := handler.CustomerService.ReadAll()
+	if err != nil {
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedRetrieveCustomers)
+	}
+
+	// Return successful response with the customers
+	return responses.CustomerResponses(context, http.StatusOK, customers)
+}
+
+// UpdateCustomer godoc
+// @Summary Update a customer
+// @Description Replaces the name and email of a customer
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param id path string true "Customer ID"
+// @Param customer body requests.CustomerRequest true "Customer details"
+// @Success 200 {object} responses.Customer
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /customers/{id} [patch]
+func (handler *CustomerHandler) Update(context *fiber.Ctx) error {
+	// Extract customer ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Parse request body into CustomerRequest struct
+	request := requests.CustomerRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+
+	// Validate the request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to update the customer using service layer
+	customer, err := handler.CustomerService.Update(id, request)
+	if err != nil {
+		return customerError(context, err, utils.MsgFailedUpdateCustomer)
+	}
+
+	// Return successful response with updated customer details
+	return responses.CustomerResponse(context, http.StatusOK, customer)
+}
+
+// DeleteCustomer godoc
+// @Summary Delete a customer
+// @Description Deletes a customer who no longer holds any account
+// @Tags Customers
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param id path string true "Customer ID"
+// @Success 204
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /customers/{id} [delete]
+func (handler *CustomerHandler) Delete(context *fiber.Ctx) error {
+	// Extract customer ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to delete the customer using service layer
+	if err := handler.CustomerService.Delete(id); err != nil {
+		return customerError(context, err, utils.MsgFailedDeleteCustomer)
+	}
+
+	return context.SendStatus(http.StatusNoContent)
+}
+
+// ReadCustomerAccounts godoc
+// @Summary Get the accounts of a customer
+// @Description Retrieves every account held by a customer in creation order
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {array} responses.Account
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /customers/{id}/accounts [get]
+func (handler *CustomerHandler) ReadAccounts(context *fiber.Ctx) error {
+	// Extract customer ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to retrieve the accounts using service layer
+	accounts, err := handler.CustomerService.ReadAccounts(id)
+	if err != nil {
+		return customerError(context, err, utils.MsgFailedRetrieveAccounts)
+	}
+
+	// Return successful response with the accounts
+	return responses.AccountResponses(context, http.StatusOK, accounts)
+}
+
+// ReadNetWorth godoc
+// @Summary Get the net worth of a customer
+// @Description Sums the balances of every account of a customer per currency.
+// @Description When a currency is given the sums are also converted with the exchange rates and totalled
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param currency query string false "Currency of the total" example(USD)
+// @Success 200 {object} responses.NetWorth
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /customers/{id}/net-worth [get]
+func (handler *CustomerHandler) NetWorth(context *fiber.Ctx) error {
+	// Extract customer ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to compute the net worth using service layer
+	netWorth, err := handler.CustomerService.NetWorth(id, context.Query("currency"))
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidCurrency:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCurrency)
+		case utils.ErrRateNotFound:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgRateNotFound)
+		}
+		return customerError(context, err, utils.MsgFailedRetrieveCustomer)
+	}
+
+	// Return successful response with the net worth
+	return responses.NetWorthResponse(context, http.StatusOK, netWorth)
+}
+
+// customerError maps the errors of customer lookups and changes to
+// responses, falling back to a server error with the given message
+func customerError(context *fiber.Ctx, err error, message string) error {
+	switch err {
+	case utils.ErrInvalidUUID:
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCustomerUUID)
+	case utils.ErrCustomerNotFound:
+		return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgCustomerNotFound)
+	case utils.ErrCustomerHasAccounts:
+		return responses.ErrorResponse(context, http.StatusConflict, utils.MsgCustomerHasAccounts)
+	}
+	return responses.ErrorResponse(context, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/server"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EventHandler struct holds the event service
+type EventHandler struct {
+	EventService *services.EventService
+}
+
+// CreateEventHandler initializes a new EventHandler with the server's storage
+func CreateEventHandler(server *server.Server) *EventHandler {
+	return &EventHandler{
+		EventService: services.CreateEventService(server.Storage),
+	}
+}
+
+// ReadByAccount godoc
+// @Summary Get account events
+// @Description Retrieves the immutable history of the specified bank account, including the transfers it received, in the order the events happened
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {array} responses.Event
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/events [get]
+func (handler *EventHandler) ReadByAccount(context *fiber.Ctx) error {
+	// Extract account ID from request parameters
+	accountID := context.Params("id")
+	if accountID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to retrieve events using service layer
+	events, err := handler.EventService.ReadByAccount(accountID)
+	if err != nil {
+		// Handle various error cases with appropriate status codes
+		switch err {
+		case utils.ErrAccountNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedRetrieveEvents)
+		}
+	}
+
+	// Return successful response with the events
+	return responses.EventResponses(context, http.StatusOK, events)
+}
+
+// Balance godoc
+// @Summary Get the projected account balance
+// @Description Computes the balance, held funds, overdraft and status of the specified bank account by replaying its events, as they stood at as_of when given. Replay starts from the latest balance checkpoint before that time. The version is the sequence of the last event applied
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param as_of query string false "RFC 3339 time to read the balance at, now when empty"
+// @Success 200 {object} responses.AccountBalance
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/balance [get]
+func (handler *EventHandler) Balance(context *fiber.Ctx) error {
+	// Extract account ID from request parameters
+	accountID := context.Params("id")
+	if accountID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Parse the time to read the balance at
+	query := requests.BalanceQuery{}
+	if err := context.QueryParser(&query); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidQuery)
+	}
+
+	// Attempt to project the balance using service layer
+	balance, err := handler.EventService.Balance(accountID, query)
+	if err != nil {
+		// Handle various error cases with appropriate status codes
+		switch err {
+		case utils.ErrAccountNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
+		case utils.ErrNotOpenAsOf:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgNotOpenAsOf)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+		case utils.ErrInvalidQuery:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidQuery)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedProjectBalance)
+		}
+	}
+
+	// Return successful response with the projected balance
+	return responses.AccountBalanceResponse(context, http.StatusOK, balance)
+}
+
+// RebuildBalances godoc
+// @Summary Rebuild the balance projection
+// @Description Replays the whole event store from the first event into an empty balance projection and returns the balance of every account in the order they were opened
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Success 200 {array} responses.AccountBalance
+// @Failure 401 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Failure 503 {object} responses.Error
+// @Router /admin/projections/balances/rebuild [post]
+func (handler *EventHandler) RebuildBalances(context *fiber.Ctx) error {
+	// Attempt to replay the event store using service layer
+	balances, err := handler.EventService.RebuildBalances()
+	if err != nil {
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedProjectBalance)
+	}
+
+	// Return successful response with the rebuilt balances
+	return responses.AccountBalanceResponses(context, http.StatusOK, balances)
+}
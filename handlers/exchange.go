@@ -0,0 +1,91 @@
+// Package handlers contains HTTP request handlers for the bank account manager
+package handlers
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/server"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExchangeHandler struct holds the exchange service shared by the server
+type ExchangeHandler struct {
+	ExchangeService *services.ExchangeService
+}
+
+// CreateExchangeHandler initializes a new ExchangeHandler with the server's rate table
+func CreateExchangeHandler(server *server.Server) *ExchangeHandler {
+	return &ExchangeHandler{
+		ExchangeService: server.Exchange,
+	}
+}
+
+// ReadAll godoc
+// @Summary Get exchange rates
+// @Description Retrieves the exchange rates used for cross-currency transfers
+// @Tags Exchange Rates
+// @Accept json
+// @Produce json
+// @Success 200 {array} responses.ExchangeRate
+// @Failure 500 {object} responses.Error
+// @Router /exchange-rates [get]
+func (handler *ExchangeHandler) ReadAll(context *fiber.Ctx) error {
+	// Attempt to retrieve the rate table using service layer
+	rates, err := handler.ExchangeService.ReadAll()
+	if err != nil {
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedRetrieveRates)
+	}
+
+	// Return successful response with all rates
+	return responses.ExchangeRateResponses(context, http.StatusOK, rates)
+}
+
+// Update godoc
+// @Summary Update exchange rates
+// @Description Adds or replaces exchange rates. The rate of a pair also converts the opposite direction unless that pair has its own rate
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param rates body requests.ExchangeRatesRequest true "Exchange rates"
+// @Success 200 {array} responses.ExchangeRate
+// @Failure 400 {object} responses.Error
+// @Failure 401 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Failure 503 {object} responses.Error
+// @Router /admin/exchange-rates [put]
+func (handler *ExchangeHandler) Update(context *fiber.Ctx) error {
+	// Parse request body into ExchangeRatesRequest struct
+	request := requests.ExchangeRatesRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+
+	// Validate the request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to update the rate table using service layer
+	rates, err := handler.ExchangeService.Update(request)
+	if err != nil {
+		// Handle invalid rates and currencies with a client error
+		switch err {
+		case utils.ErrInvalidRate:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRate)
+		case utils.ErrInvalidCurrency:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCurrency)
+		}
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedUpdateRates)
+	}
+
+	// Return successful response with the updated table
+	return responses.ExchangeRateResponses(context, http.StatusOK, rates)
+}
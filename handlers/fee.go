@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/server"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FeeHandler struct holds the fee service shared by the server
+type FeeHandler struct {
+	FeeService *services.FeeService
+}
+
+// CreateFeeHandler initializes a new FeeHandler with the server's fee schedule
+func CreateFeeHandler(server *server.Server) *FeeHandler {
+	return &FeeHandler{
+		FeeService: server.Fees,
+	}
+}
+
+// ReadAll godoc
+// @Summary Get the fee schedule
+// @Description Retrieves the fees charged for deposits, withdrawals and transfers per account type
+// @Tags Fees
+// @Accept json
+// @Produce json
+// @Success 200 {array} responses.FeeRule
+// @Failure 500 {object} responses.Error
+// @Router /fees [get]
+func (handler *FeeHandler) ReadAll(context *fiber.Ctx) error {
+	// Attempt to retrieve the fee schedule using service layer
+	rules, err := handler.FeeService.ReadAll()
+	if err != nil {
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedRetrieveFees)
+	}
+
+	// Return successful response with every rule
+	return responses.FeeRuleResponses(context, http.StatusOK, rules)
+}
+
+// Update godoc
+// @Summary Replace the fee schedule
+// @Description Replaces every fee rule. A rule prices an operation (deposit, withdrawal or transfer) on an account type, or on every type when none is given, as a flat fee plus a rate of the amount, or by tiers of amounts, kept between an optional minimum and maximum
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param schedule body requests.FeeScheduleRequest true "Fee rules"
+// @Success 200 {array} responses.FeeRule
+// @Failure 400 {object} responses.Error
+// @Failure 401 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Failure 503 {object} responses.Error
+// @Router /admin/fees [put]
+func (handler *FeeHandler) Update(context *fiber.Ctx) error {
+	// Parse request body into FeeScheduleRequest struct
+	request := requests.FeeScheduleRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+
+	// Validate the request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to replace the fee schedule using service layer
+	rules, err := handler.FeeService.Update(request)
+	if err != nil {
+		if err == utils.ErrInvalidFeeRule {
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidFeeRule)
+		}
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedUpdateFees)
+	}
+
+	// Return successful response with the new schedule
+	return responses.FeeRuleResponses(context, http.StatusOK, rules)
+}
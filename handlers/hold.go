@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PlaceHold godoc
+// @Summary Place a hold on an account
+// @Description Reserves an amount of the available balance of the account until the hold is captured or released.
+// @Description Pending holds expire after the configured duration and free their funds
+// @Tags Holds
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Customer placing a hold on an account with holders"
+// @Param hold body requests.HoldRequest true "Hold details"
+// @Success 201 {object} responses.Hold
+// @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/holds [post]
+func (handler *TransactionHandler) PlaceHold(context *fiber.Ctx) error {
+	// Extract account ID from request parameters
+	accountID := context.Params("id")
+	if accountID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Initialize and parse hold request from request body
+	request := requests.HoldRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+	request.ActingCustomerID = context.Get(requests.ActingCustomerHeader)
+
+	// Validate the hold request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to place the hold using service layer
+	hold, err := handler.TransactionService.PlaceHold(accountID, request)
+	if err != nil {
+		// Handle various hold-specific errors with appropriate status codes
+		switch err {
+		case utils.ErrInsufficientFunds:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInsufficientFunds)
+		case utils.ErrAccountFrozen:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountFrozen)
+		case utils.ErrAccountClosed:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountClosed)
+		case utils.ErrAccountLocked:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountLocked)
+		case utils.ErrWithdrawalLimit:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgWithdrawalLimit)
+		case utils.ErrInvalidAmount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+		case utils.ErrAmountPrecision:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+		case utils.ErrInvalidCurrency:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCurrency)
+		case utils.ErrCurrencyMismatch:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgCurrencyMismatch)
+		case utils.ErrAccountNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+		case utils.ErrHolderNotPermitted:
+			return responses.ErrorResponse(context, http.StatusForbidden, utils.MsgHolderNotPermitted)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedPlaceHold)
+		}
+	}
+
+	// Return successful response with the placed hold
+	return responses.HoldResponse(context, http.StatusCreated, hold)
+}
+
+// ReadHolds godoc
+// @Summary Get account holds
+// @Description Retrieves every hold of the specified bank account in the order they were placed
+// @Tags Holds
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {array} responses.Hold
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/holds [get]
+func (handler *TransactionHandler) ReadHolds(context *fiber.Ctx) error {
+	// Extract account ID from request parameters
+	accountID := context.Params("id")
+	if accountID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to retrieve holds using service layer
+	holds, err := handler.TransactionService.ReadHolds(accountID)
+	if err != nil {
+		// Handle various error cases with appropriate status codes
+		switch err {
+		case utils.ErrAccountNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedRetrieveHolds)
+		}
+	}
+
+	// Return successful response with the holds
+	return responses.HoldResponses(context, http.StatusOK, holds)
+}
+
+// CaptureHold godoc
+// @Summary Capture a hold
+// @Description Settles a pending hold as a withdrawal of its whole amount, or of the amount given, and frees the rest.
+// @Description The withdrawal follows the account rules and fee schedule like any other
+// @Tags Holds
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Customer capturing a hold on an account with holders"
+// @Param capture body requests.CaptureRequest false "Amount to capture"
+// @Success 200 {object} responses.Hold
+// @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /holds/{id}/capture [post]
+func (handler *TransactionHandler) CaptureHold(context *fiber.Ctx) error {
+	// Extract hold ID from request parameters
+	holdID := context.Params("id")
+	if holdID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Initialize and parse the optional capture request from request body
+	request := requests.CaptureRequest{}
+	if len(context.Body()) > 0 {
+		if err := context.BodyParser(&request); err != nil {
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+		}
+	}
+	request.ActingCustomerID = context.Get(requests.ActingCustomerHeader)
+
+	// Attempt to capture the hold using service layer
+	hold, err := handler.TransactionService.CaptureHold(holdID, request)
+	if err != nil {
+		// Handle various capture-specific errors with appropriate status codes
+		switch err {
+		case utils.ErrHoldNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgHoldNotFound)
+		case utils.ErrHoldNotPending:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgHoldNotPending)
+		case utils.ErrCaptureExceedsHold:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgCaptureExceedsHold)
+		case utils.ErrInsufficientFunds:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInsufficientFunds)
+		case utils.ErrAccountFrozen:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountFrozen)
+		case utils.ErrAccountClosed:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountClosed)
+		case utils.ErrAccountLocked:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountLocked)
+		case utils.ErrWithdrawalLimit:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgWithdrawalLimit)
+		case utils.ErrInvalidAmount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+		case utils.ErrAmountPrecision:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidHoldUUID)
+		case utils.ErrHolderNotPermitted:
+			return responses.ErrorResponse(context, http.StatusForbidden, utils.MsgHolderNotPermitted)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedUpdateHold)
+		}
+	}
+
+	// Return successful response with the captured hold
+	return responses.HoldResponse(context, http.StatusOK, hold)
+}
+
+// ReleaseHold godoc
+// @Summary Release a hold
+// @Description Frees the funds reserved by a pending hold without moving any money
+// @Tags Holds
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Customer releasing a hold on an account with holders"
+// @Success 200 {object} responses.Hold
+// @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /holds/{id}/release [post]
+func (handler *TransactionHandler) ReleaseHold(context *fiber.Ctx) error {
+	// Extract hold ID from request parameters
+	holdID := context.Params("id")
+	if holdID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to release the hold using service layer
+	hold, err := handler.TransactionService.ReleaseHold(holdID, context.Get(requests.ActingCustomerHeader))
+	if err != nil {
+		// Handle various release-specific errors with appropriate status codes
+		switch err {
+		case utils.ErrHoldNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgHoldNotFound)
+		case utils.ErrHoldNotPending:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgHoldNotPending)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidHoldUUID)
+		case utils.ErrHolderNotPermitted:
+			return responses.ErrorResponse(context, http.StatusForbidden, utils.MsgHolderNotPermitted)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedUpdateHold)
+		}
+	}
+
+	// Return successful response with the released hold
+	return responses.HoldResponse(context, http.StatusOK, hold)
+}
@@ -0,0 +1,160 @@
+// Package handlers contains HTTP request handlers for the bank account manager
+package handlers
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/server"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HolderHandler struct holds the account holder service
+type HolderHandler struct {
+	HolderService *services.HolderService
+}
+
+// CreateHolderHandler initializes a new HolderHandler with the server's storage
+func CreateHolderHandler(server *server.Server) *HolderHandler {
+	return &HolderHandler{
+		HolderService: services.CreateHolderService(server.Storage),
+	}
+}
+
+// ReadHolders godoc
+// @Summary Get the holders of a bank account
+// @Description Retrieves the customers holding an account and their roles
+// @Tags Account Holders
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {array} responses.AccountHolder
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/holders [get]
+func (handler *HolderHandler) ReadAll(context *fiber.Ctx) error {
+	// Extract account ID from request parameters
+	id := context.Params("id")
+	if id == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to retrieve the holders using service layer
+	holders, err := handler.HolderService.ReadAll(id)
+	if err != nil {
+		return holderError(context, err, utils.MsgFailedRetrieveHolders)
+	}
+
+	// Return successful response with the holders
+	return responses.AccountHolderResponses(context, http.StatusOK, holders)
+}
+
+// SaveHolder godoc
+// @Summary Add or change a holder of a bank account
+// @Description Adds a customer to the holders of an account with a role, or changes their role.
+// @Description Owners may debit the account and manage its holders, signatories may debit it and view-only holders may not.
+// @Description Once an account has holders the acting customer must be one of its owners, and it must keep at least one owner
+// @Tags Account Holders
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Owner of the account making the change"
+// @Param id path string true "Account ID"
+// @Param customerId path string true "Customer ID"
+// @Param holder body requests.HolderRequest true "Holder role"
+// @Success 200 {object} responses.AccountHolder
+// @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/holders/{customerId} [put]
+func (handler *HolderHandler) Save(context *fiber.Ctx) error {
+	// Extract account and customer IDs from request parameters
+	id := context.Params("id")
+	customerID := context.Params("customerId")
+	if id == "" || customerID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Parse request body into HolderRequest struct
+	request := requests.HolderRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+
+	// Validate the request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to save the holder using service layer
+	holder, err := handler.HolderService.Save(id, customerID, request, context.Get(requests.ActingCustomerHeader))
+	if err != nil {
+		return holderError(context, err, utils.MsgFailedUpdateHolders)
+	}
+
+	// Return successful response with the holder
+	return responses.AccountHolderResponse(context, http.StatusOK, holder)
+}
+
+// DeleteHolder godoc
+// @Summary Remove a holder of a bank account
+// @Description Removes a customer from the holders of an account. The acting customer must be one of its owners,
+// @Description and the account must keep at least one owner
+// @Tags Account Holders
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Owner of the account making the change"
+// @Param id path string true "Account ID"
+// @Param customerId path string true "Customer ID"
+// @Success 204
+// @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /accounts/{id}/holders/{customerId} [delete]
+func (handler *HolderHandler) Delete(context *fiber.Ctx) error {
+	// Extract account and customer IDs from request parameters
+	id := context.Params("id")
+	customerID := context.Params("customerId")
+	if id == "" || customerID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to remove the holder using service layer
+	if err := handler.HolderService.Delete(id, customerID, context.Get(requests.ActingCustomerHeader)); err != nil {
+		return holderError(context, err, utils.MsgFailedUpdateHolders)
+	}
+
+	return context.SendStatus(http.StatusNoContent)
+}
+
+// holderError maps the errors of account holder lookups and changes to
+// responses, falling back to a server error with the given message
+func holderError(context *fiber.Ctx, err error, message string) error {
+	switch err {
+	case utils.ErrInvalidUUID:
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+	case utils.ErrInvalidRequestBody:
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	case utils.ErrAccountNotFound:
+		return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
+	case utils.ErrCustomerNotFound:
+		return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgCustomerNotFound)
+	case utils.ErrHolderNotFound:
+		return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgHolderNotFound)
+	case utils.ErrHolderNotPermitted:
+		return responses.ErrorResponse(context, http.StatusForbidden, utils.MsgHolderNotPermitted)
+	case utils.ErrLastAccountOwner:
+		return responses.ErrorResponse(context, http.StatusConflict, utils.MsgLastAccountOwner)
+	}
+	return responses.ErrorResponse(context, http.StatusInternalServerError, message)
+}
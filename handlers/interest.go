@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/server"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InterestHandler struct holds the interest service
+type InterestHandler struct {
+	InterestService *services.InterestService
+}
+
+// CreateInterestHandler initializes a new InterestHandler with the server's
+// storage and the system clock
+func CreateInterestHandler(server *server.Server) *InterestHandler {
+	return &InterestHandler{
+		InterestService: services.CreateInterestService(server.Storage, utils.SystemClock{}),
+	}
+}
+
+// Accrue godoc
+// @Summary Accrue interest for a date range
+// @Description Accrues the daily interest of every savings account and term deposit for the days from the first date up to but excluding the second, never later than yesterday. Days already accrued are skipped and every month whose last day is accrued is paid as an interest transaction dated the first of the next month
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param range body requests.InterestAccrualRequest true "Dates to accrue"
+// @Success 200 {object} responses.InterestRun
+// @Failure 400 {object} responses.Error
+// @Failure 401 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Failure 503 {object} responses.Error
+// @Router /admin/interest/accrue [post]
+func (handler *InterestHandler) Accrue(context *fiber.Ctx) error {
+	// Parse request body into InterestAccrualRequest struct
+	request := requests.InterestAccrualRequest{}
+	if err := context.BodyParser(&request); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+	}
+
+	// Validate the request data
+	if err := request.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidDateRange)
+	}
+
+	// Attempt to accrue the range using service layer
+	run, err := handler.InterestService.Accrue(request)
+	if err != nil {
+		if err == utils.ErrInvalidDateRange {
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidDateRange)
+		}
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedAccrual)
+	}
+
+	// Return successful response with what the run did
+	return responses.InterestRunResponse(context, http.StatusOK, run)
+}
@@ -0,0 +1,44 @@
+// Package handlers contains HTTP request handlers for the bank account manager
+package handlers
+
+import (
+	"bank-account-manager/responses"
+	"bank-account-manager/server"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LedgerHandler struct holds the ledger service
+type LedgerHandler struct {
+	LedgerService *services.LedgerService
+}
+
+// CreateLedgerHandler initializes a new LedgerHandler with the server's storage
+func CreateLedgerHandler(server *server.Server) *LedgerHandler {
+	return &LedgerHandler{
+		LedgerService: services.CreateLedgerService(server.Storage),
+	}
+}
+
+// TrialBalance godoc
+// @Summary Get the trial balance
+// @Description Sums the debit and credit postings of every ledger account, including the external and exchange accounts. The books are balanced when debits equal credits in every currency and every account balance matches its postings
+// @Tags Ledger
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.TrialBalance
+// @Failure 500 {object} responses.Error
+// @Router /ledger/trial-balance [get]
+func (handler *LedgerHandler) TrialBalance(context *fiber.Ctx) error {
+	// Attempt to compute the trial balance using service layer
+	trialBalance, err := handler.LedgerService.TrialBalance()
+	if err != nil {
+		return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedTrialBalance)
+	}
+
+	// Return successful response with the trial balance
+	return responses.TrialBalanceResponse(context, http.StatusOK, trialBalance)
+}
@@ -19,9 +19,18 @@ type TransactionHandler struct {
 }
 
 // CreateTransactionHandler initializes a new TransactionHandler with the provided server's storage
+// exchange rate table, fee schedule and hold duration
 func CreateTransactionHandler(server *server.Server) *TransactionHandler {
+	transactionService := services.CreateTransactionService(server.Storage)
+	transactionService.Exchange = server.Exchange
+	transactionService.Rules = server.Config.AccountRules
+	transactionService.Fees = server.Fees
+	if server.Config.HoldTTL > 0 {
+		transactionService.HoldTTL = server.Config.HoldTTL
+	}
+
 	return &TransactionHandler{
-		TransactionService: services.CreateTransactionService(server.Storage),
+		TransactionService: transactionService,
 	}
 }
 
@@ -32,9 +41,14 @@ func CreateTransactionHandler(server *server.Server) *TransactionHandler {
 // @Accept json
 // @Produce json
 // @Param id path string true "Account ID"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Customer withdrawing from an account with holders"
 // @Param transaction body requests.TransactionRequest true "Transaction details"
 // @Success 201 {object} responses.Transaction
 // @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
 // @Failure 500 {object} responses.Error
 // @Router /accounts/{id}/transactions [post]
 func (handler *TransactionHandler) Create(context *fiber.Ctx) error {
@@ -49,6 +63,7 @@ func (handler *TransactionHandler) Create(context *fiber.Ctx) error {
 	if err := context.BodyParser(&request); err != nil {
 		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
 	}
+	request.ActingCustomerID = context.Get(requests.ActingCustomerHeader)
 
 	// Validate the transaction request data
 	if err := request.Validate(); err != nil {
@@ -64,10 +79,30 @@ func (handler *TransactionHandler) Create(context *fiber.Ctx) error {
 			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidTxType)
 		case utils.ErrInsufficientFunds:
 			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInsufficientFunds)
+		case utils.ErrAccountFrozen:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountFrozen)
+		case utils.ErrAccountClosed:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountClosed)
+		case utils.ErrAccountLocked:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountLocked)
+		case utils.ErrWithdrawalLimit:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgWithdrawalLimit)
+		case utils.ErrInvalidAmount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+		case utils.ErrAmountPrecision:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+		case utils.ErrInvalidCurrency:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCurrency)
+		case utils.ErrCurrencyMismatch:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgCurrencyMismatch)
 		case utils.ErrAccountNotFound:
 			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
 		case utils.ErrInvalidUUID:
 			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+		case utils.ErrSameAccountTransfer:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgSameAccountTransfer)
+		case utils.ErrHolderNotPermitted:
+			return responses.ErrorResponse(context, http.StatusForbidden, utils.MsgHolderNotPermitted)
 		default:
 			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedCreateTx)
 		}
@@ -79,13 +114,16 @@ func (handler *TransactionHandler) Create(context *fiber.Ctx) error {
 
 // ReadByAccount godoc
 // @Summary Get account transactions
-// @Description Retrieves all transactions for the specified bank account
+// @Description Retrieves one page of the transactions of the specified bank account, sorted by timestamp.
+// @Description Pass the returned next_cursor as cursor to fetch the following page
 // @Tags Transactions
 // @Accept json
 // @Produce json
 // @Param id path string true "Account ID"
-// @Success 200 {object} []responses.Transaction
+// @Param query query requests.TransactionQuery false "Filters, sort order and page"
+// @Success 200 {object} responses.TransactionPage
 // @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
 // @Failure 500 {object} responses.Error
 // @Router /accounts/{id}/transactions [get]
 func (handler *TransactionHandler) ReadByAccount(context *fiber.Ctx) error {
@@ -95,11 +133,30 @@ func (handler *TransactionHandler) ReadByAccount(context *fiber.Ctx) error {
 		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
 	}
 
+	// Parse and validate the filters of the listing
+	query := requests.TransactionQuery{}
+	if err := context.QueryParser(&query); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidQuery)
+	}
+	if err := query.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
 	// Attempt to retrieve transactions using service layer
-	transactions, err := handler.TransactionService.ReadByAccount(accountID)
+	page, err := handler.TransactionService.Search(accountID, query)
 	if err != nil {
 		// Handle various error cases with appropriate status codes
 		switch err {
+		case utils.ErrInvalidTxType:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidTxType)
+		case utils.ErrInvalidAmount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+		case utils.ErrAmountPrecision:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+		case utils.ErrInvalidQuery:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidQuery)
+		case utils.ErrInvalidCursor:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCursor)
 		case utils.ErrAccountNotFound:
 			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
 		case utils.ErrInvalidUUID:
@@ -109,19 +166,25 @@ func (handler *TransactionHandler) ReadByAccount(context *fiber.Ctx) error {
 		}
 	}
 
-	// Return successful response with all transactions for the account
-	return responses.TransactionResponses(context, http.StatusOK, transactions)
+	// Return successful response with the page of transactions
+	return responses.TransactionPageResponse(context, http.StatusOK, page)
 }
 
 // Transfer godoc
 // @Summary Transfer funds between accounts
-// @Description Transfer funds from one account to another
+// @Description Transfer funds from one account to another. The amount is in the source account currency
+// @Description and is converted with the exchange rate table when the destination uses another currency
 // @Tags Transactions
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Customer sending from a source account with holders"
 // @Param transaction body requests.TransferRequest true "Transfer details"
-// @Success 201 {object} responses.Message
+// @Success 201 {object} responses.Transfer
 // @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
 // @Failure 500 {object} responses.Error
 // @Router /transfer [post]
 func (handler *TransactionHandler) Transfer(context *fiber.Ctx) error {
@@ -130,19 +193,15 @@ func (handler *TransactionHandler) Transfer(context *fiber.Ctx) error {
 	if err := context.BodyParser(&request); err != nil {
 		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
 	}
+	request.ActingCustomerID = context.Get(requests.ActingCustomerHeader)
 
 	// Validate the transfer request data
 	if err := request.Validate(); err != nil {
 		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
 	}
 
-	// Check if source and destination accounts are different
-	if request.FromAccountID == request.ToAccountID {
-		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgSameAccountTransfer)
-	}
-
 	// Attempt to process transfer using service layer
-	err := handler.TransactionService.Transfer(request)
+	transfer, err := handler.TransactionService.Transfer(request)
 	if err != nil {
 		// Handle various transfer-specific errors with appropriate status codes
 		switch err {
@@ -150,15 +209,103 @@ func (handler *TransactionHandler) Transfer(context *fiber.Ctx) error {
 			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidTxType)
 		case utils.ErrInsufficientFunds:
 			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInsufficientFunds)
+		case utils.ErrAccountFrozen:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountFrozen)
+		case utils.ErrAccountClosed:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountClosed)
+		case utils.ErrAccountLocked:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountLocked)
+		case utils.ErrWithdrawalLimit:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgWithdrawalLimit)
+		case utils.ErrInvalidAmount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+		case utils.ErrAmountPrecision:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+		case utils.ErrInvalidCurrency:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCurrency)
+		case utils.ErrRateNotFound, utils.ErrInvalidRate:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgRateNotFound)
 		case utils.ErrAccountNotFound:
 			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgAccountNotFound)
 		case utils.ErrInvalidUUID:
 			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+		case utils.ErrHolderNotPermitted:
+			return responses.ErrorResponse(context, http.StatusForbidden, utils.MsgHolderNotPermitted)
 		default:
 			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedCreateTx)
 		}
 	}
 
-	// Return successful response with transfer confirmation message
-	return responses.MessageResponse(context, http.StatusCreated, utils.MsgTransferSuccess)
+	// Return successful response with the created transfer, its legs and fees
+	return responses.TransferResponse(context, http.StatusCreated, transfer)
+}
+
+// Reverse godoc
+// @Summary Reverse a transaction
+// @Description Undoes a deposit, a withdrawal or both legs of a transfer with compensating transactions linked to the originals.
+// @Description Withdrawals are undone by refunds and deposits by reversals. The amount is optional and reverses part of the
+// @Description transaction, in the source account currency for transfers. No transaction is reversed for more than its amount
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Param X-Customer-ID header string false "Customer reversing a deposit into an account with holders"
+// @Param reversal body requests.ReversalRequest false "Amount to reverse"
+// @Success 201 {object} responses.Reversal
+// @Failure 400 {object} responses.Error
+// @Failure 403 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 409 {object} responses.Error
+// @Failure 422 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /transactions/{id}/reverse [post]
+func (handler *TransactionHandler) Reverse(context *fiber.Ctx) error {
+	// Extract transaction ID from request parameters
+	transactionID := context.Params("id")
+	if transactionID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Initialize and parse the optional reversal request from request body
+	request := requests.ReversalRequest{}
+	if len(context.Body()) > 0 {
+		if err := context.BodyParser(&request); err != nil {
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidRequestBody)
+		}
+	}
+	request.ActingCustomerID = context.Get(requests.ActingCustomerHeader)
+
+	// Attempt to reverse the transaction using service layer
+	reversals, err := handler.TransactionService.Reverse(transactionID, request)
+	if err != nil {
+		// Handle various reversal-specific errors with appropriate status codes
+		switch err {
+		case utils.ErrTransactionNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgTxNotFound)
+		case utils.ErrNotReversible:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgNotReversible)
+		case utils.ErrReversalExceedsAmount:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgReversalExceeds)
+		case utils.ErrInsufficientFunds:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInsufficientFunds)
+		case utils.ErrAccountFrozen:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountFrozen)
+		case utils.ErrAccountClosed:
+			return responses.ErrorResponse(context, http.StatusConflict, utils.MsgAccountClosed)
+		case utils.ErrInvalidAmount:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidAmount)
+		case utils.ErrAmountPrecision:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgAmountPrecision)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidTxUUID)
+		case utils.ErrHolderNotPermitted:
+			return responses.ErrorResponse(context, http.StatusForbidden, utils.MsgHolderNotPermitted)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedReverseTx)
+		}
+	}
+
+	// Return successful response with the compensating transactions
+	return responses.ReversalResponse(context, http.StatusCreated, utils.MsgReversalSuccess, reversals)
 }
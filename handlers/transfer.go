@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReadTransfer godoc
+// @Summary Get a transfer
+// @Description Retrieves a transfer with both of its legs and the fees charged for them
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} responses.Transfer
+// @Failure 400 {object} responses.Error
+// @Failure 404 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /transfers/{id} [get]
+func (handler *TransactionHandler) ReadTransfer(context *fiber.Ctx) error {
+	// Extract transfer ID from request parameters
+	transferID := context.Params("id")
+	if transferID == "" {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgIDCannotBeEmpty)
+	}
+
+	// Attempt to retrieve the transfer using service layer
+	transfer, err := handler.TransactionService.ReadTransfer(transferID)
+	if err != nil {
+		// Handle various error cases with appropriate status codes
+		switch err {
+		case utils.ErrTransferNotFound:
+			return responses.ErrorResponse(context, http.StatusNotFound, utils.MsgTransferNotFound)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidTransferUUID)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedRetrieveTransfers)
+		}
+	}
+
+	// Return successful response with the transfer
+	return responses.TransferResponse(context, http.StatusOK, transfer)
+}
+
+// ReadTransfers godoc
+// @Summary List transfers
+// @Description Retrieves one page of the transfers, optionally only those from or to an account, sorted by timestamp.
+// @Description Pass the returned next_cursor as cursor to fetch the following page
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param query query requests.TransferQuery false "Account, sort order and page"
+// @Success 200 {object} responses.TransferPage
+// @Failure 400 {object} responses.Error
+// @Failure 500 {object} responses.Error
+// @Router /transfers [get]
+func (handler *TransactionHandler) ReadTransfers(context *fiber.Ctx) error {
+	// Parse and validate the filter of the listing
+	query := requests.TransferQuery{}
+	if err := context.QueryParser(&query); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidQuery)
+	}
+	if err := query.Validate(); err != nil {
+		return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgValidationFailed)
+	}
+
+	// Attempt to retrieve transfers using service layer
+	page, err := handler.TransactionService.SearchTransfers(query)
+	if err != nil {
+		// Handle various error cases with appropriate status codes
+		switch err {
+		case utils.ErrInvalidCursor:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidCursor)
+		case utils.ErrInvalidUUID:
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidUUID)
+		default:
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedRetrieveTransfers)
+		}
+	}
+
+	// Return successful response with the page of transfers
+	return responses.TransferPageResponse(context, http.StatusOK, page)
+}
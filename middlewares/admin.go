@@ -0,0 +1,31 @@
+// Package middlewares contains HTTP middleware for the bank account manager
+package middlewares
+
+import (
+	"bank-account-manager/responses"
+	"bank-account-manager/utils"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminTokenHeader carries the token expected by admin endpoints
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminOnly rejects requests whose X-Admin-Token header does not match the
+// configured token. Every request is rejected when no token is configured
+func AdminOnly(token string) fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		if token == "" {
+			return responses.ErrorResponse(context, http.StatusServiceUnavailable, utils.MsgAdminDisabled)
+		}
+
+		// Compare in constant time to avoid leaking the token
+		provided := context.Get(AdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return responses.ErrorResponse(context, http.StatusUnauthorized, utils.MsgInvalidAdminToken)
+		}
+		return context.Next()
+	}
+}
@@ -0,0 +1,95 @@
+package middlewares
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/responses"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Idempotency headers of requests and replayed responses
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength bounds the length of an idempotency key
+const maxIdempotencyKeyLength = 255
+
+// Idempotency makes mutating requests carrying an Idempotency-Key header safe
+// to retry. The response to the first request is stored and replayed for
+// retries with the same method, path and body, while reusing the key for a
+// different request is rejected with 422. Server errors and rejected admin
+// tokens are not stored so that the request can be retried
+func Idempotency(service *services.IdempotencyService) fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		key := context.Get(IdempotencyKeyHeader)
+		if key == "" || isSafeMethod(context.Method()) {
+			return context.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return responses.ErrorResponse(context, http.StatusBadRequest, utils.MsgInvalidIdempotencyKey)
+		}
+
+		// Claim the key or find the response of an earlier request
+		record, replay, err := service.Begin(key, fingerprint(context))
+		if err != nil {
+			switch err {
+			case utils.ErrIdempotencyKeyMismatch:
+				return responses.ErrorResponse(context, http.StatusUnprocessableEntity, utils.MsgIdempotencyKeyMismatch)
+			case utils.ErrIdempotencyKeyInProgress:
+				return responses.ErrorResponse(context, http.StatusConflict, utils.MsgIdempotencyKeyInProgress)
+			}
+			return responses.ErrorResponse(context, http.StatusInternalServerError, utils.MsgFailedIdempotency)
+		}
+
+		// Replay the stored response of a completed request
+		if replay {
+			context.Set(IdempotencyReplayedHeader, "true")
+			context.Set(fiber.HeaderContentType, record.ContentType)
+			return context.Status(record.StatusCode).Send(record.Body)
+		}
+
+		// Handle the request, releasing the key when it fails on our side
+		if err := context.Next(); err != nil {
+			service.Release(key)
+			return err
+		}
+		response := context.Response()
+		if response.StatusCode() >= http.StatusInternalServerError || response.StatusCode() == http.StatusUnauthorized {
+			service.Release(key)
+			return nil
+		}
+
+		// Store the response for later retries
+		record.StatusCode = response.StatusCode()
+		record.ContentType = string(response.Header.ContentType())
+		record.Body = append([]byte{}, response.Body()...)
+		if err := service.Complete(record); err != nil {
+			service.Release(key)
+		}
+		return nil
+	}
+}
+
+// isSafeMethod reports whether requests with the method never change state
+func isSafeMethod(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
+
+// fingerprint identifies a request by its method, path, acting customer and
+// body
+func fingerprint(context *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(context.Method() + " " + context.Path() + "\n"))
+	if actor := context.Get(requests.ActingCustomerHeader); actor != "" {
+		hash.Write([]byte(requests.ActingCustomerHeader + ": " + actor + "\n"))
+	}
+	hash.Write(context.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -1,9 +1,49 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Account struct {
-	ID      uuid.UUID
-	Owner   string
-	Balance float64
+	ID           uuid.UUID
+	CustomerID   uuid.UUID // Customer holding the account, uuid.Nil for none
+	Owner        string
+	Currency     string
+	Balance      money.Money
+	Overdraft    money.Money    // How far the balance may go below zero, zero for none
+	Held         money.Money    // Sum of the pending holds, which cannot be sent
+	InterestRate money.Rate     // Annual interest rate, zero for none
+	DayCount     utils.DayCount // Convention turning the annual rate into daily interest
+	Type         utils.AccountType
+	MaturesAt    time.Time // Term deposits allow no withdrawals before, zero for other types
+	Status       utils.AccountStatus
+	CreatedAt    time.Time
+}
+
+// Available returns the funds the account can still send: its balance plus
+// the unused overdraft, less the funds held for pending payments. It fails
+// with ErrInvalidAmount should that overflow
+func (account Account) Available() (money.Money, error) {
+	return available(account.Balance, account.Overdraft, account.Held)
+}
+
+// available adds the overdraft to a balance and subtracts the held funds
+func available(balance money.Money, overdraft money.Money, held money.Money) (money.Money, error) {
+	total, err := balance.Add(overdraft)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return total.Sub(held)
+}
+
+// AccountHolder grants a customer a role on an account. Accounts may have
+// several holders
+type AccountHolder struct {
+	AccountID  uuid.UUID
+	CustomerID uuid.UUID
+	Role       utils.HolderRole
 }
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Customer struct {
+	ID        uuid.UUID
+	Name      string
+	Email     string
+	CreatedAt time.Time
+}
+
+// CurrencyBalance is an amount in one currency
+type CurrencyBalance struct {
+	Currency string
+	Amount   money.Money
+}
+
+// NetWorth sums the balances of the accounts of a customer
+type NetWorth struct {
+	CustomerID uuid.UUID
+	Balances   []CurrencyBalance // Sum of the account balances per currency
+	Currency   string            // Currency the balances were converted into, if any
+	Total      *money.Money      // Sum of every balance converted into Currency, if requested
+}
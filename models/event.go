@@ -0,0 +1,51 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is an immutable record of a change to the state of an account.
+// Events are never updated or deleted and are numbered in the order they
+// were appended to the event store
+type Event struct {
+	Sequence       int64 // Position in the event store, assigned when appended
+	Type           utils.EventType
+	AccountID      uuid.UUID           // Account whose state changed
+	CounterpartyID uuid.UUID           // Destination account of a transfer, uuid.Nil otherwise
+	ReferenceID    uuid.UUID           // Transaction, transfer or hold behind the change, uuid.Nil for none
+	Currency       string              // Currency of the account
+	Amount         money.Money         // Money opened with, moved, held, released or the new overdraft
+	Status         utils.AccountStatus // Status of the account after the change
+	TimeStamp      time.Time
+}
+
+// EventFilter selects the events of an account
+type EventFilter struct {
+	AccountID uuid.UUID // Account the events are of or transfer to
+	After     int64     // Only events numbered after this sequence
+	Until     time.Time // Only events that happened no later than this, zero for no limit
+}
+
+// AccountBalance is the read model of an account built by applying its
+// events in order. Stored as a checkpoint, it saves replaying the events up
+// to its version
+type AccountBalance struct {
+	AccountID uuid.UUID
+	Currency  string
+	Balance   money.Money
+	Held      money.Money
+	Overdraft money.Money
+	Status    utils.AccountStatus
+	Version   int64     // Sequence of the last event applied
+	UpdatedAt time.Time // Time of the latest event applied
+}
+
+// Available returns the funds the account can still send according to its
+// events, the same way Account.Available does from its stored state
+func (balance AccountBalance) Available() (money.Money, error) {
+	return available(balance.Balance, balance.Overdraft, balance.Held)
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"time"
+)
+
+// ExchangeRate converts amounts in the From currency into the To currency
+type ExchangeRate struct {
+	From      string
+	To        string
+	Rate      money.Rate
+	UpdatedAt time.Time
+}
+
+// Exchange records the currency conversion applied to a cross-currency
+// transfer. Both legs of the transfer carry the same record
+type Exchange struct {
+	Rate                money.Rate
+	SourceCurrency      string
+	SourceAmount        money.Money
+	DestinationCurrency string
+	DestinationAmount   money.Money
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+)
+
+// Operations a fee rule can price
+const (
+	FeeOnDeposit    = "deposit"
+	FeeOnWithdrawal = "withdrawal"
+	FeeOnTransfer   = "transfer" // Charged to the source account
+)
+
+// FeeRule prices one operation on accounts of one type. Amounts are in the
+// currency of the account charged and the fee is rounded to its minor unit
+type FeeRule struct {
+	Operation   string
+	AccountType *utils.AccountType // Type of the account charged, nil for every type
+	Tiers       []FeeTier          // Prices by amount, only the last tier has no upper bound
+	Minimum     money.Money        // Smallest fee charged, zero for none
+	Maximum     *money.Money       // Largest fee charged, nil for no cap
+}
+
+// FeeTier prices the amounts up to a bound as a flat fee plus a part of
+// the amount
+type FeeTier struct {
+	UpTo *money.Money // Largest amount of the tier, nil for no bound
+	Flat money.Money
+	Rate money.Rate // Part of the amount, e.g. 0.01 for 1%, zero for none
+}
+
+// Fee returns the fee of an amount before rounding: the flat fee and part of
+// the amount of the first tier holding it, kept between the minimum and the
+// maximum
+func (rule FeeRule) Fee(amount money.Money) (money.Money, error) {
+	tier := rule.Tiers[len(rule.Tiers)-1]
+	for _, candidate := range rule.Tiers {
+		if candidate.UpTo == nil || amount.Cmp(*candidate.UpTo) <= 0 {
+			tier = candidate
+			break
+		}
+	}
+
+	fee := tier.Flat
+	if tier.Rate.Units != 0 {
+		part, err := tier.Rate.Convert(amount, amount.Scale+tier.Rate.Scale)
+		if err != nil {
+			return money.Money{}, err
+		}
+		if fee, err = fee.Add(part); err != nil {
+			return money.Money{}, err
+		}
+	}
+	if fee.Cmp(rule.Minimum) < 0 {
+		fee = rule.Minimum
+	}
+	if rule.Maximum != nil && fee.Cmp(*rule.Maximum) > 0 {
+		fee = *rule.Maximum
+	}
+	return fee, nil
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hold reserves part of the available balance of an account for a payment
+// that is authorized but not settled yet. Capturing it settles the payment
+// as a withdrawal and releasing or expiring it frees the funds
+type Hold struct {
+	ID            uuid.UUID
+	AccountID     uuid.UUID
+	Amount        money.Money // Amount reserved while pending
+	Captured      money.Money // Amount settled by the capture, zero otherwise
+	TransactionID uuid.UUID   // Withdrawal settling the capture, uuid.Nil otherwise
+	Status        utils.HoldStatus
+	CreatedAt     time.Time
+	ExpiresAt     time.Time // Pending holds expire at this time
+}
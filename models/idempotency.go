@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// IdempotencyRecord remembers the request made with an idempotency key and,
+// once the request completed, the response to replay for its retries
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string // Hash of the method, path and body of the first request
+	Completed   bool   // False while the first request is still being handled
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
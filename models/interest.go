@@ -0,0 +1,31 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InterestAccrual records the interest an account earned over one day. The
+// amounts of a month are summed and paid by a single interest transaction
+type InterestAccrual struct {
+	AccountID     uuid.UUID
+	Date          time.Time   // Day of the accrual, at midnight UTC
+	Balance       money.Money // Balance at the end of the day
+	Amount        money.Money // Interest earned, kept more precise than the currency
+	TransactionID uuid.UUID   // Interest transaction paying the accrual, uuid.Nil until paid
+}
+
+// InterestRun summarizes one run of the interest accrual
+type InterestRun struct {
+	Accrued int               // Days accrued across every account
+	Paid    []Transaction     // Interest transactions posted for the months that ended
+	Failed  []InterestFailure // Accounts left unchanged by the run because of an error
+}
+
+// InterestFailure tells why the accrual of one account failed
+type InterestFailure struct {
+	AccountID uuid.UUID
+	Err       error
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ledger accounts of the bank itself, posted against alongside customer accounts
+var (
+	// ExternalAccountID is the cash/equity account on the other side of
+	// deposits, withdrawals and opening balances
+	ExternalAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	// ExchangeAccountID holds the currency positions taken by cross-currency transfers
+	ExchangeAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	// FeesAccountID collects the fees charged to customer accounts
+	FeesAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	// InterestAccountID pays the interest earned by customer accounts
+	InterestAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000004")
+)
+
+// SystemAccounts names the ledger accounts that are not customer accounts
+var SystemAccounts = map[uuid.UUID]string{
+	ExternalAccountID: "external",
+	ExchangeAccountID: "exchange",
+	FeesAccountID:     "fees",
+	InterestAccountID: "interest",
+}
+
+// Posting debits or credits one ledger account in one currency
+type Posting struct {
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID // Customer transaction the posting belongs to, if any
+	Side          utils.PostingSide
+	Currency      string
+	Amount        money.Money // Always positive, the side gives the direction
+}
+
+// JournalEntry records a single movement of money as postings whose debits
+// and credits are equal in every currency
+type JournalEntry struct {
+	ID          uuid.UUID
+	Description string
+	Postings    []Posting
+	TimeStamp   time.Time
+}
+
+// LedgerBalance holds the posting totals of a ledger account in one currency
+type LedgerBalance struct {
+	AccountID uuid.UUID
+	Currency  string
+	Debits    money.Money
+	Credits   money.Money
+}
+
+// TrialBalanceLine reports the posting totals of one ledger account
+type TrialBalanceLine struct {
+	LedgerBalance
+	Name    string      // Owner of a customer account or name of a system account
+	Balance money.Money // Credits minus debits
+}
+
+// TrialBalanceTotal sums the postings of every ledger account in one currency
+type TrialBalanceTotal struct {
+	Currency string
+	Debits   money.Money
+	Credits  money.Money
+}
+
+// TrialBalance lists the posting totals of the whole ledger
+type TrialBalance struct {
+	Lines    []TrialBalanceLine
+	Totals   []TrialBalanceTotal
+	Balanced bool // Debits equal credits and account balances match their postings
+}
+
+// Signed returns the effect of the posting on a balance: credits increase
+// it and debits decrease it
+func (posting Posting) Signed() money.Money {
+	if posting.Side == utils.Debit {
+		return posting.Amount.Neg()
+	}
+	return posting.Amount
+}
+
+// IsBalanced reports whether debits and credits are equal in every currency.
+// Sums too large to compute are not balanced
+func (entry JournalEntry) IsBalanced() bool {
+	sums := map[string]money.Money{}
+	for _, posting := range entry.Postings {
+		sum, err := sums[posting.Currency].Add(posting.Signed())
+		if err != nil {
+			return false
+		}
+		sums[posting.Currency] = sum
+	}
+	for _, sum := range sums {
+		if !sum.IsZero() {
+			return false
+		}
+	}
+	return len(entry.Postings) > 0
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor marks the position of the last item of a page: the value the items
+// are sorted by and the ID breaking ties between equal values
+type Cursor struct {
+	Value int64
+	ID    uuid.UUID
+}
+
+// TransactionFilter selects a page of the transactions of an account. Items
+// are ordered by timestamp, then by ID
+type TransactionFilter struct {
+	AccountID  uuid.UUID
+	Type       *utils.TransactionType // Only transactions of this type, if set
+	MinAmount  *money.Money           // Inclusive lower bound of the amount, if set
+	MaxAmount  *money.Money           // Inclusive upper bound of the amount, if set
+	From       time.Time              // Inclusive lower bound of the timestamp, unless zero
+	To         time.Time              // Exclusive upper bound of the timestamp, unless zero
+	Descending bool                   // Newest transactions first
+	After      *Cursor                // Only transactions after this position, if set
+	Limit      int                    // Maximum number of transactions returned
+}
+
+// TransactionPage is one page of a transaction listing
+type TransactionPage struct {
+	Transactions []Transaction
+	NextCursor   string // Opaque cursor of the next page, empty on the last page
+}
+
+// TransferFilter selects a page of transfers. Items are ordered by timestamp,
+// then by ID
+type TransferFilter struct {
+	AccountID  uuid.UUID // Only transfers from or to this account, unless uuid.Nil
+	Descending bool      // Newest transfers first
+	After      *Cursor   // Only transfers after this position, if set
+	Limit      int       // Maximum number of transfers returned
+}
+
+// TransferPage is one page of a transfer listing
+type TransferPage struct {
+	Transfers  []Transfer
+	NextCursor string // Opaque cursor of the next page, empty on the last page
+}
+
+// Orders of an account listing
+const (
+	AccountSortCreated = "created"
+	AccountSortBalance = "balance"
+)
+
+// AccountFilter selects a page of accounts. Items are ordered by the sort
+// value of the filter, then by ID
+type AccountFilter struct {
+	Owner      string                // Case-insensitive substring of the owner, if set
+	Currency   string                // Only accounts in this currency, if set
+	MinBalance *money.Money          // Inclusive lower bound of the balance in Currency, if set
+	MaxBalance *money.Money          // Inclusive upper bound of the balance in Currency, if set
+	Statuses   []utils.AccountStatus // Only accounts in one of these statuses, if set
+	SortBy     string                // AccountSortCreated, or AccountSortBalance within Currency
+	Descending bool                  // Largest sort values first
+	After      *Cursor               // Only accounts after this position, if set
+	Limit      int                   // Maximum number of accounts returned
+}
+
+// AccountPage is one page of an account listing
+type AccountPage struct {
+	Accounts   []Account
+	NextCursor string // Opaque cursor of the next page, empty on the last page
+}
+
+// SortValue returns the value an account is ordered by in the listing
+func (filter AccountFilter) SortValue(account Account) int64 {
+	if filter.SortBy == AccountSortBalance {
+		// Balances are only sorted within one currency, so their minor
+		// units compare
+		return account.Balance.Units
+	}
+	return account.CreatedAt.UnixNano()
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bank-account-manager/money"
 	"bank-account-manager/utils"
 	"time"
 
@@ -8,9 +9,22 @@ import (
 )
 
 type Transaction struct {
-	ID        uuid.UUID
-	AccountID uuid.UUID
-	Type      utils.TransactionType
-	Amount    float64
-	TimeStamp time.Time
+	ID         uuid.UUID
+	AccountID  uuid.UUID
+	Type       utils.TransactionType
+	Currency   string
+	Amount     money.Money
+	Exchange   *Exchange // Currency conversion of a cross-currency transfer leg
+	RelatedID  uuid.UUID // Transaction a fee or reversal is for, uuid.Nil otherwise
+	TransferID uuid.UUID // Transfer the leg or its fee belongs to, uuid.Nil otherwise
+	TimeStamp  time.Time
+}
+
+// Signed returns the effect of the transaction on the account balance:
+// debits such as withdrawals and fees decrease it
+func (transaction Transaction) Signed() money.Money {
+	if transaction.Type.IsDebit() {
+		return transaction.Amount.Neg()
+	}
+	return transaction.Amount
 }
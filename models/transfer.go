@@ -0,0 +1,27 @@
+package models
+
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transfer moves money from one account to another as a withdrawal and a
+// deposit leg, both referencing the transfer by their TransferID
+type Transfer struct {
+	ID            uuid.UUID
+	FromAccountID uuid.UUID
+	ToAccountID   uuid.UUID
+	Currency      string      // Currency of the source account
+	Amount        money.Money // Amount taken from the source account
+	Status        utils.TransferStatus
+	TimeStamp     time.Time
+
+	// Legs of the transfer and the fees charged for them, filled in when the
+	// transfer is read and not stored with it
+	Withdrawal Transaction
+	Deposit    Transaction
+	Fees       []Transaction
+}
@@ -0,0 +1,58 @@
+package money
+
+import (
+	"bank-account-manager/utils"
+	"strings"
+)
+
+// DefaultCurrency is assigned to accounts created without a currency
+const DefaultCurrency = "USD"
+
+// MaxCurrencyScale is the largest number of minor unit digits of a
+// supported currency
+const MaxCurrencyScale = 3
+
+// Currency describes an ISO 4217 currency and its number of minor unit digits
+type Currency struct {
+	Code  string // Three-letter ISO 4217 code
+	Scale int    // Number of decimal places of the minor unit
+}
+
+// currencies lists the supported ISO 4217 currencies keyed by code
+var currencies = map[string]Currency{
+	"AED": {"AED", 2}, "ARS": {"ARS", 2}, "AUD": {"AUD", 2}, "BGN": {"BGN", 2},
+	"BHD": {"BHD", 3}, "BRL": {"BRL", 2}, "CAD": {"CAD", 2}, "CHF": {"CHF", 2},
+	"CLP": {"CLP", 0}, "CNY": {"CNY", 2}, "COP": {"COP", 2}, "CZK": {"CZK", 2},
+	"DKK": {"DKK", 2}, "EGP": {"EGP", 2}, "EUR": {"EUR", 2}, "GBP": {"GBP", 2},
+	"HKD": {"HKD", 2}, "HUF": {"HUF", 2}, "IDR": {"IDR", 2}, "ILS": {"ILS", 2},
+	"INR": {"INR", 2}, "IQD": {"IQD", 3}, "ISK": {"ISK", 0}, "JOD": {"JOD", 3},
+	"JPY": {"JPY", 0}, "KES": {"KES", 2}, "KRW": {"KRW", 0}, "KWD": {"KWD", 3},
+	"LYD": {"LYD", 3}, "MAD": {"MAD", 2}, "MXN": {"MXN", 2}, "MYR": {"MYR", 2},
+	"NGN": {"NGN", 2}, "NOK": {"NOK", 2}, "NZD": {"NZD", 2}, "OMR": {"OMR", 3},
+	"PEN": {"PEN", 2}, "PHP": {"PHP", 2}, "PKR": {"PKR", 2}, "PLN": {"PLN", 2},
+	"QAR": {"QAR", 2}, "RON": {"RON", 2}, "SAR": {"SAR", 2}, "SEK": {"SEK", 2},
+	"SGD": {"SGD", 2}, "THB": {"THB", 2}, "TND": {"TND", 3}, "TRY": {"TRY", 2},
+	"TWD": {"TWD", 2}, "UAH": {"UAH", 2}, "UGX": {"UGX", 0}, "USD": {"USD", 2},
+	"VND": {"VND", 0}, "XAF": {"XAF", 0}, "XOF": {"XOF", 0}, "ZAR": {"ZAR", 2},
+}
+
+// LookupCurrency returns the currency with the given ISO 4217 code. Codes
+// are matched case-insensitively
+func LookupCurrency(code string) (Currency, error) {
+	currency, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, utils.ErrInvalidCurrency
+	}
+	return currency, nil
+}
+
+// Parse converts a decimal into an amount of the currency, rejecting more
+// decimal places than its minor unit allows
+func (currency Currency) Parse(value Decimal) (Money, error) {
+	return value.Parse(currency.Scale)
+}
+
+// Zero returns a zero amount of the currency
+func (currency Currency) Zero() Money {
+	return Zero(currency.Scale)
+}
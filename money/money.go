@@ -0,0 +1,264 @@
+// Package money provides exact decimal arithmetic for monetary amounts
+package money
+
+import (
+	"bank-account-manager/utils"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultScale is the number of decimal places used when no currency is known
+const DefaultScale = 2
+
+// maxScale bounds the number of decimal places an amount may carry
+const maxScale = 18
+
+// Money is an exact monetary amount stored as an integer number of minor
+// units (e.g. cents) together with the number of decimal places of its currency
+type Money struct {
+	Units int64 // Amount expressed in minor units
+	Scale int   // Number of decimal places of the currency
+}
+
+// New returns the amount of the given minor units at the given scale
+func New(units int64, scale int) Money {
+	return Money{Units: units, Scale: scale}
+}
+
+// Zero returns a zero amount at the given scale
+func Zero(scale int) Money {
+	return Money{Scale: scale}
+}
+
+// Parse converts a decimal string such as "12.34" into minor units at the
+// given scale. Exponents, thousands separators and more fractional digits
+// than the scale allows are rejected rather than rounded, and so are amounts
+// too large to be expressed in the minor unit of every currency
+func Parse(text string, scale int) (Money, error) {
+	if scale < 0 || scale > maxScale {
+		return Money{}, utils.ErrInvalidAmount
+	}
+
+	// Split off the sign
+	negative := strings.HasPrefix(text, "-")
+	digits := strings.TrimPrefix(text, "-")
+
+	// Split the integer and fractional parts
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, utils.ErrInvalidAmount
+	}
+	if len(fraction) > scale {
+		return Money{}, utils.ErrAmountPrecision
+	}
+
+	// Combine both parts into minor units, padding the fraction to the scale
+	units, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", scale-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, utils.ErrInvalidAmount
+	}
+	if negative {
+		units = -units
+	}
+
+	// Make sure the amount still fits once aligned with any currency
+	value := Money{Units: units, Scale: scale}
+	if _, err := value.Rescale(max(scale, MaxCurrencyScale)); err != nil {
+		return Money{}, err
+	}
+	return value, nil
+}
+
+// isDigits reports whether value consists of ASCII digits only
+func isDigits(value string) bool {
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkedSum adds both unit counts, or returns ErrInvalidAmount when the sum
+// leaves the range of int64. The minimum int64 is excluded as well so that
+// every amount can be negated
+func checkedSum(left int64, right int64) (int64, error) {
+	sum := left + right
+	if (left > 0 && right > 0 && sum < 0) || (left < 0 && right < 0 && sum >= 0) || sum == math.MinInt64 {
+		return 0, utils.ErrInvalidAmount
+	}
+	return sum, nil
+}
+
+// Rescale converts the amount to a scale at least as large as its own, which
+// is exact, or returns ErrInvalidAmount when it no longer fits
+func (value Money) Rescale(scale int) (Money, error) {
+	if scale < value.Scale {
+		return Money{}, utils.ErrInvalidAmount
+	}
+	units := value.Units
+	for exponent := value.Scale; exponent < scale; exponent++ {
+		if units > math.MaxInt64/10 || units < -math.MaxInt64/10 {
+			return Money{}, utils.ErrInvalidAmount
+		}
+		units *= 10
+	}
+	return Money{Units: units, Scale: scale}, nil
+}
+
+// align converts both amounts to the larger of their scales
+func align(left Money, right Money) (Money, Money, error) {
+	scale := max(left.Scale, right.Scale)
+	left, err := left.Rescale(scale)
+	if err != nil {
+		return Money{}, Money{}, err
+	}
+	right, err = right.Rescale(scale)
+	if err != nil {
+		return Money{}, Money{}, err
+	}
+	return left, right, nil
+}
+
+// Add returns the sum of both amounts, or ErrInvalidAmount when it overflows
+func (value Money) Add(other Money) (Money, error) {
+	value, other, err := align(value, other)
+	if err != nil {
+		return Money{}, err
+	}
+	units, err := checkedSum(value.Units, other.Units)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Units: units, Scale: value.Scale}, nil
+}
+
+// Sub returns the difference of both amounts, or ErrInvalidAmount when it
+// overflows
+func (value Money) Sub(other Money) (Money, error) {
+	value, other, err := align(value, other)
+	if err != nil || other.Units == math.MinInt64 {
+		return Money{}, utils.ErrInvalidAmount
+	}
+	units, err := checkedSum(value.Units, -other.Units)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Units: units, Scale: value.Scale}, nil
+}
+
+// Neg returns the amount with its sign inverted
+func (value Money) Neg() Money {
+	return Money{Units: -value.Units, Scale: value.Scale}
+}
+
+// Cmp returns -1, 0 or +1 depending on whether value is less than, equal
+// to or greater than other
+func (value Money) Cmp(other Money) int {
+	// Compare in a common scale, which may exceed int64
+	if value.Scale == other.Scale {
+		return cmp.Compare(value.Units, other.Units)
+	}
+	scale := max(value.Scale, other.Scale)
+	left := new(big.Int).Mul(big.NewInt(value.Units), pow10(scale-value.Scale))
+	right := new(big.Int).Mul(big.NewInt(other.Units), pow10(scale-other.Scale))
+	return left.Cmp(right)
+}
+
+// IsZero reports whether the amount is zero
+func (value Money) IsZero() bool {
+	return value.Units == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (value Money) IsNegative() bool {
+	return value.Units < 0
+}
+
+// IsPositive reports whether the amount is above zero
+func (value Money) IsPositive() bool {
+	return value.Units > 0
+}
+
+// String formats the amount as a decimal with exactly Scale fractional digits
+func (value Money) String() string {
+	units := value.Units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+
+	// Format the absolute value, taking care of the minimum int64
+	var digits string
+	if units == math.MinInt64 {
+		digits = strings.TrimPrefix(strconv.FormatInt(units, 10), "-")
+	} else {
+		if units < 0 {
+			units = -units
+		}
+		digits = strconv.FormatInt(units, 10)
+	}
+	if value.Scale <= 0 {
+		return sign + digits
+	}
+
+	// Insert the decimal point, padding with leading zeros if needed
+	if len(digits) <= value.Scale {
+		digits = strings.Repeat("0", value.Scale-len(digits)+1) + digits
+	}
+	point := len(digits) - value.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalJSON encodes the amount as an exact JSON number
+func (value Money) MarshalJSON() ([]byte, error) {
+	return []byte(value.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string, taking the scale from the
+// number of fractional digits so that encoded amounts round-trip exactly
+func (value *Money) UnmarshalJSON(data []byte) error {
+	text := string(bytes.Trim(data, `"`))
+	_, fraction, _ := strings.Cut(text, ".")
+
+	parsed, err := Parse(text, len(fraction))
+	if err != nil {
+		return err
+	}
+	*value = parsed
+	return nil
+}
+
+// Decimal is the textual form of an amount received in a request. It is kept
+// verbatim until the scale needed to parse it exactly is known
+type Decimal string
+
+// Parse converts the decimal into an amount at the given scale
+func (value Decimal) Parse(scale int) (Money, error) {
+	return Parse(string(value), scale)
+}
+
+// UnmarshalJSON accepts either a JSON number or a JSON string and keeps its
+// exact text, so no precision is lost to floating point decoding
+func (value *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*value = Decimal(text)
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*value = Decimal(number)
+	return nil
+}
@@ -0,0 +1,122 @@
+package money
+
+import (
+	"bank-account-manager/utils"
+	"bytes"
+	"math/big"
+	"strings"
+)
+
+// inverseScale is the number of decimal places kept when inverting a rate
+const inverseScale = 10
+
+// maxRateScale bounds the number of decimal places of an exchange rate
+const maxRateScale = 12
+
+// Rate is an exact, positive exchange rate expressed as a decimal: the
+// number of destination currency units bought by one source currency unit
+type Rate struct {
+	Units int64 // Rate multiplied by 10^Scale
+	Scale int   // Number of decimal places of the rate
+}
+
+// ParseRate converts a decimal string such as "0.9215" into a Rate
+func ParseRate(text string) (Rate, error) {
+	_, fraction, _ := strings.Cut(text, ".")
+	if len(fraction) > maxRateScale {
+		return Rate{}, utils.ErrInvalidRate
+	}
+
+	value, err := Parse(text, len(fraction))
+	if err != nil || !value.IsPositive() {
+		return Rate{}, utils.ErrInvalidRate
+	}
+	return normalize(Rate{Units: value.Units, Scale: value.Scale}), nil
+}
+
+// normalize removes trailing zero decimal places
+func normalize(rate Rate) Rate {
+	for rate.Scale > 0 && rate.Units%10 == 0 {
+		rate = Rate{Units: rate.Units / 10, Scale: rate.Scale - 1}
+	}
+	return rate
+}
+
+// pow10 returns 10^exponent as a big integer
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// roundHalfEven divides numerator by denominator, rounding ties to even
+func roundHalfEven(numerator *big.Int, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+
+	// Compare twice the remainder with the denominator to find the rounding
+	doubled := new(big.Int).Abs(remainder)
+	doubled.Lsh(doubled, 1)
+	comparison := doubled.Cmp(new(big.Int).Abs(denominator))
+	if comparison > 0 || (comparison == 0 && quotient.Bit(0) == 1) {
+		if numerator.Sign()*denominator.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+// Convert multiplies the amount by the rate and rounds the result half to
+// even at the given scale
+func (rate Rate) Convert(amount Money, scale int) (Money, error) {
+	return rate.Prorate(amount, 1, 1, scale)
+}
+
+// Prorate multiplies the amount by the rate and by the fraction
+// numerator/denominator, such as a number of days in a year, and rounds the
+// result half to even at the given scale
+func (rate Rate) Prorate(amount Money, numerator int64, denominator int64, scale int) (Money, error) {
+	if denominator <= 0 {
+		return Money{}, utils.ErrInvalidAmount
+	}
+	product := new(big.Int).Mul(big.NewInt(amount.Units), big.NewInt(rate.Units))
+	product.Mul(product, big.NewInt(numerator))
+	product.Mul(product, pow10(scale))
+	divisor := new(big.Int).Mul(pow10(amount.Scale+rate.Scale), big.NewInt(denominator))
+
+	units := roundHalfEven(product, divisor)
+	if !units.IsInt64() {
+		return Money{}, utils.ErrInvalidAmount
+	}
+	return Money{Units: units.Int64(), Scale: scale}, nil
+}
+
+// Inverse returns one divided by the rate, rounded half to even to ten
+// decimal places so that it stays an exact decimal
+func (rate Rate) Inverse() (Rate, error) {
+	numerator := pow10(rate.Scale + inverseScale)
+	units := roundHalfEven(numerator, big.NewInt(rate.Units))
+	if !units.IsInt64() || units.Sign() <= 0 {
+		return Rate{}, utils.ErrInvalidRate
+	}
+	return normalize(Rate{Units: units.Int64(), Scale: inverseScale}), nil
+}
+
+// String formats the rate as a decimal
+func (rate Rate) String() string {
+	return Money{Units: rate.Units, Scale: rate.Scale}.String()
+}
+
+// MarshalJSON encodes the rate as an exact JSON number
+func (rate Rate) MarshalJSON() ([]byte, error) {
+	return []byte(rate.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string into a rate
+func (rate *Rate) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseRate(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return err
+	}
+	*rate = parsed
+	return nil
+}
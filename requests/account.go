@@ -1,15 +1,96 @@
 package requests
 
-import validation "github.com/go-ozzo/ozzo-validation"
+import (
+	"bank-account-manager/money"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
 
 type AccountRequest struct {
-	Owner          string  `json:"owner" example:"account"`
-	InitialBalance float64 `json:"inital_balance" example:"100"`
+	CustomerID     string        `json:"customer_id"`             // Customer holding the account, optional
+	Owner          string        `json:"owner" example:"account"` // Defaults to the customer name
+	Currency       string        `json:"currency" example:"USD"`
+	InitialBalance money.Decimal `json:"inital_balance" swaggertype:"number" example:"100.00"`
+	Type           string        `json:"type" example:"checking"`                               // checking (default), savings or term
+	MaturityDate   string        `json:"maturity_date" example:"2030-01-31"`                    // Required for term deposits, RFC 3339 or a date
+	OverdraftLimit money.Decimal `json:"overdraft_limit" swaggertype:"number" example:"500.00"` // Checking accounts only, none by default
+	InterestRate   money.Decimal `json:"interest_rate" swaggertype:"number" example:"0.035"`    // Annual rate of savings and term accounts, none by default
+	DayCount       string        `json:"day_count" example:"ACT/365"`                           // ACT/365 (default) or 30/360
 }
 
 func (request AccountRequest) Validate() error {
 	return validation.ValidateStruct(&request,
-		validation.Field(&request.Owner, validation.Required),
+		validation.Field(&request.Owner, ownerRules(request.CustomerID)...),
 		validation.Field(&request.InitialBalance, validation.Required),
+		validation.Field(&request.Type, validation.In("checking", "savings", "term")),
+		validation.Field(&request.MaturityDate, maturityRules(request.Type)...),
+		validation.Field(&request.DayCount, validation.In("ACT/365", "30/360")),
+	)
+}
+
+// maturityRules requires a maturity date for term deposits
+func maturityRules(accountType string) []validation.Rule {
+	if accountType == "term" {
+		return []validation.Rule{validation.Required}
+	}
+	return nil
+}
+
+// AccountUpdateRequest holds the editable details of an account
+type AccountUpdateRequest struct {
+	CustomerID       string `json:"customer_id"`             // Links the account to another customer, optional
+	Owner            string `json:"owner" example:"account"` // Defaults to the customer name
+	ActingCustomerID string `json:"-"`                       // Customer relinking the account, from the ActingCustomerHeader
+}
+
+func (request AccountUpdateRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.Owner, ownerRules(request.CustomerID)...),
+	)
+}
+
+// ownerRules requires an owner unless the account is linked to a customer,
+// whose name is used instead
+func ownerRules(customerID string) []validation.Rule {
+	if customerID == "" {
+		return []validation.Rule{validation.Required}
+	}
+	return nil
+}
+
+// OverdraftRequest holds the overdraft limit of an account
+type OverdraftRequest struct {
+	Limit money.Decimal `json:"limit" swaggertype:"number" example:"500.00"`
+}
+
+func (request OverdraftRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.Limit, validation.Required),
+	)
+}
+
+// AccountQuery holds the search, filters, sort order and page of an account listing
+type AccountQuery struct {
+	Owner      string        `query:"owner" example:"ali"`
+	Currency   string        `query:"currency" example:"USD"`
+	MinBalance money.Decimal `query:"min_balance" swaggertype:"number" example:"10.00"`
+	MaxBalance money.Decimal `query:"max_balance" swaggertype:"number" example:"5000.00"`
+	Status     string        `query:"status" example:"active,frozen"`
+	SortBy     string        `query:"sort_by" example:"balance"`
+	Sort       string        `query:"sort" example:"desc"`
+	Limit      int           `query:"limit" example:"50"`
+	Cursor     string        `query:"cursor"`
+}
+
+func (query AccountQuery) Validate() error {
+	return validation.ValidateStruct(&query,
+		validation.Field(&query.SortBy, validation.In("created", "balance")),
+		validation.Field(&query.Sort, validation.In("asc", "desc")),
+		validation.Field(&query.Limit, validation.Min(0), validation.Max(MaxPageLimit)),
 	)
 }
+
+// BalanceQuery holds the time a projected balance is read at
+type BalanceQuery struct {
+	AsOf string `query:"as_of" example:"2024-01-31T23:59:59Z"`
+}
@@ -0,0 +1,23 @@
+package requests
+
+import (
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// emailPattern loosely matches an email address: something, an @ and a domain
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// CustomerRequest holds the details of a customer
+type CustomerRequest struct {
+	Name  string `json:"name" example:"Ali Veli"`
+	Email string `json:"email" example:"ali@example.com"`
+}
+
+func (request CustomerRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&request.Email, validation.Length(0, 255), validation.Match(emailPattern)),
+	)
+}
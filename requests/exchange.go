@@ -0,0 +1,31 @@
+package requests
+
+import (
+	"bank-account-manager/money"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type ExchangeRateRequest struct {
+	From string        `json:"from" example:"USD"`
+	To   string        `json:"to" example:"EUR"`
+	Rate money.Decimal `json:"rate" swaggertype:"number" example:"0.92"`
+}
+
+type ExchangeRatesRequest struct {
+	Rates []ExchangeRateRequest `json:"rates"`
+}
+
+func (request ExchangeRateRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.From, validation.Required),
+		validation.Field(&request.To, validation.Required),
+		validation.Field(&request.Rate, validation.Required),
+	)
+}
+
+func (request ExchangeRatesRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.Rates, validation.Required),
+	)
+}
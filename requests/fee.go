@@ -0,0 +1,45 @@
+package requests
+
+import (
+	"bank-account-manager/money"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// FeeRuleRequest prices one operation on one account type. Amounts are in
+// the currency of the account charged. A rule has either a flat fee and rate
+// or tiers
+type FeeRuleRequest struct {
+	Operation   string           `json:"operation" example:"transfer"`                           // deposit, withdrawal or transfer
+	AccountType string           `json:"account_type,omitempty" example:"checking"`              // checking, savings or term, empty for every type
+	Flat        money.Decimal    `json:"flat,omitempty" swaggertype:"number" example:"0.50"`     // Fixed part of the fee
+	Rate        money.Decimal    `json:"rate,omitempty" swaggertype:"number" example:"0.01"`     // Part of the amount, e.g. 0.01 for 1%
+	Tiers       []FeeTierRequest `json:"tiers,omitempty"`                                        // Prices by amount, replacing flat and rate
+	Minimum     money.Decimal    `json:"minimum,omitempty" swaggertype:"number" example:"1.00"`  // Smallest fee charged
+	Maximum     money.Decimal    `json:"maximum,omitempty" swaggertype:"number" example:"25.00"` // Cap of the fee
+}
+
+// FeeTierRequest prices the amounts up to UpTo, or every remaining amount
+// when it is empty
+type FeeTierRequest struct {
+	UpTo money.Decimal `json:"up_to,omitempty" swaggertype:"number" example:"1000.00"`
+	Flat money.Decimal `json:"flat,omitempty" swaggertype:"number" example:"0.50"`
+	Rate money.Decimal `json:"rate,omitempty" swaggertype:"number" example:"0.005"`
+}
+
+type FeeScheduleRequest struct {
+	Rules []FeeRuleRequest `json:"rules"`
+}
+
+func (request FeeRuleRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.Operation, validation.Required, validation.In("deposit", "withdrawal", "transfer")),
+		validation.Field(&request.AccountType, validation.In("checking", "savings", "term")),
+	)
+}
+
+func (request FeeScheduleRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.Rules, validation.NotNil),
+	)
+}
@@ -0,0 +1,20 @@
+package requests
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// ActingCustomerHeader names the customer on whose behalf a request acts.
+// Accounts with holders only let permitted holders send money from them
+const ActingCustomerHeader = "X-Customer-ID"
+
+// HolderRequest holds the role of a customer on an account
+type HolderRequest struct {
+	Role string `json:"role" example:"signatory"`
+}
+
+func (request HolderRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.Role, validation.Required, validation.In("owner", "signatory", "view-only")),
+	)
+}
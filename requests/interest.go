@@ -0,0 +1,21 @@
+package requests
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// InterestAccrualRequest holds the days to accrue interest for, from the
+// first date up to but excluding the second
+type InterestAccrualRequest struct {
+	From string `json:"from" example:"2024-01-01"`
+	To   string `json:"to" example:"2024-02-01"`
+}
+
+func (request InterestAccrualRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.From, validation.Required, validation.Date(time.DateOnly)),
+		validation.Field(&request.To, validation.Required, validation.Date(time.DateOnly)),
+	)
+}
@@ -0,0 +1,7 @@
+package requests
+
+// Page sizes of listings
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
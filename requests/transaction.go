@@ -1,12 +1,16 @@
 package requests
 
 import (
+	"bank-account-manager/money"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
 type TransactionRequest struct {
-	Type   string  `json:"type" example:"deposit/withdrawal"`
-	Amount float64 `json:"amount" example:"100"`
+	Type             string        `json:"type" example:"deposit/withdrawal"`
+	Amount           money.Decimal `json:"amount" swaggertype:"number" example:"100.00"`
+	Currency         string        `json:"currency" example:"USD"`
+	ActingCustomerID string        `json:"-"` // Customer sending a withdrawal, from the ActingCustomerHeader
 }
 
 func (request TransactionRequest) Validate() error {
@@ -15,3 +19,47 @@ func (request TransactionRequest) Validate() error {
 		validation.Field(&request.Amount, validation.Required),
 	)
 }
+
+// TransactionQuery holds the filters, sort order and page of a transaction listing
+type TransactionQuery struct {
+	Type      string        `query:"type" example:"deposit"`
+	MinAmount money.Decimal `query:"min_amount" swaggertype:"number" example:"10.00"`
+	MaxAmount money.Decimal `query:"max_amount" swaggertype:"number" example:"500.00"`
+	From      string        `query:"from" example:"2024-01-01T00:00:00Z"`
+	To        string        `query:"to" example:"2024-02-01T00:00:00Z"`
+	Sort      string        `query:"sort" example:"asc"`
+	Limit     int           `query:"limit" example:"50"`
+	Cursor    string        `query:"cursor"`
+}
+
+func (query TransactionQuery) Validate() error {
+	return validation.ValidateStruct(&query,
+		validation.Field(&query.Sort, validation.In("asc", "desc")),
+		validation.Field(&query.Limit, validation.Min(0), validation.Max(MaxPageLimit)),
+	)
+}
+
+// HoldRequest reserves an amount of an account for a later capture
+type HoldRequest struct {
+	Amount           money.Decimal `json:"amount" swaggertype:"number" example:"100.00"`
+	Currency         string        `json:"currency" example:"USD"`
+	ActingCustomerID string        `json:"-"` // Customer placing the hold, from the ActingCustomerHeader
+}
+
+func (request HoldRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.Amount, validation.Required),
+	)
+}
+
+// CaptureRequest settles a hold, for its whole amount unless one is given
+type CaptureRequest struct {
+	Amount           money.Decimal `json:"amount" swaggertype:"number" example:"60.00"`
+	ActingCustomerID string        `json:"-"` // Customer capturing the hold, from the ActingCustomerHeader
+}
+
+// ReversalRequest undoes a transaction, in full unless an amount is given
+type ReversalRequest struct {
+	Amount           money.Decimal `json:"amount" swaggertype:"number" example:"25.00"`
+	ActingCustomerID string        `json:"-"` // Customer reversing a deposit, from the ActingCustomerHeader
+}
@@ -1,11 +1,16 @@
 package requests
 
-import validation "github.com/go-ozzo/ozzo-validation"
+import (
+	"bank-account-manager/money"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
 
 type TransferRequest struct {
-	FromAccountID string  `json:"from_acount_id"`
-	ToAccountID   string  `json:"to_account_id"`
-	Amount        float64 `json:"amount"`
+	FromAccountID    string        `json:"from_acount_id"`
+	ToAccountID      string        `json:"to_account_id"`
+	Amount           money.Decimal `json:"amount" swaggertype:"number" example:"100.00"`
+	ActingCustomerID string        `json:"-"` // Customer sending the money, from the ActingCustomerHeader
 }
 
 func (request TransferRequest) Validate() error {
@@ -15,3 +20,18 @@ func (request TransferRequest) Validate() error {
 		validation.Field(&request.Amount, validation.Required),
 	)
 }
+
+// TransferQuery holds the filter, sort order and page of a transfer listing
+type TransferQuery struct {
+	AccountID string `query:"account_id"`
+	Sort      string `query:"sort" example:"asc"`
+	Limit     int    `query:"limit" example:"50"`
+	Cursor    string `query:"cursor"`
+}
+
+func (query TransferQuery) Validate() error {
+	return validation.ValidateStruct(&query,
+		validation.Field(&query.Sort, validation.In("asc", "desc")),
+		validation.Field(&query.Limit, validation.Min(0), validation.Max(MaxPageLimit)),
+	)
+}
@@ -2,33 +2,87 @@ package responses
 
 import (
 	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID      string  `json:"id"`
-	Owner   string  `json:"owner"`
-	Balance float64 `json:"balance"`
+	ID         string      `json:"id"`
+	CustomerID *string     `json:"customer_id"`
+	Owner      string      `json:"owner"`
+	Currency   string      `json:"currency" example:"USD"`
+	Balance    money.Money `json:"balance" swaggertype:"number" example:"100.00"`
+	Overdraft  money.Money `json:"overdraft_limit" swaggertype:"number" example:"500.00"`
+	Held       money.Money `json:"held" swaggertype:"number" example:"0.00"`
+	Available  money.Money `json:"available_balance" swaggertype:"number" example:"600.00"`
+	Interest   *money.Rate `json:"interest_rate" swaggertype:"number" example:"0.035"`
+	DayCount   string      `json:"day_count" example:"ACT/365"`
+	Type       string      `json:"type" example:"checking"`
+	MaturesAt  *string     `json:"matures_at"`
+	Status     string      `json:"status" example:"active"`
+	CreatedAt  string      `json:"created_at"`
+}
+
+type AccountPage struct {
+	Data       []Account `json:"data"`
+	NextCursor *string   `json:"next_cursor"`
 }
 
 func AccountResponse(ctx *fiber.Ctx, status int, account models.Account) error {
-	return Response(ctx, status, Account{
-		ID:      account.ID.String(),
-		Owner:   account.Owner,
-		Balance: account.Balance,
-	})
+	return Response(ctx, status, accountResponse(account))
 }
 
 func AccountResponses(ctx *fiber.Ctx, status int, accounts []models.Account) error {
-	accountResponses := []Account{}
+	response := []Account{}
 	for _, account := range accounts {
-		accountResponses = append(accountResponses, Account{
-			ID:      account.ID.String(),
-			Owner:   account.Owner,
-			Balance: account.Balance,
-		})
+		response = append(response, accountResponse(account))
 	}
+	return Response(ctx, status, response)
+}
 
-	return Response(ctx, status, accountResponses)
+func AccountPageResponse(ctx *fiber.Ctx, status int, page models.AccountPage) error {
+	response := AccountPage{Data: []Account{}}
+	for _, account := range page.Accounts {
+		response.Data = append(response.Data, accountResponse(account))
+	}
+	if page.NextCursor != "" {
+		response.NextCursor = &page.NextCursor
+	}
+	return Response(ctx, status, response)
+}
+
+// accountResponse converts an account model
+func accountResponse(account models.Account) Account {
+	// Every change to the balance, overdraft or held funds of an account
+	// checks its available funds still compute
+	available, _ := account.Available()
+	response := Account{
+		ID:        account.ID.String(),
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		Balance:   account.Balance,
+		Overdraft: account.Overdraft,
+		Held:      account.Held,
+		Available: available,
+		DayCount:  account.DayCount.String(),
+		Type:      account.Type.String(),
+		Status:    account.Status.String(),
+		CreatedAt: account.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if !account.MaturesAt.IsZero() {
+		maturesAt := account.MaturesAt.Format(time.RFC3339Nano)
+		response.MaturesAt = &maturesAt
+	}
+	if account.InterestRate.Units != 0 {
+		interestRate := account.InterestRate
+		response.Interest = &interestRate
+	}
+	if account.CustomerID != uuid.Nil {
+		customerID := account.CustomerID.String()
+		response.CustomerID = &customerID
+	}
+	return response
 }
@@ -0,0 +1,63 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Customer struct {
+	ID        string `json:"id"`
+	Name      string `json:"name" example:"Ali Veli"`
+	Email     string `json:"email" example:"ali@example.com"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CurrencyBalance struct {
+	Currency string      `json:"currency" example:"USD"`
+	Amount   money.Money `json:"amount" swaggertype:"number" example:"100.00"`
+}
+
+type NetWorth struct {
+	CustomerID string            `json:"customer_id"`
+	Balances   []CurrencyBalance `json:"balances"`
+	Currency   string            `json:"currency,omitempty" example:"USD"`
+	Total      *money.Money      `json:"total,omitempty" swaggertype:"number" example:"250.00"`
+}
+
+func CustomerResponse(ctx *fiber.Ctx, status int, customer models.Customer) error {
+	return Response(ctx, status, customerResponse(customer))
+}
+
+func CustomerResponses(ctx *fiber.Ctx, status int, customers []models.Customer) error {
+	response := []Customer{}
+	for _, customer := range customers {
+		response = append(response, customerResponse(customer))
+	}
+	return Response(ctx, status, response)
+}
+
+func NetWorthResponse(ctx *fiber.Ctx, status int, netWorth models.NetWorth) error {
+	response := NetWorth{
+		CustomerID: netWorth.CustomerID.String(),
+		Balances:   []CurrencyBalance{},
+		Currency:   netWorth.Currency,
+		Total:      netWorth.Total,
+	}
+	for _, balance := range netWorth.Balances {
+		response.Balances = append(response.Balances, CurrencyBalance{Currency: balance.Currency, Amount: balance.Amount})
+	}
+	return Response(ctx, status, response)
+}
+
+// customerResponse converts a customer model
+func customerResponse(customer models.Customer) Customer {
+	return Customer{
+		ID:        customer.ID.String(),
+		Name:      customer.Name,
+		Email:     customer.Email,
+		CreatedAt: customer.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,93 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Event struct {
+	Sequence       int64       `json:"sequence" example:"42"`
+	Type           string      `json:"type" example:"funds_deposited"`
+	AccountID      string      `json:"account_id"`
+	CounterpartyID *string     `json:"counterparty_id,omitempty"` // Account receiving a transfer
+	ReferenceID    *string     `json:"reference_id,omitempty"`    // Transaction, transfer or hold behind the event
+	Currency       string      `json:"currency" example:"USD"`
+	Amount         money.Money `json:"amount" swaggertype:"number" example:"100.00"`
+	Status         string      `json:"status" example:"active"`
+	TimeStamp      string      `json:"timestamp"`
+}
+
+type AccountBalance struct {
+	AccountID string      `json:"account_id"`
+	Currency  string      `json:"currency" example:"USD"`
+	Balance   money.Money `json:"balance" swaggertype:"number" example:"600.00"`
+	Held      money.Money `json:"held" swaggertype:"number" example:"0.00"`
+	Overdraft money.Money `json:"overdraft_limit" swaggertype:"number" example:"0.00"`
+	Available money.Money `json:"available_balance" swaggertype:"number" example:"600.00"`
+	Status    string      `json:"status" example:"active"`
+	Version   int64       `json:"version" example:"42"`
+	UpdatedAt string      `json:"updated_at"`
+}
+
+func EventResponses(ctx *fiber.Ctx, status int, events []models.Event) error {
+	response := []Event{}
+	for _, event := range events {
+		response = append(response, eventResponse(event))
+	}
+	return Response(ctx, status, response)
+}
+
+func AccountBalanceResponse(ctx *fiber.Ctx, status int, balance models.AccountBalance) error {
+	return Response(ctx, status, accountBalanceResponse(balance))
+}
+
+func AccountBalanceResponses(ctx *fiber.Ctx, status int, balances []models.AccountBalance) error {
+	response := []AccountBalance{}
+	for _, balance := range balances {
+		response = append(response, accountBalanceResponse(balance))
+	}
+	return Response(ctx, status, response)
+}
+
+// accountBalanceResponse converts an account balance read model
+func accountBalanceResponse(balance models.AccountBalance) AccountBalance {
+	// The events only record changes whose available funds computed
+	available, _ := balance.Available()
+	return AccountBalance{
+		AccountID: balance.AccountID.String(),
+		Currency:  balance.Currency,
+		Balance:   balance.Balance,
+		Held:      balance.Held,
+		Overdraft: balance.Overdraft,
+		Available: available,
+		Status:    balance.Status.String(),
+		Version:   balance.Version,
+		UpdatedAt: balance.UpdatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+// eventResponse converts an event model
+func eventResponse(event models.Event) Event {
+	response := Event{
+		Sequence:  event.Sequence,
+		Type:      event.Type.String(),
+		AccountID: event.AccountID.String(),
+		Currency:  event.Currency,
+		Amount:    event.Amount,
+		Status:    event.Status.String(),
+		TimeStamp: event.TimeStamp.Format(time.RFC3339Nano),
+	}
+	if event.CounterpartyID != uuid.Nil {
+		counterpartyID := event.CounterpartyID.String()
+		response.CounterpartyID = &counterpartyID
+	}
+	if event.ReferenceID != uuid.Nil {
+		referenceID := event.ReferenceID.String()
+		response.ReferenceID = &referenceID
+	}
+	return response
+}
@@ -0,0 +1,51 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExchangeRate struct {
+	From      string     `json:"from" example:"USD"`
+	To        string     `json:"to" example:"EUR"`
+	Rate      money.Rate `json:"rate" swaggertype:"number" example:"0.92"`
+	UpdatedAt string     `json:"updated_at"`
+}
+
+type Exchange struct {
+	Rate                money.Rate  `json:"rate" swaggertype:"number" example:"0.92"`
+	SourceCurrency      string      `json:"source_currency" example:"USD"`
+	SourceAmount        money.Money `json:"source_amount" swaggertype:"number" example:"100.00"`
+	DestinationCurrency string      `json:"destination_currency" example:"EUR"`
+	DestinationAmount   money.Money `json:"destination_amount" swaggertype:"number" example:"92.00"`
+}
+
+func ExchangeRateResponses(ctx *fiber.Ctx, status int, rates []models.ExchangeRate) error {
+	rateResponses := []ExchangeRate{}
+	for _, rate := range rates {
+		rateResponses = append(rateResponses, ExchangeRate{
+			From:      rate.From,
+			To:        rate.To,
+			Rate:      rate.Rate,
+			UpdatedAt: rate.UpdatedAt.Format(time.RFC3339Nano),
+		})
+	}
+	return Response(ctx, status, rateResponses)
+}
+
+// exchangeResponse converts an optional exchange record
+func exchangeResponse(exchange *models.Exchange) *Exchange {
+	if exchange == nil {
+		return nil
+	}
+	return &Exchange{
+		Rate:                exchange.Rate,
+		SourceCurrency:      exchange.SourceCurrency,
+		SourceAmount:        exchange.SourceAmount,
+		DestinationCurrency: exchange.DestinationCurrency,
+		DestinationAmount:   exchange.DestinationAmount,
+	}
+}
@@ -0,0 +1,48 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FeeTier struct {
+	UpTo *money.Money `json:"up_to" swaggertype:"number" example:"1000.00"`
+	Flat money.Money  `json:"flat" swaggertype:"number" example:"0.50"`
+	Rate *money.Rate  `json:"rate" swaggertype:"number" example:"0.01"`
+}
+
+type FeeRule struct {
+	Operation   string       `json:"operation" example:"transfer"`
+	AccountType *string      `json:"account_type" example:"checking"`
+	Tiers       []FeeTier    `json:"tiers"`
+	Minimum     money.Money  `json:"minimum" swaggertype:"number" example:"0.00"`
+	Maximum     *money.Money `json:"maximum" swaggertype:"number" example:"25.00"`
+}
+
+func FeeRuleResponses(ctx *fiber.Ctx, status int, rules []models.FeeRule) error {
+	response := []FeeRule{}
+	for _, rule := range rules {
+		ruleResponse := FeeRule{
+			Operation: rule.Operation,
+			Tiers:     []FeeTier{},
+			Minimum:   rule.Minimum,
+			Maximum:   rule.Maximum,
+		}
+		if rule.AccountType != nil {
+			accountType := rule.AccountType.String()
+			ruleResponse.AccountType = &accountType
+		}
+		for _, tier := range rule.Tiers {
+			tierResponse := FeeTier{UpTo: tier.UpTo, Flat: tier.Flat}
+			if tier.Rate.Units != 0 {
+				rate := tier.Rate
+				tierResponse.Rate = &rate
+			}
+			ruleResponse.Tiers = append(ruleResponse.Tiers, tierResponse)
+		}
+		response = append(response, ruleResponse)
+	}
+	return Response(ctx, status, response)
+}
@@ -0,0 +1,51 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Hold struct {
+	ID            string      `json:"id"`
+	AccountID     string      `json:"account_id"`
+	Amount        money.Money `json:"amount" swaggertype:"number" example:"100.00"`
+	Captured      money.Money `json:"captured" swaggertype:"number" example:"60.00"`
+	TransactionID *string     `json:"transaction_id"` // Withdrawal settling a captured hold
+	Status        string      `json:"status" example:"pending"`
+	CreatedAt     string      `json:"created_at"`
+	ExpiresAt     string      `json:"expires_at"`
+}
+
+func HoldResponse(ctx *fiber.Ctx, status int, hold models.Hold) error {
+	return Response(ctx, status, holdResponse(hold))
+}
+
+func HoldResponses(ctx *fiber.Ctx, status int, holds []models.Hold) error {
+	response := []Hold{}
+	for _, hold := range holds {
+		response = append(response, holdResponse(hold))
+	}
+	return Response(ctx, status, response)
+}
+
+// holdResponse converts a hold model
+func holdResponse(hold models.Hold) Hold {
+	response := Hold{
+		ID:        hold.ID.String(),
+		AccountID: hold.AccountID.String(),
+		Amount:    hold.Amount,
+		Captured:  hold.Captured,
+		Status:    hold.Status.String(),
+		CreatedAt: hold.CreatedAt.Format(time.RFC3339Nano),
+		ExpiresAt: hold.ExpiresAt.Format(time.RFC3339Nano),
+	}
+	if hold.TransactionID != uuid.Nil {
+		transactionID := hold.TransactionID.String()
+		response.TransactionID = &transactionID
+	}
+	return response
+}
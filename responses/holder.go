@@ -0,0 +1,34 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccountHolder struct {
+	AccountID  string `json:"account_id"`
+	CustomerID string `json:"customer_id"`
+	Role       string `json:"role" example:"signatory"`
+}
+
+func AccountHolderResponse(ctx *fiber.Ctx, status int, holder models.AccountHolder) error {
+	return Response(ctx, status, accountHolderResponse(holder))
+}
+
+func AccountHolderResponses(ctx *fiber.Ctx, status int, holders []models.AccountHolder) error {
+	response := []AccountHolder{}
+	for _, holder := range holders {
+		response = append(response, accountHolderResponse(holder))
+	}
+	return Response(ctx, status, response)
+}
+
+// accountHolderResponse converts an account holder model
+func accountHolderResponse(holder models.AccountHolder) AccountHolder {
+	return AccountHolder{
+		AccountID:  holder.AccountID.String(),
+		CustomerID: holder.CustomerID.String(),
+		Role:       holder.Role.String(),
+	}
+}
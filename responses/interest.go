@@ -0,0 +1,29 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type InterestRun struct {
+	AccruedDays int               `json:"accrued_days" example:"31"`
+	Payments    []Transaction     `json:"payments"`
+	Failures    []InterestFailure `json:"failures"`
+}
+
+type InterestFailure struct {
+	AccountID string `json:"account_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Error     string `json:"error" example:"invalid amount"`
+}
+
+func InterestRunResponse(ctx *fiber.Ctx, status int, run models.InterestRun) error {
+	response := InterestRun{AccruedDays: run.Accrued, Payments: []Transaction{}, Failures: []InterestFailure{}}
+	for _, transaction := range run.Paid {
+		response.Payments = append(response.Payments, transactionResponse(transaction))
+	}
+	for _, failure := range run.Failed {
+		response.Failures = append(response.Failures, InterestFailure{AccountID: failure.AccountID.String(), Error: failure.Err.Error()})
+	}
+	return Response(ctx, status, response)
+}
@@ -0,0 +1,55 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrialBalanceLine struct {
+	AccountID string      `json:"account_id"`
+	Name      string      `json:"name" example:"external"`
+	Currency  string      `json:"currency" example:"USD"`
+	Debits    money.Money `json:"debits" swaggertype:"number" example:"0.00"`
+	Credits   money.Money `json:"credits" swaggertype:"number" example:"100.00"`
+	Balance   money.Money `json:"balance" swaggertype:"number" example:"100.00"`
+}
+
+type TrialBalanceTotal struct {
+	Currency string      `json:"currency" example:"USD"`
+	Debits   money.Money `json:"debits" swaggertype:"number" example:"100.00"`
+	Credits  money.Money `json:"credits" swaggertype:"number" example:"100.00"`
+}
+
+type TrialBalance struct {
+	Lines    []TrialBalanceLine  `json:"lines"`
+	Totals   []TrialBalanceTotal `json:"totals"`
+	Balanced bool                `json:"balanced" example:"true"`
+}
+
+func TrialBalanceResponse(ctx *fiber.Ctx, status int, trialBalance models.TrialBalance) error {
+	response := TrialBalance{
+		Lines:    []TrialBalanceLine{},
+		Totals:   []TrialBalanceTotal{},
+		Balanced: trialBalance.Balanced,
+	}
+	for _, line := range trialBalance.Lines {
+		response.Lines = append(response.Lines, TrialBalanceLine{
+			AccountID: line.AccountID.String(),
+			Name:      line.Name,
+			Currency:  line.Currency,
+			Debits:    line.Debits,
+			Credits:   line.Credits,
+			Balance:   line.Balance,
+		})
+	}
+	for _, total := range trialBalance.Totals {
+		response.Totals = append(response.Totals, TrialBalanceTotal{
+			Currency: total.Currency,
+			Debits:   total.Debits,
+			Credits:  total.Credits,
+		})
+	}
+	return Response(ctx, status, response)
+}
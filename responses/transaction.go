@@ -2,39 +2,78 @@ package responses
 
 import (
 	"bank-account-manager/models"
+	"bank-account-manager/money"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type Transaction struct {
-	ID        string  `json:"id"`
-	AccountID string  `json:"account_id"`
-	Type      string  `json:"type"`
-	Amount    float64 `json:"amount"`
-	TimeStamp string  `json:"timestamp"`
+	ID         string      `json:"id"`
+	AccountID  string      `json:"account_id"`
+	Type       string      `json:"type"`
+	Currency   string      `json:"currency" example:"USD"`
+	Amount     money.Money `json:"amount" swaggertype:"number" example:"100.00"`
+	Exchange   *Exchange   `json:"exchange,omitempty"`
+	RelatedID  *string     `json:"related_id,omitempty"`  // Transaction a fee was charged for or a reversal undoes
+	TransferID *string     `json:"transfer_id,omitempty"` // Transfer the leg or fee belongs to
+	TimeStamp  string      `json:"timestamp"`
 }
 
 func TransactionResponse(ctx *fiber.Ctx, status int, transaction models.Transaction) error {
-	return Response(ctx, status, Transaction{
+	return Response(ctx, status, transactionResponse(transaction))
+}
+
+type TransactionPage struct {
+	Data       []Transaction `json:"data"`
+	NextCursor *string       `json:"next_cursor"`
+}
+
+func TransactionPageResponse(ctx *fiber.Ctx, status int, page models.TransactionPage) error {
+	response := TransactionPage{Data: []Transaction{}}
+	for _, transaction := range page.Transactions {
+		response.Data = append(response.Data, transactionResponse(transaction))
+	}
+	if page.NextCursor != "" {
+		response.NextCursor = &page.NextCursor
+	}
+	return Response(ctx, status, response)
+}
+
+// transactionResponse converts a transaction model
+func transactionResponse(transaction models.Transaction) Transaction {
+	response := Transaction{
 		ID:        transaction.ID.String(),
 		AccountID: transaction.AccountID.String(),
 		Type:      transaction.Type.String(),
+		Currency:  transaction.Currency,
 		Amount:    transaction.Amount,
+		Exchange:  exchangeResponse(transaction.Exchange),
 		TimeStamp: transaction.TimeStamp.Format(time.RFC3339Nano),
-	})
+	}
+	if transaction.RelatedID != uuid.Nil {
+		relatedID := transaction.RelatedID.String()
+		response.RelatedID = &relatedID
+	}
+	if transaction.TransferID != uuid.Nil {
+		transferID := transaction.TransferID.String()
+		response.TransferID = &transferID
+	}
+	return response
+}
+
+// Reversal holds the transactions undoing a deposit, a withdrawal or both
+// legs of a transfer
+type Reversal struct {
+	Message      string        `json:"message" example:"Successfully reversed"`
+	Transactions []Transaction `json:"transactions"`
 }
 
-func TransactionResponses(ctx *fiber.Ctx, status int, transactions []models.Transaction) error {
-	transactionResponses := []Transaction{}
-	for _, account := range transactions {
-		transactionResponses = append(transactionResponses, Transaction{
-			ID:        account.ID.String(),
-			AccountID: account.AccountID.String(),
-			Type:      account.Type.String(),
-			Amount:    account.Amount,
-			TimeStamp: account.TimeStamp.Format(time.RFC3339Nano),
-		})
+func ReversalResponse(ctx *fiber.Ctx, status int, message string, reversals []models.Transaction) error {
+	response := Reversal{Message: message, Transactions: []Transaction{}}
+	for _, reversal := range reversals {
+		response.Transactions = append(response.Transactions, transactionResponse(reversal))
 	}
-	return Response(ctx, status, transactionResponses)
+	return Response(ctx, status, response)
 }
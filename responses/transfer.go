@@ -0,0 +1,63 @@
+package responses
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Transfer holds a transfer with both of its legs and the fees it cost
+type Transfer struct {
+	ID            string        `json:"id"`
+	FromAccountID string        `json:"from_account_id"`
+	ToAccountID   string        `json:"to_account_id"`
+	Currency      string        `json:"currency" example:"USD"`
+	Amount        money.Money   `json:"amount" swaggertype:"number" example:"100.00"`
+	Status        string        `json:"status" example:"completed"`
+	TimeStamp     string        `json:"timestamp"`
+	Withdrawal    Transaction   `json:"withdrawal"`
+	Deposit       Transaction   `json:"deposit"`
+	Fees          []Transaction `json:"fees"`
+}
+
+type TransferPage struct {
+	Data       []Transfer `json:"data"`
+	NextCursor *string    `json:"next_cursor"`
+}
+
+func TransferResponse(ctx *fiber.Ctx, status int, transfer models.Transfer) error {
+	return Response(ctx, status, transferResponse(transfer))
+}
+
+func TransferPageResponse(ctx *fiber.Ctx, status int, page models.TransferPage) error {
+	response := TransferPage{Data: []Transfer{}}
+	for _, transfer := range page.Transfers {
+		response.Data = append(response.Data, transferResponse(transfer))
+	}
+	if page.NextCursor != "" {
+		response.NextCursor = &page.NextCursor
+	}
+	return Response(ctx, status, response)
+}
+
+// transferResponse converts a transfer model
+func transferResponse(transfer models.Transfer) Transfer {
+	response := Transfer{
+		ID:            transfer.ID.String(),
+		FromAccountID: transfer.FromAccountID.String(),
+		ToAccountID:   transfer.ToAccountID.String(),
+		Currency:      transfer.Currency,
+		Amount:        transfer.Amount,
+		Status:        transfer.Status.String(),
+		TimeStamp:     transfer.TimeStamp.Format(time.RFC3339Nano),
+		Withdrawal:    transactionResponse(transfer.Withdrawal),
+		Deposit:       transactionResponse(transfer.Deposit),
+		Fees:          []Transaction{},
+	}
+	for _, fee := range transfer.Fees {
+		response.Fees = append(response.Fees, transactionResponse(fee))
+	}
+	return response
+}
@@ -3,7 +3,9 @@ package routes
 import (
 	_ "bank-account-manager/docs"
 	"bank-account-manager/handlers"
+	"bank-account-manager/middlewares"
 	"bank-account-manager/server"
+	"bank-account-manager/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
@@ -12,19 +14,80 @@ import (
 func ConfigRoutes(server *server.Server) {
 	server.App.Get("/swagger/*", swagger.HandlerDefault)
 	server.App.Get("/", redirectToSwagger)
-	apiV1 := server.App.Group("api/v1")
+	// Make every mutating endpoint safe to retry with an Idempotency-Key
+	idempotencyService := services.CreateIdempotencyService(server.Storage)
+	if server.Config.IdempotencyTTL > 0 {
+		idempotencyService.TTL = server.Config.IdempotencyTTL
+	}
+	apiV1 := server.App.Group("api/v1", middlewares.Idempotency(idempotencyService))
 
 	accountHandler := handlers.CreateAccountHandler(server)
 
 	apiV1.Post("/accounts", accountHandler.Create)
 	apiV1.Get("/accounts/:id", accountHandler.ReadOne)
 	apiV1.Get("/accounts", accountHandler.ReadAll)
+	apiV1.Patch("/accounts/:id", accountHandler.Update)
+	apiV1.Put("/accounts/:id/overdraft", accountHandler.SetOverdraft)
+	apiV1.Post("/accounts/:id/freeze", accountHandler.Freeze)
+	apiV1.Post("/accounts/:id/unfreeze", accountHandler.Unfreeze)
+	apiV1.Post("/accounts/:id/close", accountHandler.Close)
+	apiV1.Post("/accounts/:id/reopen", accountHandler.Reopen)
+
+	eventHandler := handlers.CreateEventHandler(server)
+
+	apiV1.Get("/accounts/:id/events", eventHandler.ReadByAccount)
+	apiV1.Get("/accounts/:id/balance", eventHandler.Balance)
+
+	holderHandler := handlers.CreateHolderHandler(server)
+
+	apiV1.Get("/accounts/:id/holders", holderHandler.ReadAll)
+	apiV1.Put("/accounts/:id/holders/:customerId", holderHandler.Save)
+	apiV1.Delete("/accounts/:id/holders/:customerId", holderHandler.Delete)
+
+	customerHandler := handlers.CreateCustomerHandler(server)
+
+	apiV1.Post("/customers", customerHandler.Create)
+	apiV1.Get("/customers", customerHandler.ReadAll)
+	apiV1.Get("/customers/:id", customerHandler.ReadOne)
+	apiV1.Patch("/customers/:id", customerHandler.Update)
+	apiV1.Delete("/customers/:id", customerHandler.Delete)
+	apiV1.Get("/customers/:id/accounts", customerHandler.ReadAccounts)
+	apiV1.Get("/customers/:id/net-worth", customerHandler.NetWorth)
 
 	transactionHandler := handlers.CreateTransactionHandler(server)
 
 	apiV1.Post("/accounts/:id/transactions", transactionHandler.Create)
 	apiV1.Get("/accounts/:id/transactions", transactionHandler.ReadByAccount)
 	apiV1.Post("/transfer", transactionHandler.Transfer)
+	apiV1.Get("/transfers", transactionHandler.ReadTransfers)
+	apiV1.Get("/transfers/:id", transactionHandler.ReadTransfer)
+	apiV1.Post("/transactions/:id/reverse", transactionHandler.Reverse)
+	apiV1.Post("/accounts/:id/holds", transactionHandler.PlaceHold)
+	apiV1.Get("/accounts/:id/holds", transactionHandler.ReadHolds)
+	apiV1.Post("/holds/:id/capture", transactionHandler.CaptureHold)
+	apiV1.Post("/holds/:id/release", transactionHandler.ReleaseHold)
+
+	exchangeHandler := handlers.CreateExchangeHandler(server)
+
+	apiV1.Get("/exchange-rates", exchangeHandler.ReadAll)
+
+	feeHandler := handlers.CreateFeeHandler(server)
+
+	apiV1.Get("/fees", feeHandler.ReadAll)
+
+	ledgerHandler := handlers.CreateLedgerHandler(server)
+
+	apiV1.Get("/ledger/trial-balance", ledgerHandler.TrialBalance)
+
+	admin := apiV1.Group("/admin", middlewares.AdminOnly(server.Config.AdminToken))
+
+	admin.Put("/exchange-rates", exchangeHandler.Update)
+	admin.Put("/fees", feeHandler.Update)
+
+	interestHandler := handlers.CreateInterestHandler(server)
+
+	admin.Post("/interest/accrue", interestHandler.Accrue)
+	admin.Post("/projections/balances/rebuild", eventHandler.RebuildBalances)
 }
 
 func redirectToSwagger(context *fiber.Ctx) error {
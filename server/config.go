@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings used to assemble a Server
+type Config struct {
+	Storage           storage.Config        // Storage backend selection
+	ExchangeRatesPath string                // JSON file holding the exchange rate table
+	FeeSchedulePath   string                // JSON file holding the fee schedule
+	AdminToken        string                // Token required by admin endpoints, which are disabled when unset
+	IdempotencyTTL    time.Duration         // How long idempotency keys are remembered
+	IdempotencySweep  time.Duration         // How often expired idempotency keys are deleted, 0 to never
+	AccountRules      services.AccountRules // Rules enforced per account type
+	InterestInterval  time.Duration         // How often the server accrues interest, 0 to never
+	HoldTTL           time.Duration         // How long holds reserve funds before they expire
+}
+
+// DefaultIdempotencySweep is how often the server deletes expired
+// idempotency keys unless configured otherwise
+const DefaultIdempotencySweep = time.Hour
+
+// DefaultInterestInterval is how often the server accrues interest unless
+// configured otherwise. Runs only accrue the days that ended since the last one
+const DefaultInterestInterval = time.Hour
+
+// LoadConfig reads the server configuration from environment variables
+func LoadConfig() (Config, error) {
+	storagePath := os.Getenv("STORAGE_PATH")
+	if storagePath == "" {
+		storagePath = "bank.db"
+	}
+
+	// Parse the idempotency key window, e.g. "24h" or "90m"
+	idempotencyTTL := services.DefaultIdempotencyTTL
+	if value := os.Getenv("IDEMPOTENCY_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return Config{}, fmt.Errorf("invalid IDEMPOTENCY_TTL: %s", value)
+		}
+		idempotencyTTL = parsed
+	}
+
+	// Parse how often expired keys are deleted, where 0 turns the sweeper off
+	idempotencySweep := DefaultIdempotencySweep
+	if value := os.Getenv("IDEMPOTENCY_SWEEP_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return Config{}, fmt.Errorf("invalid IDEMPOTENCY_SWEEP_INTERVAL: %s", value)
+		}
+		idempotencySweep = parsed
+	}
+
+	// Parse the account type rules, keeping the defaults for unset values
+	accountRules := services.DefaultAccountRules
+	if value := os.Getenv("SAVINGS_WITHDRAWAL_LIMIT"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return Config{}, fmt.Errorf("invalid SAVINGS_WITHDRAWAL_LIMIT: %s", value)
+		}
+		accountRules.SavingsWithdrawals = parsed
+	}
+	if value := os.Getenv("CHECKING_FREE_TRANSACTIONS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return Config{}, fmt.Errorf("invalid CHECKING_FREE_TRANSACTIONS: %s", value)
+		}
+		accountRules.CheckingFreeTransactions = parsed
+	}
+	if value := os.Getenv("CHECKING_TRANSACTION_FEES"); value != "" {
+		fees, err := parseCurrencyAmounts(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid CHECKING_TRANSACTION_FEES: %s", value)
+		}
+		accountRules.CheckingTransactionFees = fees
+	}
+
+	// Parse how often interest is accrued, where 0 turns the scheduler off
+	interestInterval := DefaultInterestInterval
+	if value := os.Getenv("INTEREST_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return Config{}, fmt.Errorf("invalid INTEREST_INTERVAL: %s", value)
+		}
+		interestInterval = parsed
+	}
+
+	// Parse how long holds reserve funds, e.g. "168h"
+	holdTTL := services.DefaultHoldTTL
+	if value := os.Getenv("HOLD_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return Config{}, fmt.Errorf("invalid HOLD_TTL: %s", value)
+		}
+		holdTTL = parsed
+	}
+
+	// Parse how many journal records are kept before a snapshot is taken
+	snapshotEvery := storage.DefaultSnapshotEvery
+	if value := os.Getenv("JOURNAL_SNAPSHOT_EVERY"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return Config{}, fmt.Errorf("invalid JOURNAL_SNAPSHOT_EVERY: %s", value)
+		}
+		snapshotEvery = parsed
+	}
+
+	return Config{
+		Storage: storage.Config{
+			Driver:        os.Getenv("STORAGE_DRIVER"),
+			Path:          storagePath,
+			SnapshotEvery: snapshotEvery,
+		},
+		ExchangeRatesPath: os.Getenv("EXCHANGE_RATES_FILE"),
+		FeeSchedulePath:   os.Getenv("FEE_SCHEDULE_FILE"),
+		AdminToken:        os.Getenv("ADMIN_TOKEN"),
+		IdempotencyTTL:    idempotencyTTL,
+		IdempotencySweep:  idempotencySweep,
+		AccountRules:      accountRules,
+		InterestInterval:  interestInterval,
+		HoldTTL:           holdTTL,
+	}, nil
+}
+
+// parseCurrencyAmounts parses a comma separated list of amounts, each
+// prefixed by its currency code and a colon such as "USD:1.50,JPY:200",
+// into non-negative amounts in the minor units of their currency
+func parseCurrencyAmounts(value string) (map[string]money.Money, error) {
+	amounts := map[string]money.Money{}
+	for _, entry := range strings.Split(value, ",") {
+		code, amount, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			return nil, fmt.Errorf("missing currency in %q", entry)
+		}
+		currency, err := money.LookupCurrency(code)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := currency.Parse(money.Decimal(amount))
+		if err != nil {
+			return nil, err
+		}
+		if parsed.IsNegative() {
+			return nil, fmt.Errorf("negative amount in %q", entry)
+		}
+		amounts[currency.Code] = parsed
+	}
+	return amounts, nil
+}
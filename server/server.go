@@ -1,24 +1,51 @@
 package server
 
 import (
+	"bank-account-manager/services"
 	"bank-account-manager/storage"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type Server struct {
-	App     *fiber.App
-	Storage *storage.Storage
+	App      *fiber.App
+	Config   Config
+	Storage  storage.Storage
+	Exchange *services.ExchangeService // Exchange rate table shared by all handlers
+	Fees     *services.FeeService      // Fee schedule shared by all handlers
 }
 
-func Create() *Server {
+// Create assembles a new Server using the storage backend selected by config
+func Create(config Config) (*Server, error) {
 	app := fiber.New()
-	storage := storage.Create()
+	storage, err := storage.Open(config.Storage)
+	if err != nil {
+		return nil, err
+	}
 
-	return &Server{
-		App:     app,
-		Storage: storage,
+	// Load the exchange rate table when a file is configured
+	exchange := services.CreateExchangeService()
+	if config.ExchangeRatesPath != "" {
+		if err := exchange.Load(config.ExchangeRatesPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Load the fee schedule when a file is configured
+	fees := services.CreateFeeService()
+	if config.FeeSchedulePath != "" {
+		if err := fees.Load(config.FeeSchedulePath); err != nil {
+			return nil, err
+		}
 	}
+
+	return &Server{
+		App:      app,
+		Config:   config,
+		Storage:  storage,
+		Exchange: exchange,
+		Fees:     fees,
+	}, nil
 }
 
 func (server *Server) Listen(port string) error {
@@ -2,40 +2,145 @@ package services
 
 import (
 	"bank-account-manager/models"
+	"bank-account-manager/money"
 	"bank-account-manager/requests"
 	"bank-account-manager/storage"
 	"bank-account-manager/utils"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
 type AccountService struct {
-	Storage *storage.Storage
+	Storage storage.Storage
+	Clock   utils.Clock // Source of creation dates, event timestamps and hold expiry
 }
 
 // CreateAccountService initializes a new AccountService with the provided storage
-func CreateAccountService(storage *storage.Storage) *AccountService {
+func CreateAccountService(storage storage.Storage) *AccountService {
 	return &AccountService{
 		Storage: storage,
+		Clock:   utils.SystemClock{},
 	}
 }
 
 // Create generates a new account based on the provided request
 func (service *AccountService) Create(request requests.AccountRequest) (models.Account, error) {
-	// Create a new Account instance with the request data
+	// Resolve the account currency, defaulting when none is given
+	currencyCode := request.Currency
+	if currencyCode == "" {
+		currencyCode = money.DefaultCurrency
+	}
+	currency, err := money.LookupCurrency(currencyCode)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	// Parse the initial balance exactly, rejecting negative amounts
+	initialBalance, err := currency.Parse(request.InitialBalance)
+	if err != nil {
+		return models.Account{}, err
+	}
+	if initialBalance.IsNegative() {
+		return models.Account{}, utils.ErrInvalidAmount
+	}
+
+	// Parse the account type and the maturity date of term deposits
+	createdAt := service.Clock.Now()
+	accountType := utils.Checking
+	if request.Type != "" {
+		if accountType, err = utils.ParseAccountType(request.Type); err != nil {
+			return models.Account{}, utils.ErrInvalidAccountType
+		}
+	}
+	maturesAt, err := parseMaturity(accountType, request.MaturityDate, createdAt)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	// Parse the overdraft limit, which only checking accounts may have
+	overdraft, err := parseOverdraft(request.OverdraftLimit, currency, accountType)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	// Parse the interest rate, which only savings and term accounts may have
+	interestRate, dayCount, err := parseInterest(request.InterestRate, request.DayCount, accountType)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	// Parse the customer holding the account, if any
+	customerID := uuid.Nil
+	if request.CustomerID != "" {
+		if customerID, err = uuid.Parse(request.CustomerID); err != nil {
+			return models.Account{}, utils.ErrInvalidUUID
+		}
+	}
+
+	// Create a new active Account instance with the request data. The
+	// balance starts at zero and is opened by a journal entry
 	newUUID := uuid.New()
 	account := models.Account{
-		ID:      newUUID,
-		Owner:   request.Owner,
-		Balance: request.InitialBalance,
+		ID:           newUUID,
+		CustomerID:   customerID,
+		Owner:        request.Owner,
+		Currency:     currency.Code,
+		Balance:      currency.Zero(),
+		Overdraft:    overdraft,
+		Held:         currency.Zero(),
+		InterestRate: interestRate,
+		DayCount:     dayCount,
+		Type:         accountType,
+		MaturesAt:    maturesAt,
+		Status:       utils.Active,
+		CreatedAt:    createdAt,
 	}
 
-	// Lock mutex to ensure thread-safe operations
-	service.Storage.Mutex.Lock()
-	defer service.Storage.Mutex.Unlock()
+	err = service.Storage.Lock([]uuid.UUID{newUUID, customerID}, func(store storage.Storage) error {
+		// Link the account to its customer, named after them by default
+		if err := linkCustomer(store, &account, customerID, request.Owner); err != nil {
+			return err
+		}
+
+		// Add the new account to storage, held by its customer
+		if err := store.CreateAccount(account); err != nil {
+			return err
+		}
+		if err := holdByCustomer(store, account, uuid.Nil); err != nil {
+			return err
+		}
+
+		// Record the opening of the account with its balance and overdraft
+		if err := emit(store, account, models.Event{Type: utils.EventAccountOpened, Amount: initialBalance, TimeStamp: createdAt}); err != nil {
+			return err
+		}
+		if overdraft.IsPositive() {
+			if err := emit(store, account, models.Event{Type: utils.EventOverdraftChanged, Amount: overdraft, TimeStamp: createdAt}); err != nil {
+				return err
+			}
+		}
+		if initialBalance.IsZero() {
+			return nil
+		}
 
-	// Add the new account to storage
-	service.Storage.Accounts = append(service.Storage.Accounts, account)
+		// Post the initial balance against the external account
+		_, err := post(store, models.JournalEntry{
+			Description: "opening balance",
+			Postings: []models.Posting{
+				debit(models.ExternalAccountID, uuid.Nil, currency.Code, initialBalance),
+				credit(newUUID, uuid.Nil, currency.Code, initialBalance),
+			},
+			TimeStamp: createdAt,
+		})
+		return err
+	})
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	account.Balance = initialBalance
 	return account, nil
 }
 
@@ -47,26 +152,381 @@ func (service *AccountService) ReadOne(id string) (models.Account, error) {
 		return models.Account{}, utils.ErrInvalidUUID
 	}
 
-	// Lock mutex to ensure thread-safe operations
-	service.Storage.Mutex.Lock()
-	defer service.Storage.Mutex.Unlock()
+	// Find the account in storage, leaving out the funds of expired holds
+	var account models.Account
+	err = service.Storage.View([]uuid.UUID{parsedUUID}, func(store storage.Storage) error {
+		account, err = store.FindAccount(parsedUUID)
+		if err != nil {
+			return err
+		}
+		account, _, err = effectiveHolds(store, account, service.Clock.Now())
+		return err
+	})
+	if err != nil {
+		return models.Account{}, err
+	}
 
-	// Find the account index in storage
-	index, err := service.Storage.FindAccount(parsedUUID)
+	return account, nil
+}
+
+// Update changes the editable details of an account that is not closed.
+// Only an owner of the account, or its customer while it has no holders,
+// may link it to another customer
+func (service *AccountService) Update(id string, request requests.AccountUpdateRequest) (models.Account, error) {
+	// Convert string IDs to UUID type
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Account{}, utils.ErrInvalidUUID
+	}
+	customerID := uuid.Nil
+	if request.CustomerID != "" {
+		if customerID, err = uuid.Parse(request.CustomerID); err != nil {
+			return models.Account{}, utils.ErrInvalidUUID
+		}
+	}
+
+	// Lock the account together with its current and new customers
+	var account models.Account
+	owners := func(store storage.Storage) ([]uuid.UUID, error) {
+		account, err := store.FindAccount(parsedUUID)
+		return []uuid.UUID{parsedUUID, account.CustomerID, customerID}, err
+	}
+	err = lockResolved(service.Storage, parsedUUID, owners, func(store storage.Storage) error {
+		// Find the account in storage
+		account, err = store.FindAccount(parsedUUID)
+		if err != nil {
+			return err
+		}
+		if account.Status == utils.Closed {
+			return utils.ErrAccountClosed
+		}
+
+		// Apply the new details, keeping the current customer when none is given
+		previous := account.CustomerID
+		if customerID == uuid.Nil {
+			customerID = previous
+		}
+		if customerID != previous {
+			if err := authorize(store, account, request.ActingCustomerID, utils.HolderRole.CanManage); err != nil {
+				return err
+			}
+		}
+		account.Owner = request.Owner
+		if err := linkCustomer(store, &account, customerID, request.Owner); err != nil {
+			return err
+		}
+		if err := store.UpdateAccount(account); err != nil {
+			return err
+		}
+		return holdByCustomer(store, account, previous)
+	})
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return account, nil
+}
+
+// SetOverdraft changes the overdraft limit of a checking account that is not
+// closed. The limit cannot drop below what the account already overdrew
+func (service *AccountService) SetOverdraft(id string, request requests.OverdraftRequest) (models.Account, error) {
+	// Convert string ID to UUID type
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Account{}, utils.ErrInvalidUUID
+	}
+
+	var account models.Account
+	err = service.Storage.Lock([]uuid.UUID{parsedUUID}, func(store storage.Storage) error {
+		// Find the account in storage
+		account, err = store.FindAccount(parsedUUID)
+		if err != nil {
+			return err
+		}
+		if account.Status == utils.Closed {
+			return utils.ErrAccountClosed
+		}
+
+		// Parse the limit in the account currency
+		currency, err := money.LookupCurrency(account.Currency)
+		if err != nil {
+			return err
+		}
+		overdraft, err := parseOverdraft(request.Limit, currency, account.Type)
+		if err != nil {
+			return err
+		}
+		limit, err := account.Balance.Add(overdraft)
+		if err != nil {
+			return err
+		}
+		if limit.IsNegative() {
+			return utils.ErrOverdraftInUse
+		}
+
+		account.Overdraft = overdraft
+		if err := store.UpdateAccount(account); err != nil {
+			return err
+		}
+		return emit(store, account, models.Event{Type: utils.EventOverdraftChanged, Amount: overdraft, TimeStamp: service.Clock.Now()})
+	})
 	if err != nil {
 		return models.Account{}, err
 	}
 
-	// Return the found account
-	return service.Storage.Accounts[index], nil
+	return account, nil
+}
+
+// parseOverdraft converts an optional overdraft limit into minor units of
+// the currency. Only checking accounts may have a limit above zero
+func parseOverdraft(value money.Decimal, currency money.Currency, accountType utils.AccountType) (money.Money, error) {
+	if value == "" {
+		return currency.Zero(), nil
+	}
+	overdraft, err := currency.Parse(value)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if overdraft.IsNegative() {
+		return money.Money{}, utils.ErrInvalidAmount
+	}
+	if overdraft.IsPositive() && accountType != utils.Checking {
+		return money.Money{}, utils.ErrOverdraftNotAllowed
+	}
+	return overdraft, nil
+}
+
+// parseInterest converts an optional annual interest rate and its day count
+// convention. Only savings accounts and term deposits may earn interest
+func parseInterest(rate money.Decimal, dayCount string, accountType utils.AccountType) (money.Rate, utils.DayCount, error) {
+	convention := utils.Actual365
+	if dayCount != "" {
+		var err error
+		if convention, err = utils.ParseDayCount(dayCount); err != nil {
+			return money.Rate{}, utils.Actual365, utils.ErrInvalidDayCount
+		}
+	}
+	if rate == "" {
+		return money.Rate{}, convention, nil
+	}
+
+	interestRate, err := money.ParseRate(string(rate))
+	if err != nil {
+		return money.Rate{}, utils.Actual365, utils.ErrInvalidInterestRate
+	}
+	if accountType == utils.Checking {
+		return money.Rate{}, utils.Actual365, utils.ErrInterestNotAllowed
+	}
+	return interestRate, convention, nil
+}
+
+// Freeze stops an active account from sending money. It can still receive
+func (service *AccountService) Freeze(id string) (models.Account, error) {
+	return service.transition(id, utils.Frozen, utils.Active)
+}
+
+// Unfreeze lets a frozen account send money again
+func (service *AccountService) Unfreeze(id string) (models.Account, error) {
+	return service.transition(id, utils.Active, utils.Frozen)
+}
+
+// Close stops an account with a zero balance and no pending holds from
+// sending or receiving money
+func (service *AccountService) Close(id string) (models.Account, error) {
+	return service.transition(id, utils.Closed, utils.Active, utils.Frozen)
+}
+
+// Reopen makes a closed account active again
+func (service *AccountService) Reopen(id string) (models.Account, error) {
+	return service.transition(id, utils.Active, utils.Closed)
+}
+
+// transition moves an account into the status to when its current status is
+// one of from. Only accounts with a zero balance and no pending holds can be
+// closed, as a capture would otherwise debit a closed account
+func (service *AccountService) transition(id string, to utils.AccountStatus, from ...utils.AccountStatus) (models.Account, error) {
+	// Convert string ID to UUID type
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Account{}, utils.ErrInvalidUUID
+	}
+
+	var account models.Account
+	err = service.Storage.Lock([]uuid.UUID{parsedUUID}, func(store storage.Storage) error {
+		// Find the account in storage
+		account, err = store.FindAccount(parsedUUID)
+		if err != nil {
+			return err
+		}
+
+		// Check the transition is allowed from the current status
+		if !slices.Contains(from, account.Status) {
+			return utils.ErrInvalidStatusTransition
+		}
+		if to == utils.Closed {
+			if !account.Balance.IsZero() {
+				return utils.ErrAccountNotEmpty
+			}
+
+			// Holds past their expiry no longer keep the account open
+			if account, err = expireHolds(store, account, service.Clock.Now()); err != nil {
+				return err
+			}
+			if !account.Held.IsZero() {
+				return utils.ErrAccountHasHolds
+			}
+		}
+
+		account.Status = to
+		if err := store.UpdateAccount(account); err != nil {
+			return err
+		}
+		return emit(store, account, models.Event{Type: utils.EventAccountStatusChanged, TimeStamp: service.Clock.Now()})
+	})
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return account, nil
+}
+
+// linkCustomer makes the customer with the given ID hold the account and
+// names the account after them unless an owner is given. uuid.Nil leaves the
+// account without a customer
+func linkCustomer(store storage.Storage, account *models.Account, customerID uuid.UUID, owner string) error {
+	account.CustomerID = customerID
+	if customerID == uuid.Nil {
+		return nil
+	}
+
+	// Find the customer in storage
+	customer, err := store.FindCustomer(customerID)
+	if err != nil {
+		return err
+	}
+	if owner == "" {
+		account.Owner = customer.Name
+	}
+	return nil
+}
+
+// holdByCustomer makes the customer of an account one of its owners when it
+// changed from the previous customer, who stops holding the account
+func holdByCustomer(store storage.Storage, account models.Account, previous uuid.UUID) error {
+	if account.CustomerID == previous {
+		return nil
+	}
+	if previous != uuid.Nil {
+		err := store.DeleteAccountHolder(account.ID, previous)
+		if err != nil && err != utils.ErrHolderNotFound {
+			return err
+		}
+	}
+	if account.CustomerID == uuid.Nil {
+		return nil
+	}
+	return store.SaveAccountHolder(models.AccountHolder{AccountID: account.ID, CustomerID: account.CustomerID, Role: utils.Owner})
+}
+
+// Search retrieves one page of the accounts matching the query, sorted by
+// creation time or balance
+func (service *AccountService) Search(query requests.AccountQuery) (models.AccountPage, error) {
+	filter := models.AccountFilter{
+		Owner:      query.Owner,
+		SortBy:     models.AccountSortCreated,
+		Descending: query.Sort == "desc",
+		Limit:      pageLimit(query.Limit),
+	}
+	if query.SortBy == models.AccountSortBalance {
+		filter.SortBy = models.AccountSortBalance
+	}
+
+	// Balances in different currencies do not compare, so a balance range
+	// or order only applies within the currency of the query
+	var currency money.Currency
+	var err error
+	if query.Currency != "" {
+		if currency, err = money.LookupCurrency(query.Currency); err != nil {
+			return models.AccountPage{}, err
+		}
+		filter.Currency = currency.Code
+	} else if query.MinBalance != "" || query.MaxBalance != "" || filter.SortBy == models.AccountSortBalance {
+		return models.AccountPage{}, utils.ErrCurrencyRequired
+	}
+
+	// Parse the cursor and the balance range
+	if filter.After, err = decodeCursor(query.Cursor); err != nil {
+		return models.AccountPage{}, err
+	}
+	if filter.MinBalance, err = parseBalanceBound(query.MinBalance, currency); err != nil {
+		return models.AccountPage{}, err
+	}
+	if filter.MaxBalance, err = parseBalanceBound(query.MaxBalance, currency); err != nil {
+		return models.AccountPage{}, err
+	}
+
+	// Parse the comma separated statuses
+	if query.Status != "" {
+		for _, value := range strings.Split(query.Status, ",") {
+			status, err := utils.ParseAccountStatus(strings.TrimSpace(value))
+			if err != nil {
+				return models.AccountPage{}, utils.ErrInvalidQuery
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	// Fetch one account more than the page to learn if another follows,
+	// leaving out the funds of expired holds
+	limit := filter.Limit
+	filter.Limit++
+	var accounts []models.Account
+	err = service.Storage.View(nil, func(store storage.Storage) error {
+		accounts, err = store.SearchAccounts(filter)
+		if err != nil {
+			return err
+		}
+		return effectiveAccounts(store, accounts, service.Clock.Now())
+	})
+	if err != nil {
+		return models.AccountPage{}, err
+	}
+
+	page := models.AccountPage{Accounts: accounts}
+	if len(accounts) > limit {
+		page.Accounts = accounts[:limit]
+		last := page.Accounts[limit-1]
+		page.NextCursor = encodeCursor(models.Cursor{Value: filter.SortValue(last), ID: last.ID})
+	}
+	return page, nil
+}
+
+// parseBalanceBound converts an optional balance bound of a query into
+// minor units of the currency of the query
+func parseBalanceBound(value money.Decimal, currency money.Currency) (*money.Money, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := currency.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
 }
 
 // ReadAll retrieves all accounts from storage
 func (service *AccountService) ReadAll() ([]models.Account, error) {
-	// Lock mutex to ensure thread-safe operations
-	service.Storage.Mutex.Lock()
-	defer service.Storage.Mutex.Unlock()
-
-	// Return all accounts from storage
-	return service.Storage.Accounts, nil
+	// Return all accounts from storage, leaving out the funds of expired holds
+	var accounts []models.Account
+	err := service.Storage.View(nil, func(store storage.Storage) (err error) {
+		accounts, err = store.FindAccounts()
+		if err != nil {
+			return err
+		}
+		return effectiveAccounts(store, accounts, service.Clock.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
 }
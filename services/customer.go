@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type CustomerService struct {
+	Storage  storage.Storage
+	Exchange *ExchangeService // Rates used to total the net worth in one currency
+	Clock    utils.Clock      // Source of creation dates and hold expiry
+}
+
+// CreateCustomerService initializes a new CustomerService with the provided
+// storage and an empty exchange rate table
+func CreateCustomerService(storage storage.Storage) *CustomerService {
+	return &CustomerService{
+		Storage:  storage,
+		Exchange: CreateExchangeService(),
+		Clock:    utils.SystemClock{},
+	}
+}
+
+// Create adds a new customer based on the provided request
+func (service *CustomerService) Create(request requests.CustomerRequest) (models.Customer, error) {
+	// Create a new Customer instance with the request data
+	customer := models.Customer{
+		ID:        uuid.New(),
+		Name:      request.Name,
+		Email:     request.Email,
+		CreatedAt: service.Clock.Now(),
+	}
+
+	// Add the new customer to storage
+	if err := service.Storage.CreateCustomer(customer); err != nil {
+		return models.Customer{}, err
+	}
+	return customer, nil
+}
+
+// ReadOne retrieves a single customer by its ID
+func (service *CustomerService) ReadOne(id string) (models.Customer, error) {
+	// Convert string ID to UUID type
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Customer{}, utils.ErrInvalidUUID
+	}
+
+	// Find the customer in storage
+	return service.Storage.FindCustomer(parsedUUID)
+}
+
+// ReadAll retrieves all customers from storage
+func (service *CustomerService) ReadAll() ([]models.Customer, error) {
+	return service.Storage.FindCustomers()
+}
+
+// Update changes the details of a customer
+func (service *CustomerService) Update(id string, request requests.CustomerRequest) (models.Customer, error) {
+	// Convert string ID to UUID type
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Customer{}, utils.ErrInvalidUUID
+	}
+
+	var customer models.Customer
+	err = service.Storage.Lock([]uuid.UUID{parsedUUID}, func(store storage.Storage) error {
+		// Find the customer in storage
+		customer, err = store.FindCustomer(parsedUUID)
+		if err != nil {
+			return err
+		}
+
+		// Apply the new details
+		customer.Name = request.Name
+		customer.Email = request.Email
+		return store.UpdateCustomer(customer)
+	})
+	if err != nil {
+		return models.Customer{}, err
+	}
+
+	return customer, nil
+}
+
+// Delete removes a customer that no longer holds any account
+func (service *CustomerService) Delete(id string) error {
+	// Convert string ID to UUID type
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return utils.ErrInvalidUUID
+	}
+
+	return service.Storage.Lock([]uuid.UUID{parsedUUID}, func(store storage.Storage) error {
+		// Refuse to orphan the accounts of the customer, including joint ones
+		accounts, err := store.FindCustomerAccounts(parsedUUID)
+		if err != nil {
+			return err
+		}
+		holdings, err := store.FindCustomerHoldings(parsedUUID)
+		if err != nil {
+			return err
+		}
+		if len(accounts) > 0 || len(holdings) > 0 {
+			return utils.ErrCustomerHasAccounts
+		}
+
+		return store.DeleteCustomer(parsedUUID)
+	})
+}
+
+// ReadAccounts retrieves the accounts held by a customer, leaving out the
+// funds of expired holds
+func (service *CustomerService) ReadAccounts(id string) ([]models.Account, error) {
+	// Convert string ID to UUID type
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, utils.ErrInvalidUUID
+	}
+
+	// Read the balances of every account at the same point in time, in a
+	// view of the customer and its accounts
+	var accounts []models.Account
+	err = viewResolved(service.Storage, []uuid.UUID{parsedUUID}, func(store storage.Storage) ([]uuid.UUID, error) {
+		// Make sure the customer exists so an unknown ID is not an empty list
+		if _, err := store.FindCustomer(parsedUUID); err != nil {
+			return nil, err
+		}
+
+		accounts, err = store.FindCustomerAccounts(parsedUUID)
+		if err != nil {
+			return nil, err
+		}
+		ids := []uuid.UUID{parsedUUID}
+		for _, account := range accounts {
+			ids = append(ids, account.ID)
+		}
+		return ids, effectiveAccounts(store, accounts, service.Clock.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// NetWorth sums the balances of every account of a customer per currency.
+// When a currency is given the sums are also converted into it and totalled
+func (service *CustomerService) NetWorth(id string, currencyCode string) (models.NetWorth, error) {
+	// Convert string ID to UUID type
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return models.NetWorth{}, utils.ErrInvalidUUID
+	}
+
+	// Resolve the currency of the total, if one is requested
+	var target money.Currency
+	if currencyCode != "" {
+		if target, err = money.LookupCurrency(currencyCode); err != nil {
+			return models.NetWorth{}, err
+		}
+	}
+
+	accounts, err := service.ReadAccounts(id)
+	if err != nil {
+		return models.NetWorth{}, err
+	}
+
+	// Sum the balances per currency
+	sums := map[string]money.Money{}
+	for _, account := range accounts {
+		if sum, ok := sums[account.Currency]; ok {
+			if sums[account.Currency], err = sum.Add(account.Balance); err != nil {
+				return models.NetWorth{}, err
+			}
+		} else {
+			sums[account.Currency] = account.Balance
+		}
+	}
+	netWorth := models.NetWorth{CustomerID: parsedUUID, Balances: []models.CurrencyBalance{}}
+	for currency, amount := range sums {
+		netWorth.Balances = append(netWorth.Balances, models.CurrencyBalance{Currency: currency, Amount: amount})
+	}
+	sort.Slice(netWorth.Balances, func(i, j int) bool {
+		return netWorth.Balances[i].Currency < netWorth.Balances[j].Currency
+	})
+	if currencyCode == "" {
+		return netWorth, nil
+	}
+
+	// Convert every sum into the requested currency and total them
+	total := target.Zero()
+	for _, balance := range netWorth.Balances {
+		amount := balance.Amount
+		if balance.Currency != target.Code {
+			rate, err := service.Exchange.Find(balance.Currency, target.Code)
+			if err != nil {
+				return models.NetWorth{}, err
+			}
+			if amount, err = rate.Rate.Convert(amount, target.Scale); err != nil {
+				return models.NetWorth{}, err
+			}
+		}
+		if total, err = total.Add(amount); err != nil {
+			return models.NetWorth{}, err
+		}
+	}
+	netWorth.Currency = target.Code
+	netWorth.Total = &total
+	return netWorth, nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// replayBatch is how many events are read from the store at a time while
+// replaying it
+const replayBatch = 500
+
+// checkpointEvery is how many events of an account are appended between two
+// checkpoints of its balance, which bounds the events read to project it
+const checkpointEvery = 100
+
+// Projection is a read model built by applying events in the order they
+// were appended. A projection can always be rebuilt from an empty state by
+// replaying the whole event store
+type Projection interface {
+	Apply(event models.Event) error
+}
+
+// BalanceProjection derives the balance, held funds, overdraft and status of
+// accounts from their events
+type BalanceProjection struct {
+	Balances map[uuid.UUID]models.AccountBalance
+	order    []uuid.UUID // Accounts in the order they were opened
+}
+
+// CreateBalanceProjection initializes an empty BalanceProjection
+func CreateBalanceProjection() *BalanceProjection {
+	return &BalanceProjection{
+		Balances: map[uuid.UUID]models.AccountBalance{},
+	}
+}
+
+// Apply folds the next event into the balance of its account. Transfers
+// change no balance themselves, their legs do. It fails with
+// ErrInvalidAmount should a balance overflow
+func (projection *BalanceProjection) Apply(event models.Event) error {
+	balance, ok := projection.Balances[event.AccountID]
+	if event.Type == utils.EventAccountOpened {
+		projection.order = append(projection.order, event.AccountID)
+		balance = models.AccountBalance{
+			AccountID: event.AccountID,
+			Currency:  event.Currency,
+			Balance:   event.Amount,
+			Held:      money.Zero(event.Amount.Scale),
+			Overdraft: money.Zero(event.Amount.Scale),
+		}
+	} else if !ok {
+		return nil
+	}
+
+	var err error
+	switch event.Type {
+	case utils.EventFundsDeposited:
+		balance.Balance, err = balance.Balance.Add(event.Amount)
+	case utils.EventFundsWithdrawn:
+		balance.Balance, err = balance.Balance.Sub(event.Amount)
+	case utils.EventFundsHeld:
+		balance.Held, err = balance.Held.Add(event.Amount)
+	case utils.EventFundsReleased:
+		balance.Held, err = balance.Held.Sub(event.Amount)
+	case utils.EventOverdraftChanged:
+		balance.Overdraft = event.Amount
+	}
+	if err != nil {
+		return err
+	}
+	balance.Status = event.Status
+	balance.Version = event.Sequence
+	// Events can be dated before the ones appended earlier, such as interest
+	// paid for a past day
+	if event.TimeStamp.After(balance.UpdatedAt) {
+		balance.UpdatedAt = event.TimeStamp
+	}
+	projection.Balances[event.AccountID] = balance
+	return nil
+}
+
+// All returns the balances of every account in the order they were opened
+func (projection *BalanceProjection) All() []models.AccountBalance {
+	balances := []models.AccountBalance{}
+	for _, accountID := range projection.order {
+		balances = append(balances, projection.Balances[accountID])
+	}
+	return balances
+}
+
+type EventService struct {
+	Storage storage.Storage
+}
+
+// CreateEventService initializes a new EventService with the provided storage
+func CreateEventService(storage storage.Storage) *EventService {
+	return &EventService{
+		Storage: storage,
+	}
+}
+
+// ReadByAccount retrieves the events of an account, including the transfers
+// it received, in the order they happened
+func (service *EventService) ReadByAccount(accountId string) ([]models.Event, error) {
+	// Validate and parse the account UUID
+	parsedAccountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return []models.Event{}, utils.ErrInvalidUUID
+	}
+
+	var events []models.Event
+	err = service.Storage.View([]uuid.UUID{parsedAccountUUID}, func(store storage.Storage) error {
+		// Check the account exists so unknown IDs are told apart from
+		// accounts without events
+		if _, err := store.FindAccount(parsedAccountUUID); err != nil {
+			return err
+		}
+
+		events, err = store.FindAccountEvents(models.EventFilter{AccountID: parsedAccountUUID})
+		return err
+	})
+	if err != nil {
+		return []models.Event{}, err
+	}
+
+	return events, nil
+}
+
+// Balance projects the balance of an account from its events alone, as it
+// stood at the time of the query or now when the query has none
+func (service *EventService) Balance(accountId string, query requests.BalanceQuery) (models.AccountBalance, error) {
+	// Validate and parse the account UUID and the time
+	parsedAccountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return models.AccountBalance{}, utils.ErrInvalidUUID
+	}
+	asOf, err := parseTime(query.AsOf)
+	if err != nil {
+		return models.AccountBalance{}, err
+	}
+
+	var balance models.AccountBalance
+	err = service.Storage.View([]uuid.UUID{parsedAccountUUID}, func(store storage.Storage) error {
+		// Check the account exists so unknown IDs are told apart from
+		// accounts opened later
+		if _, err := store.FindAccount(parsedAccountUUID); err != nil {
+			return err
+		}
+
+		balance, _, err = project(store, parsedAccountUUID, asOf)
+		return err
+	})
+	if err != nil {
+		return models.AccountBalance{}, err
+	}
+	return balance, nil
+}
+
+// Replay applies every event of the store to a projection in order, from
+// the first one. Events are only ever added after the last one, so reading
+// them batch by batch, each batch once the units of work of its accounts
+// committed, replays every event up to the last batch
+func (service *EventService) Replay(projection Projection) error {
+	// Read the events in batches so the store is never copied at once
+	after := int64(0)
+	for {
+		events, err := service.Storage.FindEvents(after, replayBatch)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := projection.Apply(event); err != nil {
+				return err
+			}
+			after = event.Sequence
+		}
+		if len(events) < replayBatch {
+			return nil
+		}
+	}
+}
+
+// RebuildBalances replays the whole event store into a new balance
+// projection and returns the balances of every account in the order they
+// were opened
+func (service *EventService) RebuildBalances() ([]models.AccountBalance, error) {
+	projection := CreateBalanceProjection()
+	if err := service.Replay(projection); err != nil {
+		return []models.AccountBalance{}, err
+	}
+	return projection.All(), nil
+}
+
+// project folds the events of an account that happened no later than asOf,
+// or all of them when asOf is zero, into its balance. It starts from the
+// latest checkpoint taken before asOf and returns how many events it read on
+// top of it, or ErrNotOpenAsOf when the account was not open by then
+func project(store storage.Storage, accountID uuid.UUID, asOf time.Time) (models.AccountBalance, int, error) {
+	// Start from the latest checkpoint, if any
+	projection := CreateBalanceProjection()
+	checkpoint, err := store.FindBalanceCheckpoint(accountID, asOf)
+	if err == nil {
+		projection.Balances[accountID] = checkpoint
+	} else if err != utils.ErrCheckpointNotFound {
+		return models.AccountBalance{}, 0, err
+	}
+
+	// Apply the events appended after it. Every event folded into the
+	// checkpoint happened no later than asOf, so none is counted twice
+	events, err := store.FindAccountEvents(models.EventFilter{AccountID: accountID, After: checkpoint.Version, Until: asOf})
+	if err != nil {
+		return models.AccountBalance{}, 0, err
+	}
+	for _, event := range events {
+		if err := projection.Apply(event); err != nil {
+			return models.AccountBalance{}, 0, err
+		}
+	}
+
+	balance, ok := projection.Balances[accountID]
+	if !ok {
+		return models.AccountBalance{}, len(events), utils.ErrNotOpenAsOf
+	}
+	return balance, len(events), nil
+}
+
+// checkpoint saves the balance of an account once enough events were
+// appended since its last checkpoint. It must be called inside a unit of work
+func checkpoint(store storage.Storage, accountID uuid.UUID) error {
+	balance, read, err := project(store, accountID, time.Time{})
+	if err == utils.ErrNotOpenAsOf {
+		// Accounts opened before events were recorded have no opening event
+		return nil
+	}
+	if err != nil || read < checkpointEvery {
+		return err
+	}
+	return store.SaveBalanceCheckpoint(balance)
+}
+
+// emit appends an event about an account to the event store, stamped with
+// the account currency and the status the account is left in, and
+// checkpoints the balances it changes. It must be called inside a unit of
+// work
+func emit(store storage.Storage, account models.Account, event models.Event) error {
+	event.AccountID = account.ID
+	event.Currency = account.Currency
+	event.Status = account.Status
+	if err := store.AppendEvent(event); err != nil {
+		return err
+	}
+	if err := checkpoint(store, event.AccountID); err != nil {
+		return err
+	}
+	if event.CounterpartyID != uuid.Nil {
+		return checkpoint(store, event.CounterpartyID)
+	}
+	return nil
+}
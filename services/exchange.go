@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/utils"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExchangeService keeps the table of exchange rates used to convert
+// transfers between accounts in different currencies
+type ExchangeService struct {
+	Path  string                         // File the table is loaded from and saved to
+	rates map[string]models.ExchangeRate // Rates keyed by "FROM/TO" currency pair
+	mutex *sync.RWMutex
+}
+
+// CreateExchangeService initializes an ExchangeService with an empty table
+func CreateExchangeService() *ExchangeService {
+	return &ExchangeService{
+		rates: map[string]models.ExchangeRate{},
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// pairKey identifies a currency pair in the rate table
+func pairKey(from string, to string) string {
+	return from + "/" + to
+}
+
+// Load replaces the table with the rates stored in the JSON file at path and
+// remembers the path for later updates. A missing file leaves the table empty
+func (service *ExchangeService) Load(path string) error {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	service.Path = path
+
+	// Read the file, treating a missing file as an empty table
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Decode and validate every rate before replacing the table
+	var stored []requests.ExchangeRateRequest
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	rates, err := parseRates(stored, time.Now())
+	if err != nil {
+		return err
+	}
+
+	service.rates = map[string]models.ExchangeRate{}
+	for _, rate := range rates {
+		service.rates[pairKey(rate.From, rate.To)] = rate
+	}
+	return nil
+}
+
+// ReadAll returns every rate in the table sorted by currency pair
+func (service *ExchangeService) ReadAll() ([]models.ExchangeRate, error) {
+	service.mutex.RLock()
+	defer service.mutex.RUnlock()
+	return service.sortedRates(), nil
+}
+
+// Update adds or replaces the given rates and saves the table when it was
+// loaded from a file. Either every rate is applied or none is
+func (service *ExchangeService) Update(request requests.ExchangeRatesRequest) ([]models.ExchangeRate, error) {
+	// Validate every rate before touching the table
+	rates, err := parseRates(request.Rates, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	// Apply the rates to a copy so a failed save leaves the table unchanged
+	updated := map[string]models.ExchangeRate{}
+	for key, rate := range service.rates {
+		updated[key] = rate
+	}
+	for _, rate := range rates {
+		updated[pairKey(rate.From, rate.To)] = rate
+	}
+
+	previous := service.rates
+	service.rates = updated
+	if service.Path != "" {
+		if err := service.save(); err != nil {
+			service.rates = previous
+			return nil, err
+		}
+	}
+	return service.sortedRates(), nil
+}
+
+// Find returns the rate converting from one currency into another. A pair
+// without a direct rate uses the inverse of the opposite pair
+func (service *ExchangeService) Find(from string, to string) (models.ExchangeRate, error) {
+	// Amounts in the same currency convert one to one
+	if from == to {
+		return models.ExchangeRate{From: from, To: to, Rate: money.Rate{Units: 1}}, nil
+	}
+
+	service.mutex.RLock()
+	defer service.mutex.RUnlock()
+
+	if rate, ok := service.rates[pairKey(from, to)]; ok {
+		return rate, nil
+	}
+	if rate, ok := service.rates[pairKey(to, from)]; ok {
+		inverse, err := rate.Rate.Inverse()
+		if err != nil {
+			return models.ExchangeRate{}, err
+		}
+		return models.ExchangeRate{From: from, To: to, Rate: inverse, UpdatedAt: rate.UpdatedAt}, nil
+	}
+	return models.ExchangeRate{}, utils.ErrRateNotFound
+}
+
+// sortedRates lists the table sorted by currency pair. The caller must
+// hold the mutex
+func (service *ExchangeService) sortedRates() []models.ExchangeRate {
+	rates := []models.ExchangeRate{}
+	for _, rate := range service.rates {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return pairKey(rates[i].From, rates[i].To) < pairKey(rates[j].From, rates[j].To)
+	})
+	return rates
+}
+
+// save writes the table to its file through a temporary file so that a
+// crash never leaves a partially written table. The caller must hold the mutex
+func (service *ExchangeService) save() error {
+	stored := []requests.ExchangeRateRequest{}
+	for _, rate := range service.sortedRates() {
+		stored = append(stored, requests.ExchangeRateRequest{
+			From: rate.From,
+			To:   rate.To,
+			Rate: money.Decimal(rate.Rate.String()),
+		})
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temporary, err := os.CreateTemp(filepath.Dir(service.Path), ".exchange-rates-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary.Name(), service.Path)
+}
+
+// parseRates validates requested rates and converts them into models
+func parseRates(requested []requests.ExchangeRateRequest, updatedAt time.Time) ([]models.ExchangeRate, error) {
+	rates := []models.ExchangeRate{}
+	for _, request := range requested {
+		from, err := money.LookupCurrency(request.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := money.LookupCurrency(request.To)
+		if err != nil {
+			return nil, err
+		}
+		if from == to {
+			return nil, utils.ErrInvalidRate
+		}
+		rate, err := money.ParseRate(string(request.Rate))
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, models.ExchangeRate{
+			From:      from.Code,
+			To:        to.Code,
+			Rate:      rate,
+			UpdatedAt: updatedAt,
+		})
+	}
+	return rates, nil
+}
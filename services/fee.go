@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/utils"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FeeService keeps the fee schedule pricing deposits, withdrawals and
+// transfers per account type
+type FeeService struct {
+	Path  string           // File the schedule is loaded from and saved to
+	rules []models.FeeRule // Rules in the order they were given
+	mutex *sync.RWMutex
+}
+
+// CreateFeeService initializes a FeeService with an empty schedule, which
+// charges nothing
+func CreateFeeService() *FeeService {
+	return &FeeService{
+		rules: []models.FeeRule{},
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// Load replaces the schedule with the rules stored in the JSON file at path
+// and remembers the path for later updates. A missing file leaves the
+// schedule empty
+func (service *FeeService) Load(path string) error {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	service.Path = path
+
+	// Read the file, treating a missing file as an empty schedule
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Decode and validate every rule before replacing the schedule
+	var stored []requests.FeeRuleRequest
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	rules, err := parseFeeRules(stored)
+	if err != nil {
+		return err
+	}
+
+	service.rules = rules
+	return nil
+}
+
+// ReadAll returns a copy of every rule of the schedule
+func (service *FeeService) ReadAll() ([]models.FeeRule, error) {
+	service.mutex.RLock()
+	defer service.mutex.RUnlock()
+	return copyFeeRules(service.rules), nil
+}
+
+// Update replaces the schedule and saves it when it was loaded from a file.
+// Either every rule is applied or none is
+func (service *FeeService) Update(request requests.FeeScheduleRequest) ([]models.FeeRule, error) {
+	// Validate every rule before touching the schedule
+	rules, err := parseFeeRules(request.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	previous := service.rules
+	service.rules = rules
+	if service.Path != "" {
+		if err := service.save(); err != nil {
+			service.rules = previous
+			return nil, err
+		}
+	}
+	return copyFeeRules(service.rules), nil
+}
+
+// Quote returns the fee the schedule charges an account for an operation
+// on an amount, rounded to the account currency. A rule for the account type
+// takes precedence over a rule for every type. The fee is zero when no rule
+// applies
+func (service *FeeService) Quote(operation string, account models.Account, amount money.Money) (money.Money, error) {
+	currency, err := money.LookupCurrency(account.Currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	service.mutex.RLock()
+	defer service.mutex.RUnlock()
+
+	// Find the most specific rule for the operation
+	var rule *models.FeeRule
+	for index, candidate := range service.rules {
+		if candidate.Operation != operation {
+			continue
+		}
+		if candidate.AccountType == nil && rule == nil {
+			rule = &service.rules[index]
+		} else if candidate.AccountType != nil && *candidate.AccountType == account.Type {
+			rule = &service.rules[index]
+			break
+		}
+	}
+	if rule == nil {
+		return currency.Zero(), nil
+	}
+
+	fee, err := rule.Fee(amount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return money.Rate{Units: 1}.Convert(fee, currency.Scale)
+}
+
+// save writes the schedule to its file through a temporary file so that a
+// crash never leaves a partially written schedule. The caller must hold the
+// mutex
+func (service *FeeService) save() error {
+	stored := []requests.FeeRuleRequest{}
+	for _, rule := range service.rules {
+		stored = append(stored, feeRuleRequest(rule))
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temporary, err := os.CreateTemp(filepath.Dir(service.Path), ".fees-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary.Name(), service.Path)
+}
+
+// copyFeeRules copies rules down to their tiers and bounds, so callers
+// cannot change the schedule through what they are given
+func copyFeeRules(rules []models.FeeRule) []models.FeeRule {
+	copied := make([]models.FeeRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.AccountType != nil {
+			accountType := *rule.AccountType
+			rule.AccountType = &accountType
+		}
+		if rule.Maximum != nil {
+			maximum := *rule.Maximum
+			rule.Maximum = &maximum
+		}
+		tiers := make([]models.FeeTier, 0, len(rule.Tiers))
+		for _, tier := range rule.Tiers {
+			if tier.UpTo != nil {
+				upTo := *tier.UpTo
+				tier.UpTo = &upTo
+			}
+			tiers = append(tiers, tier)
+		}
+		rule.Tiers = tiers
+		copied = append(copied, rule)
+	}
+	return copied
+}
+
+// parseFeeRules validates requested fee rules and converts them into models.
+// Each operation may have one rule per account type and one for every type
+func parseFeeRules(requested []requests.FeeRuleRequest) ([]models.FeeRule, error) {
+	rules := []models.FeeRule{}
+	seen := map[string]bool{}
+	for _, request := range requested {
+		if err := request.Validate(); err != nil {
+			return nil, utils.ErrInvalidFeeRule
+		}
+		if seen[request.Operation+"/"+request.AccountType] {
+			return nil, utils.ErrInvalidFeeRule
+		}
+		seen[request.Operation+"/"+request.AccountType] = true
+
+		rule := models.FeeRule{Operation: request.Operation}
+		if request.AccountType != "" {
+			accountType, err := utils.ParseAccountType(request.AccountType)
+			if err != nil {
+				return nil, utils.ErrInvalidFeeRule
+			}
+			rule.AccountType = &accountType
+		}
+
+		// A rule without tiers prices every amount alike
+		tiers := request.Tiers
+		if len(tiers) == 0 {
+			tiers = []requests.FeeTierRequest{{Flat: request.Flat, Rate: request.Rate}}
+		} else if request.Flat != "" || request.Rate != "" {
+			return nil, utils.ErrInvalidFeeRule
+		}
+		for index, tierRequest := range tiers {
+			tier, err := parseFeeTier(tierRequest)
+			if err != nil {
+				return nil, err
+			}
+
+			// Bounds must rise and only the last tier may be unbounded
+			last := index == len(tiers)-1
+			if (tier.UpTo == nil) != last {
+				return nil, utils.ErrInvalidFeeRule
+			}
+			if index > 0 && tier.UpTo != nil && tier.UpTo.Cmp(*rule.Tiers[index-1].UpTo) <= 0 {
+				return nil, utils.ErrInvalidFeeRule
+			}
+			rule.Tiers = append(rule.Tiers, tier)
+		}
+
+		// Keep the fee between its optional bounds
+		var err error
+		if rule.Minimum, err = parseFeeAmount(request.Minimum); err != nil {
+			return nil, err
+		}
+		if request.Maximum != "" {
+			maximum, err := parseFeeAmount(request.Maximum)
+			if err != nil || maximum.Cmp(rule.Minimum) < 0 {
+				return nil, utils.ErrInvalidFeeRule
+			}
+			rule.Maximum = &maximum
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseFeeTier converts a requested tier
+func parseFeeTier(request requests.FeeTierRequest) (models.FeeTier, error) {
+	var tier models.FeeTier
+	var err error
+	if request.UpTo != "" {
+		upTo, err := parseFeeAmount(request.UpTo)
+		if err != nil || !upTo.IsPositive() {
+			return models.FeeTier{}, utils.ErrInvalidFeeRule
+		}
+		tier.UpTo = &upTo
+	}
+	if tier.Flat, err = parseFeeAmount(request.Flat); err != nil {
+		return models.FeeTier{}, err
+	}
+	if request.Rate != "" {
+		if tier.Rate, err = money.ParseRate(string(request.Rate)); err != nil {
+			return models.FeeTier{}, utils.ErrInvalidFeeRule
+		}
+	}
+	return tier, nil
+}
+
+// parseFeeAmount converts an optional amount of a fee rule, allowing as many
+// decimal places as the most precise currency. Empty amounts are zero
+func parseFeeAmount(value money.Decimal) (money.Money, error) {
+	if value == "" {
+		return money.Zero(money.MaxCurrencyScale), nil
+	}
+	amount, err := value.Parse(money.MaxCurrencyScale)
+	if err != nil || amount.IsNegative() {
+		return money.Money{}, utils.ErrInvalidFeeRule
+	}
+	return amount, nil
+}
+
+// feeRuleRequest converts a fee rule back into its stored form
+func feeRuleRequest(rule models.FeeRule) requests.FeeRuleRequest {
+	request := requests.FeeRuleRequest{
+		Operation: rule.Operation,
+		Minimum:   feeDecimal(rule.Minimum),
+	}
+	if rule.AccountType != nil {
+		request.AccountType = rule.AccountType.String()
+	}
+	if rule.Maximum != nil {
+		request.Maximum = money.Decimal(rule.Maximum.String())
+	}
+
+	tiers := []requests.FeeTierRequest{}
+	for _, tier := range rule.Tiers {
+		tierRequest := requests.FeeTierRequest{Flat: feeDecimal(tier.Flat)}
+		if tier.UpTo != nil {
+			tierRequest.UpTo = money.Decimal(tier.UpTo.String())
+		}
+		if tier.Rate.Units != 0 {
+			tierRequest.Rate = money.Decimal(tier.Rate.String())
+		}
+		tiers = append(tiers, tierRequest)
+	}
+	if len(tiers) == 1 {
+		request.Flat, request.Rate = tiers[0].Flat, tiers[0].Rate
+	} else {
+		request.Tiers = tiers
+	}
+	return request
+}
+
+// feeDecimal formats an amount of a fee rule, leaving zero empty
+func feeDecimal(amount money.Money) money.Decimal {
+	if amount.IsZero() {
+		return ""
+	}
+	return money.Decimal(amount.String())
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlaceHold reserves an amount of the available balance of an account until
+// the hold is captured, released or expires
+func (service *TransactionService) PlaceHold(accountId string, request requests.HoldRequest) (models.Hold, error) {
+	// Validate and parse the account UUID
+	parsedAccountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return models.Hold{}, utils.ErrInvalidUUID
+	}
+
+	now := service.Clock.Now()
+	var hold models.Hold
+	err = service.Storage.Lock([]uuid.UUID{parsedAccountUUID}, func(store storage.Storage) error {
+		// Find the account to learn its currency
+		account, err := store.FindAccount(parsedAccountUUID)
+		if err != nil {
+			return err
+		}
+
+		// Reject holds in a currency other than the account's
+		if request.Currency != "" && !strings.EqualFold(request.Currency, account.Currency) {
+			return utils.ErrCurrencyMismatch
+		}
+
+		// Only holders allowed to debit the account may reserve its funds
+		if err := authorize(store, account, request.ActingCustomerID, utils.HolderRole.CanDebit); err != nil {
+			return err
+		}
+
+		// Validate and parse the amount in the account currency
+		amount, err := parseAmount(request.Amount, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		// Holds are withdrawals to come, so the account must accept one now
+		if account.Status == utils.Closed {
+			return utils.ErrAccountClosed
+		}
+		if account.Status == utils.Frozen {
+			return utils.ErrAccountFrozen
+		}
+		withdrawal := models.Transaction{AccountID: account.ID, Type: utils.Withdrawal, Amount: amount, TimeStamp: now}
+		if _, err := service.Rules.enforce(store, account, withdrawal); err != nil {
+			return err
+		}
+
+		// Free the funds of expired holds and reserve the amount
+		account, err = expireHolds(store, account, now)
+		if err != nil {
+			return err
+		}
+		available, err := account.Available()
+		if err != nil {
+			return err
+		}
+		if available.Cmp(amount) < 0 {
+			return utils.ErrInsufficientFunds
+		}
+		if account.Held, err = account.Held.Add(amount); err != nil {
+			return err
+		}
+		if err := store.UpdateAccount(account); err != nil {
+			return err
+		}
+
+		hold = models.Hold{
+			ID:        uuid.New(),
+			AccountID: account.ID,
+			Amount:    amount,
+			Captured:  money.Zero(amount.Scale),
+			Status:    utils.HoldPending,
+			CreatedAt: now,
+			ExpiresAt: now.Add(service.HoldTTL),
+		}
+		if err := store.CreateHold(hold); err != nil {
+			return err
+		}
+		return emit(store, account, models.Event{Type: utils.EventFundsHeld, ReferenceID: hold.ID, Amount: amount, TimeStamp: now})
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// ReadHolds retrieves the holds of an account in the order they were placed
+func (service *TransactionService) ReadHolds(accountId string) ([]models.Hold, error) {
+	// Validate and parse the account UUID
+	parsedAccountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return []models.Hold{}, utils.ErrInvalidUUID
+	}
+
+	var holds []models.Hold
+	err = service.Storage.View([]uuid.UUID{parsedAccountUUID}, func(store storage.Storage) error {
+		// Find the account and list its holds, overdue ones as expired
+		account, err := store.FindAccount(parsedAccountUUID)
+		if err != nil {
+			return err
+		}
+		_, holds, err = effectiveHolds(store, account, service.Clock.Now())
+		return err
+	})
+	if err != nil {
+		return []models.Hold{}, err
+	}
+
+	return holds, nil
+}
+
+// CaptureHold settles a pending hold as a withdrawal of the whole amount held
+// or of the requested part of it. The rest of the hold is freed
+func (service *TransactionService) CaptureHold(holdId string, request requests.CaptureRequest) (models.Hold, error) {
+	// Validate and parse the hold UUID
+	parsedHoldUUID, err := uuid.Parse(holdId)
+	if err != nil {
+		return models.Hold{}, utils.ErrInvalidUUID
+	}
+
+	now := service.Clock.Now()
+	var hold models.Hold
+	err = lockResolved(service.Storage, parsedHoldUUID, holdAccount(parsedHoldUUID), func(store storage.Storage) error {
+		// Find the hold and its account
+		hold, err = store.FindHold(parsedHoldUUID)
+		if err != nil {
+			return err
+		}
+		account, err := store.FindAccount(hold.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// Only holders allowed to debit the account may capture its holds
+		if err := authorize(store, account, request.ActingCustomerID, utils.HolderRole.CanDebit); err != nil {
+			return err
+		}
+
+		// Parse the amount to capture, the whole hold unless requested
+		amount := hold.Amount
+		if request.Amount != "" {
+			if amount, err = parseAmount(request.Amount, account.Currency); err != nil {
+				return err
+			}
+			if amount.Cmp(hold.Amount) > 0 {
+				return utils.ErrCaptureExceedsHold
+			}
+		}
+
+		// Free the hold and settle the captured amount as a withdrawal
+		if hold, account, err = finishHold(store, hold, account, utils.HoldCaptured, now); err != nil {
+			return err
+		}
+		transaction, err := service.settle(store, account, models.Transaction{
+			AccountID: account.ID,
+			Type:      utils.Withdrawal,
+			Amount:    amount,
+			TimeStamp: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		hold.Captured = amount
+		hold.TransactionID = transaction.ID
+		return store.UpdateHold(hold)
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// ReleaseHold frees the funds reserved by a pending hold without moving money
+func (service *TransactionService) ReleaseHold(holdId string, actor string) (models.Hold, error) {
+	// Validate and parse the hold UUID
+	parsedHoldUUID, err := uuid.Parse(holdId)
+	if err != nil {
+		return models.Hold{}, utils.ErrInvalidUUID
+	}
+
+	now := service.Clock.Now()
+	var hold models.Hold
+	err = lockResolved(service.Storage, parsedHoldUUID, holdAccount(parsedHoldUUID), func(store storage.Storage) error {
+		// Find the hold and its account
+		hold, err = store.FindHold(parsedHoldUUID)
+		if err != nil {
+			return err
+		}
+		account, err := store.FindAccount(hold.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// Only holders allowed to debit the account may release its holds
+		if err := authorize(store, account, actor, utils.HolderRole.CanDebit); err != nil {
+			return err
+		}
+
+		hold, _, err = finishHold(store, hold, account, utils.HoldReleased, now)
+		if err != nil {
+			return err
+		}
+		return store.UpdateHold(hold)
+	})
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// holdAccount resolves the account a hold reserves funds of, which units of
+// work on the hold lock
+func holdAccount(holdID uuid.UUID) func(store storage.Storage) ([]uuid.UUID, error) {
+	return func(store storage.Storage) ([]uuid.UUID, error) {
+		hold, err := store.FindHold(holdID)
+		return []uuid.UUID{hold.AccountID}, err
+	}
+}
+
+// finishHold moves a pending hold to the given status and frees its amount
+// from the account. Holds past their expiry can no longer be finished and
+// are left for expireHolds. It must be called inside a unit of work
+func finishHold(store storage.Storage, hold models.Hold, account models.Account, status utils.HoldStatus, now time.Time) (models.Hold, models.Account, error) {
+	if hold.Status != utils.HoldPending || !now.Before(hold.ExpiresAt) {
+		return models.Hold{}, models.Account{}, utils.ErrHoldNotPending
+	}
+
+	hold.Status = status
+	held, err := account.Held.Sub(hold.Amount)
+	if err != nil {
+		return models.Hold{}, models.Account{}, err
+	}
+	account.Held = held
+	if err := store.UpdateAccount(account); err != nil {
+		return models.Hold{}, models.Account{}, err
+	}
+	if err := emit(store, account, models.Event{Type: utils.EventFundsReleased, ReferenceID: hold.ID, Amount: hold.Amount, TimeStamp: now}); err != nil {
+		return models.Hold{}, models.Account{}, err
+	}
+	return hold, account, nil
+}
+
+// effectiveHolds returns an account and its holds as they stand at now,
+// showing pending holds past their expiry as expired and their amounts as
+// freed. Nothing is stored, expireHolds does that the next time the account
+// changes, so it only needs a view of the account
+func effectiveHolds(store storage.Storage, account models.Account, now time.Time) (models.Account, []models.Hold, error) {
+	holds, err := store.FindAccountHolds(account.ID)
+	if err != nil {
+		return models.Account{}, nil, err
+	}
+
+	for index, hold := range holds {
+		if hold.Status != utils.HoldPending || now.Before(hold.ExpiresAt) {
+			continue
+		}
+		holds[index].Status = utils.HoldExpired
+		if account.Held, err = account.Held.Sub(hold.Amount); err != nil {
+			return models.Account{}, nil, err
+		}
+	}
+	return account, holds, nil
+}
+
+// effectiveAccounts applies effectiveHolds to every account of a listing,
+// so its funds on hold match those of the accounts read one by one
+func effectiveAccounts(store storage.Storage, accounts []models.Account, now time.Time) error {
+	for index, account := range accounts {
+		if account.Held.IsZero() {
+			continue
+		}
+		effective, _, err := effectiveHolds(store, account, now)
+		if err != nil {
+			return err
+		}
+		accounts[index] = effective
+	}
+	return nil
+}
+
+// expireHolds marks the pending holds of an account whose expiry has passed
+// as expired and frees their amounts. It returns the updated account and must
+// be called inside a unit of work
+func expireHolds(store storage.Storage, account models.Account, now time.Time) (models.Account, error) {
+	if account.Held.IsZero() {
+		return account, nil
+	}
+
+	holds, err := store.FindAccountHolds(account.ID)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	expired := false
+	for _, hold := range holds {
+		if hold.Status != utils.HoldPending || now.Before(hold.ExpiresAt) {
+			continue
+		}
+		hold.Status = utils.HoldExpired
+		if err := store.UpdateHold(hold); err != nil {
+			return models.Account{}, err
+		}
+		if account.Held, err = account.Held.Sub(hold.Amount); err != nil {
+			return models.Account{}, err
+		}
+		if err := emit(store, account, models.Event{Type: utils.EventFundsReleased, ReferenceID: hold.ID, Amount: hold.Amount, TimeStamp: now}); err != nil {
+			return models.Account{}, err
+		}
+		expired = true
+	}
+
+	if expired {
+		if err := store.UpdateAccount(account); err != nil {
+			return models.Account{}, err
+		}
+	}
+	return account, nil
+}
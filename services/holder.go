@@ -0,0 +1,208 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/requests"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+
+	"github.com/google/uuid"
+)
+
+// HolderService manages the customers holding an account and their roles
+type HolderService struct {
+	Storage storage.Storage
+}
+
+// CreateHolderService initializes a new HolderService with the provided storage
+func CreateHolderService(storage storage.Storage) *HolderService {
+	return &HolderService{
+		Storage: storage,
+	}
+}
+
+// ReadAll retrieves the holders of an account
+func (service *HolderService) ReadAll(accountId string) ([]models.AccountHolder, error) {
+	// Validate and parse the account UUID
+	parsedAccountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return nil, utils.ErrInvalidUUID
+	}
+
+	var holders []models.AccountHolder
+	err = service.Storage.View([]uuid.UUID{parsedAccountUUID}, func(store storage.Storage) error {
+		// Make sure the account exists so an unknown ID is not an empty list
+		if _, err := store.FindAccount(parsedAccountUUID); err != nil {
+			return err
+		}
+
+		holders, err = store.FindAccountHolders(parsedAccountUUID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return holders, nil
+}
+
+// Save adds a customer as a holder of an account or changes their role. Only
+// its owners, or its customer while it has no holders, acting as actor, may
+// change them. Accounts without a customer have no one to grant roles and
+// must be linked to a customer first
+func (service *HolderService) Save(accountId string, customerId string, request requests.HolderRequest, actor string) (models.AccountHolder, error) {
+	// Validate and parse both UUIDs and the role
+	parsedAccountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return models.AccountHolder{}, utils.ErrInvalidUUID
+	}
+	parsedCustomerUUID, err := uuid.Parse(customerId)
+	if err != nil {
+		return models.AccountHolder{}, utils.ErrInvalidUUID
+	}
+	role, err := utils.ParseHolderRole(request.Role)
+	if err != nil {
+		return models.AccountHolder{}, utils.ErrInvalidRequestBody
+	}
+
+	holder := models.AccountHolder{AccountID: parsedAccountUUID, CustomerID: parsedCustomerUUID, Role: role}
+	err = lockResolved(service.Storage, parsedAccountUUID, accountParties(parsedAccountUUID, parsedCustomerUUID), func(store storage.Storage) error {
+		// Find the account and the customer in storage
+		account, err := store.FindAccount(parsedAccountUUID)
+		if err != nil {
+			return err
+		}
+		if _, err := store.FindCustomer(parsedCustomerUUID); err != nil {
+			return err
+		}
+		if account.CustomerID == uuid.Nil {
+			return utils.ErrHolderNotPermitted
+		}
+		if err := authorize(store, account, actor, utils.HolderRole.CanManage); err != nil {
+			return err
+		}
+
+		if err := store.SaveAccountHolder(holder); err != nil {
+			return err
+		}
+		return keepOwner(store, account)
+	})
+	if err != nil {
+		return models.AccountHolder{}, err
+	}
+
+	return holder, nil
+}
+
+// Delete removes a customer from the holders of an account. Once an account
+// has holders only its owners, acting as actor, may change them
+func (service *HolderService) Delete(accountId string, customerId string, actor string) error {
+	// Validate and parse both UUIDs
+	parsedAccountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return utils.ErrInvalidUUID
+	}
+	parsedCustomerUUID, err := uuid.Parse(customerId)
+	if err != nil {
+		return utils.ErrInvalidUUID
+	}
+
+	return lockResolved(service.Storage, parsedAccountUUID, accountParties(parsedAccountUUID, parsedCustomerUUID), func(store storage.Storage) error {
+		// Find the account in storage
+		account, err := store.FindAccount(parsedAccountUUID)
+		if err != nil {
+			return err
+		}
+		if err := authorize(store, account, actor, utils.HolderRole.CanManage); err != nil {
+			return err
+		}
+
+		if err := store.DeleteAccountHolder(parsedAccountUUID, parsedCustomerUUID); err != nil {
+			return err
+		}
+		return keepOwner(store, account)
+	})
+}
+
+// accountParties resolves the IDs that units of work changing the holders
+// of an account lock: the account, the customer joining or leaving it, and
+// its customer and holders, any of whom keepOwner may make its customer
+func accountParties(accountID uuid.UUID, customerID uuid.UUID) func(store storage.Storage) ([]uuid.UUID, error) {
+	return func(store storage.Storage) ([]uuid.UUID, error) {
+		account, err := store.FindAccount(accountID)
+		if err != nil {
+			return nil, err
+		}
+		holders, err := store.FindAccountHolders(accountID)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := []uuid.UUID{accountID, customerID, account.CustomerID}
+		for _, holder := range holders {
+			ids = append(ids, holder.CustomerID)
+		}
+		return ids, nil
+	}
+}
+
+// keepOwner checks that an account whose holders changed still has an owner
+// and moves the customer of the account to the first owner when its
+// customer is no longer one
+func keepOwner(store storage.Storage, account models.Account) error {
+	holders, err := store.FindAccountHolders(account.ID)
+	if err != nil {
+		return err
+	}
+
+	owner := uuid.Nil
+	for _, holder := range holders {
+		if holder.Role != utils.Owner {
+			continue
+		}
+		if holder.CustomerID == account.CustomerID {
+			return nil
+		}
+		if owner == uuid.Nil {
+			owner = holder.CustomerID
+		}
+	}
+	if owner == uuid.Nil {
+		return utils.ErrLastAccountOwner
+	}
+
+	account.CustomerID = owner
+	return store.UpdateAccount(account)
+}
+
+// authorize checks that the acting customer holds the account with a role
+// that allows the operation. An account without holders may only be acted
+// on by its own customer, or by anyone when it has none, as its owner is
+// then only a name. It must be called inside a unit of work
+func authorize(store storage.Storage, account models.Account, actor string, allowed func(utils.HolderRole) bool) error {
+	holders, err := store.FindAccountHolders(account.ID)
+	if err != nil {
+		return err
+	}
+	if len(holders) == 0 && account.CustomerID == uuid.Nil {
+		return nil
+	}
+
+	// An unknown or missing actor is simply not a holder
+	actorUUID, err := uuid.Parse(actor)
+	if err != nil {
+		return utils.ErrHolderNotPermitted
+	}
+	if len(holders) == 0 {
+		if actorUUID != account.CustomerID {
+			return utils.ErrHolderNotPermitted
+		}
+		return nil
+	}
+	for _, holder := range holders {
+		if holder.CustomerID == actorUUID && allowed(holder.Role) {
+			return nil
+		}
+	}
+	return utils.ErrHolderNotPermitted
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultIdempotencyTTL is how long idempotency keys are remembered when no
+// other window is configured
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+type IdempotencyService struct {
+	Storage storage.Storage
+	TTL     time.Duration // How long a key is remembered after its first use
+	Clock   utils.Clock   // Tells when keys expire
+}
+
+// CreateIdempotencyService initializes a new IdempotencyService with the
+// provided storage and the default expiry window
+func CreateIdempotencyService(storage storage.Storage) *IdempotencyService {
+	return &IdempotencyService{
+		Storage: storage,
+		TTL:     DefaultIdempotencyTTL,
+		Clock:   utils.SystemClock{},
+	}
+}
+
+// Begin claims an idempotency key for a request identified by fingerprint.
+// It returns true together with the stored record when the key already
+// holds a completed response for the same request. A key used for another
+// request fails with ErrIdempotencyKeyMismatch and a key whose first request
+// is still running fails with ErrIdempotencyKeyInProgress
+func (service *IdempotencyService) Begin(key string, fingerprint string) (models.IdempotencyRecord, bool, error) {
+	now := service.Clock.Now()
+	var record models.IdempotencyRecord
+	var replay bool
+	err := service.Storage.Lock([]uuid.UUID{storage.KeyLockID(key)}, func(store storage.Storage) error {
+		// Check a key that is already known. One that expired since is
+		// claimed again
+		existing, err := store.FindIdempotencyRecord(key)
+		if err == nil && existing.ExpiresAt.After(now) {
+			if existing.Fingerprint != fingerprint {
+				return utils.ErrIdempotencyKeyMismatch
+			}
+			if !existing.Completed {
+				return utils.ErrIdempotencyKeyInProgress
+			}
+			record, replay = existing, true
+			return nil
+		}
+		if err != nil && err != utils.ErrIdempotencyKeyNotFound {
+			return err
+		}
+
+		// Claim the key until the request completes
+		record = models.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(service.TTL),
+		}
+		return store.SaveIdempotencyRecord(record)
+	})
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	return record, replay, nil
+}
+
+// Complete stores the response of the request that claimed a key so that
+// its retries replay it
+func (service *IdempotencyService) Complete(record models.IdempotencyRecord) error {
+	record.Completed = true
+	return service.Storage.SaveIdempotencyRecord(record)
+}
+
+// Release gives up a claimed key so that the request can be retried
+func (service *IdempotencyService) Release(key string) error {
+	return service.Storage.DeleteIdempotencyRecord(key)
+}
+
+// Sweep deletes the records of expired keys. Begin already treats them as
+// unknown, sweeping only frees the space they take
+func (service *IdempotencyService) Sweep() error {
+	return service.Storage.DeleteExpiredIdempotencyRecords(service.Clock.Now())
+}
+
+// Schedule sweeps expired keys in the background now and then every
+// interval until the returned function is called. Failed sweeps are logged
+// and retried at the next interval
+func (service *IdempotencyService) Schedule(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			if err := service.Sweep(); err != nil {
+				log.Printf("Idempotency sweep error: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
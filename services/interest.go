@@ -0,0 +1,315 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// accrualScale is the number of decimal places daily accruals keep beyond
+// the minor unit of their currency, so that a month of them rounds correctly
+const accrualScale = 6
+
+// InterestService accrues the interest of savings accounts and term deposits
+// every day and pays it monthly. Days and months run in UTC
+type InterestService struct {
+	Storage storage.Storage
+	Clock   utils.Clock // Tells which day is today
+}
+
+// CreateInterestService initializes a new InterestService with the provided
+// storage and clock
+func CreateInterestService(storage storage.Storage, clock utils.Clock) *InterestService {
+	return &InterestService{
+		Storage: storage,
+		Clock:   clock,
+	}
+}
+
+// Run accrues interest for every day since the last accrual of each account,
+// or since it was opened, up to yesterday, and pays the months that ended.
+// Running it again the same day changes nothing
+func (service *InterestService) Run() (models.InterestRun, error) {
+	return service.accrue(time.Time{}, service.today())
+}
+
+// Accrue accrues interest for the days of the request that have not been
+// accrued yet, up to yesterday at most, and pays the months that ended
+// within them
+func (service *InterestService) Accrue(request requests.InterestAccrualRequest) (models.InterestRun, error) {
+	// Parse the dates, never accruing today or later
+	from, err := time.Parse(time.DateOnly, request.From)
+	if err != nil {
+		return models.InterestRun{}, utils.ErrInvalidDateRange
+	}
+	to, err := time.Parse(time.DateOnly, request.To)
+	if err != nil || !from.Before(to) {
+		return models.InterestRun{}, utils.ErrInvalidDateRange
+	}
+	if today := service.today(); to.After(today) {
+		to = today
+	}
+
+	return service.accrue(from, to)
+}
+
+// Schedule runs the accrual in the background now and then every interval
+// until the returned function is called. Failed runs and accounts are logged
+// and retried at the next interval
+func (service *InterestService) Schedule(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			run, err := service.Run()
+			if err != nil {
+				log.Printf("Interest accrual error: %v", err)
+			}
+			for _, failure := range run.Failed {
+				log.Printf("Interest accrual error for account %s: %v", failure.AccountID, failure.Err)
+			}
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// today returns the start of the current day in UTC
+func (service *InterestService) today() time.Time {
+	return startOfDay(service.Clock.Now())
+}
+
+// accrue accrues every account earning interest for the days from from, or
+// from its last accrual when from is zero, up to but excluding to. Every
+// account is accrued in its own unit of work, so one that fails is rolled
+// back and listed in the run while the others carry on
+func (service *InterestService) accrue(from time.Time, to time.Time) (models.InterestRun, error) {
+	accounts, err := service.Storage.FindAccounts()
+	if err != nil {
+		return models.InterestRun{}, err
+	}
+
+	run := models.InterestRun{Paid: []models.Transaction{}, Failed: []models.InterestFailure{}}
+	for _, account := range accounts {
+		if account.InterestRate.Units == 0 || account.Status == utils.Closed {
+			continue
+		}
+
+		// Only count what the account did once its unit of work committed
+		accountRun := models.InterestRun{}
+		err := service.Storage.Lock([]uuid.UUID{account.ID}, func(store storage.Storage) error {
+			return accrueAccount(store, account.ID, from, to, &accountRun)
+		})
+		if err != nil {
+			run.Failed = append(run.Failed, models.InterestFailure{AccountID: account.ID, Err: err})
+			continue
+		}
+		run.Accrued += accountRun.Accrued
+		run.Paid = append(run.Paid, accountRun.Paid...)
+	}
+	return run, nil
+}
+
+// accrueAccount accrues one account and adds what it did to the run. It must
+// be called inside a unit of work
+func accrueAccount(store storage.Storage, accountID uuid.UUID, from time.Time, to time.Time, run *models.InterestRun) error {
+	// Find the account in storage
+	account, err := store.FindAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	// Start from the day after the last accrual unless a range is given,
+	// but never before the account was opened
+	start := startOfDay(account.CreatedAt)
+	if from.IsZero() {
+		latest, err := store.FindLatestInterestAccrual(account.ID)
+		if err != nil && err != utils.ErrAccrualNotFound {
+			return err
+		}
+		if err == nil {
+			start = latest.Date.AddDate(0, 0, 1)
+		}
+	} else if from.After(start) {
+		start = from
+	}
+	if !start.Before(to) {
+		return nil
+	}
+
+	// Skip the days of the range that were already accrued
+	existing, err := store.FindInterestAccruals(account.ID, start, to)
+	if err != nil {
+		return err
+	}
+	accrued := map[time.Time]bool{}
+	for _, accrual := range existing {
+		accrued[accrual.Date] = true
+	}
+
+	// Work out the balance at the start of the range by undoing every
+	// transaction made since
+	transactions, err := store.FindTransactions(account.ID)
+	if err != nil {
+		return err
+	}
+	balance := account.Balance
+	for _, transaction := range transactions {
+		if !transaction.TimeStamp.Before(start) {
+			if balance, err = balance.Sub(transaction.Signed()); err != nil {
+				return err
+			}
+		}
+	}
+
+	for day := start; day.Before(to); day = day.AddDate(0, 0, 1) {
+		// Apply the transactions of the day to find its closing balance
+		next := day.AddDate(0, 0, 1)
+		for _, transaction := range transactions {
+			if !transaction.TimeStamp.Before(day) && transaction.TimeStamp.Before(next) {
+				if balance, err = balance.Add(transaction.Signed()); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !accrued[day] {
+			amount, err := dailyInterest(account, balance, day)
+			if err != nil {
+				return err
+			}
+			err = store.SaveInterestAccrual(models.InterestAccrual{
+				AccountID: account.ID,
+				Date:      day,
+				Balance:   balance,
+				Amount:    amount,
+			})
+			if err != nil {
+				return err
+			}
+			run.Accrued++
+		}
+
+		// Pay the month once its last day is accrued. The payment belongs
+		// to the first day of the next month
+		if next.Day() != 1 {
+			continue
+		}
+		transaction, err := payInterest(store, account, next.AddDate(0, -1, 0), next)
+		if err != nil {
+			return err
+		}
+		if transaction.ID != uuid.Nil {
+			if balance, err = balance.Add(transaction.Amount); err != nil {
+				return err
+			}
+			run.Paid = append(run.Paid, transaction)
+		}
+	}
+	return nil
+}
+
+// dailyInterest returns the interest earned over one day by a closing
+// balance. Only positive balances earn interest
+func dailyInterest(account models.Account, balance money.Money, day time.Time) (money.Money, error) {
+	scale := balance.Scale + accrualScale
+	if !balance.IsPositive() {
+		return money.Zero(scale), nil
+	}
+
+	// ACT/365 pays every day alike while 30/360 pays every month as if it
+	// had 30 days
+	if account.DayCount == utils.Thirty360 {
+		return account.InterestRate.Prorate(balance, days360(day, day.AddDate(0, 0, 1)), 360, scale)
+	}
+	return account.InterestRate.Prorate(balance, 1, 365, scale)
+}
+
+// days360 counts the days between two dates as if every month had 30 days
+func days360(start time.Time, end time.Time) int64 {
+	startDay := min(start.Day(), 30)
+	endDay := min(end.Day(), 30)
+	return int64(360*(end.Year()-start.Year()) + 30*(int(end.Month())-int(start.Month())) + endDay - startDay)
+}
+
+// payInterest pays the unpaid accruals dated from from up to but excluding
+// to, rounded to the minor unit, and marks them paid. It returns a zero
+// transaction when there is nothing to pay. It must be called inside a unit
+// of work
+func payInterest(store storage.Storage, account models.Account, from time.Time, to time.Time) (models.Transaction, error) {
+	accruals, err := store.FindInterestAccruals(account.ID, from, to)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	// Sum the unpaid accruals in the account currency
+	currency, err := money.LookupCurrency(account.Currency)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	unpaid := []models.InterestAccrual{}
+	total := money.Zero(currency.Scale + accrualScale)
+	for _, accrual := range accruals {
+		if accrual.TransactionID == uuid.Nil {
+			unpaid = append(unpaid, accrual)
+			if total, err = total.Add(accrual.Amount); err != nil {
+				return models.Transaction{}, err
+			}
+		}
+	}
+	amount, err := money.Rate{Units: 1}.Convert(total, currency.Scale)
+	if err != nil || !amount.IsPositive() {
+		return models.Transaction{}, err
+	}
+
+	// Record the interest and post it from the interest account
+	transaction, err := record(store, models.Transaction{
+		AccountID: account.ID,
+		Type:      utils.Interest,
+		Amount:    amount,
+		TimeStamp: to,
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	_, err = post(store, models.JournalEntry{
+		Description: "interest",
+		Postings: []models.Posting{
+			debit(models.InterestAccountID, transaction.ID, account.Currency, amount),
+			credit(account.ID, transaction.ID, account.Currency, amount),
+		},
+		TimeStamp: to,
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	// Mark the accruals paid by the transaction
+	for _, accrual := range unpaid {
+		accrual.TransactionID = transaction.ID
+		if err := store.SaveInterestAccrual(accrual); err != nil {
+			return models.Transaction{}, err
+		}
+	}
+	return transaction, nil
+}
+
+// startOfDay returns midnight UTC of the day holding t
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"bytes"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type LedgerService struct {
+	Storage storage.Storage
+}
+
+// CreateLedgerService initializes a new LedgerService with the provided storage
+func CreateLedgerService(storage storage.Storage) *LedgerService {
+	return &LedgerService{
+		Storage: storage,
+	}
+}
+
+// TrialBalance sums the postings of every ledger account and checks that
+// debits equal credits in every currency and that the balance of every
+// customer account matches its postings
+func (service *LedgerService) TrialBalance() (models.TrialBalance, error) {
+	// Read the posting totals and the accounts in a view of every account
+	// posted to, found from the system accounts every entry balances against
+	var trialBalance models.TrialBalance
+	err := viewResolved(service.Storage, systemAccountIDs(), func(store storage.Storage) ([]uuid.UUID, error) {
+		balances, err := store.FindLedgerBalances()
+		if err != nil {
+			return nil, err
+		}
+		accounts, err := store.FindAccounts()
+		if err != nil {
+			return nil, err
+		}
+		if trialBalance, err = buildTrialBalance(balances, accounts); err != nil {
+			return nil, err
+		}
+		return ledgerOwners(balances, accounts), nil
+	})
+	if err != nil {
+		return models.TrialBalance{}, err
+	}
+
+	return trialBalance, nil
+}
+
+// systemAccountIDs returns the IDs of the system accounts in ascending order
+func systemAccountIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(models.SystemAccounts))
+	for id := range models.SystemAccounts {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(left, right uuid.UUID) int { return bytes.Compare(left[:], right[:]) })
+	return ids
+}
+
+// ledgerOwners returns the system accounts followed by the accounts and by
+// any other account posted to, which own everything a trial balance reads
+func ledgerOwners(balances []models.LedgerBalance, accounts []models.Account) []uuid.UUID {
+	ids := systemAccountIDs()
+	for _, account := range accounts {
+		ids = append(ids, account.ID)
+	}
+	listed := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		listed[id] = true
+	}
+	for _, balance := range balances {
+		if !listed[balance.AccountID] {
+			listed[balance.AccountID] = true
+			ids = append(ids, balance.AccountID)
+		}
+	}
+	return ids
+}
+
+// buildTrialBalance turns posting totals into a trial balance, comparing
+// them with the stored balances of the customer accounts. It fails with
+// ErrInvalidAmount should a total overflow
+func buildTrialBalance(balances []models.LedgerBalance, accounts []models.Account) (models.TrialBalance, error) {
+	trialBalance := models.TrialBalance{
+		Lines:    []models.TrialBalanceLine{},
+		Totals:   []models.TrialBalanceTotal{},
+		Balanced: true,
+	}
+
+	// Index the customer accounts to name the lines and compare balances
+	owners := map[uuid.UUID]models.Account{}
+	derived := map[uuid.UUID]money.Money{}
+	for _, account := range accounts {
+		owners[account.ID] = account
+		derived[account.ID] = money.Zero(account.Balance.Scale)
+	}
+
+	totals := map[string]int{}
+	for _, balance := range balances {
+		// Name the line after the customer or the system account
+		net, err := balance.Credits.Sub(balance.Debits)
+		if err != nil {
+			return models.TrialBalance{}, err
+		}
+		name := models.SystemAccounts[balance.AccountID]
+		if account, ok := owners[balance.AccountID]; ok {
+			name = account.Owner
+			if balance.Currency == account.Currency {
+				derived[account.ID] = net
+			} else {
+				trialBalance.Balanced = false
+			}
+		}
+		trialBalance.Lines = append(trialBalance.Lines, models.TrialBalanceLine{
+			LedgerBalance: balance,
+			Name:          name,
+			Balance:       net,
+		})
+
+		// Add the postings to the totals of their currency
+		index, ok := totals[balance.Currency]
+		if !ok {
+			index = len(trialBalance.Totals)
+			totals[balance.Currency] = index
+			trialBalance.Totals = append(trialBalance.Totals, models.TrialBalanceTotal{
+				Currency: balance.Currency,
+				Debits:   money.Zero(balance.Debits.Scale),
+				Credits:  money.Zero(balance.Credits.Scale),
+			})
+		}
+		total := &trialBalance.Totals[index]
+		if total.Debits, err = total.Debits.Add(balance.Debits); err != nil {
+			return models.TrialBalance{}, err
+		}
+		if total.Credits, err = total.Credits.Add(balance.Credits); err != nil {
+			return models.TrialBalance{}, err
+		}
+	}
+
+	// The books balance when debits equal credits in every currency and no
+	// stored balance drifted from its postings
+	for _, total := range trialBalance.Totals {
+		if total.Debits.Cmp(total.Credits) != 0 {
+			trialBalance.Balanced = false
+		}
+	}
+	for _, account := range accounts {
+		if account.Balance.Cmp(derived[account.ID]) != 0 {
+			trialBalance.Balanced = false
+		}
+	}
+	return trialBalance, nil
+}
+
+// post records a balanced journal entry and applies its postings to the
+// balances of the customer accounts involved. It must be called inside a
+// unit of work
+func post(store storage.Storage, entry models.JournalEntry) (models.JournalEntry, error) {
+	// Refuse entries whose debits and credits differ
+	if !entry.IsBalanced() {
+		return models.JournalEntry{}, utils.ErrUnbalancedEntry
+	}
+
+	// Give the entry a unique ID
+	entry.ID = uuid.New()
+
+	// Apply every posting to its customer account, system accounts only
+	// exist in the journal
+	for _, posting := range entry.Postings {
+		if _, ok := models.SystemAccounts[posting.AccountID]; ok {
+			continue
+		}
+
+		account, err := store.FindAccount(posting.AccountID)
+		if err != nil {
+			return models.JournalEntry{}, err
+		}
+		if posting.Currency != account.Currency {
+			return models.JournalEntry{}, utils.ErrCurrencyMismatch
+		}
+
+		// Refuse balances too large to hold, or whose available funds are
+		if account.Balance, err = account.Balance.Add(posting.Signed()); err != nil {
+			return models.JournalEntry{}, err
+		}
+		if _, err := account.Available(); err != nil {
+			return models.JournalEntry{}, err
+		}
+		if err := store.UpdateAccount(account); err != nil {
+			return models.JournalEntry{}, err
+		}
+	}
+
+	// Add the entry to the journal
+	if err := store.CreateJournalEntry(entry); err != nil {
+		return models.JournalEntry{}, err
+	}
+	return entry, nil
+}
+
+// debit returns a posting that debits amount from a ledger account
+func debit(accountID uuid.UUID, transactionID uuid.UUID, currency string, amount money.Money) models.Posting {
+	return models.Posting{AccountID: accountID, TransactionID: transactionID, Side: utils.Debit, Currency: currency, Amount: amount}
+}
+
+// credit returns a posting that credits amount to a ledger account
+func credit(accountID uuid.UUID, transactionID uuid.UUID, currency string, amount money.Money) models.Posting {
+	return models.Posting{AccountID: accountID, TransactionID: transactionID, Side: utils.Credit, Currency: currency, Amount: amount}
+}
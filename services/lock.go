@@ -0,0 +1,66 @@
+package services
+
+import (
+	"bank-account-manager/storage"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// errOwnersMoved tells lockResolved that the IDs to lock changed between
+// resolving and locking them
+var errOwnersMoved = errors.New("owners moved while locking them")
+
+// lockResolved runs fn in a unit of work locking the IDs that resolve finds
+// from the record with the given ID, such as the account of a hold, which
+// are only known once that record was read. They are resolved first and
+// again once locked, and the unit is retried should they have changed in
+// between, so resolving them first only needs a view of the record itself
+func lockResolved(store storage.Storage, id uuid.UUID, resolve func(store storage.Storage) ([]uuid.UUID, error), fn func(store storage.Storage) error) error {
+	for {
+		// Resolve the IDs from what the store holds now
+		var ids []uuid.UUID
+		err := store.View([]uuid.UUID{id}, func(store storage.Storage) (err error) {
+			ids, err = resolve(store)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		// Lock them, checking nothing moved before running fn
+		err = store.Lock(ids, func(store storage.Storage) error {
+			locked, err := resolve(store)
+			if err != nil {
+				return err
+			}
+			if !slices.Equal(locked, ids) {
+				return errOwnersMoved
+			}
+			return fn(store)
+		})
+		if err != errOwnersMoved {
+			return err
+		}
+	}
+}
+
+// viewResolved runs read in a view of the IDs owning the records it reads,
+// such as the accounts of a transfer, which read returns and which are only
+// known once the records were read. read runs first in a view of the given
+// IDs, then again in a view of the IDs it returned until they stop
+// changing, so that everything it read last was committed
+func viewResolved(store storage.Storage, ids []uuid.UUID, read func(store storage.Storage) ([]uuid.UUID, error)) error {
+	for {
+		var owners []uuid.UUID
+		err := store.View(ids, func(store storage.Storage) (err error) {
+			owners, err = read(store)
+			return err
+		})
+		if err != nil || slices.Equal(owners, ids) {
+			return err
+		}
+		ids = owners
+	}
+}
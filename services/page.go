@@ -0,0 +1,65 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/requests"
+	"bank-account-manager/utils"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// encodeCursor turns the position of the last item of a page into an opaque
+// cursor
+func encodeCursor(cursor models.Cursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(cursor.Value, 10) + ":" + cursor.ID.String()))
+}
+
+// decodeCursor parses a cursor created by encodeCursor. An empty cursor
+// starts at the first page
+func decodeCursor(text string) (*models.Cursor, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(text)
+	if err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	value, id, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return nil, utils.ErrInvalidCursor
+	}
+	parsedValue, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	return &models.Cursor{Value: parsedValue, ID: parsedID}, nil
+}
+
+// pageLimit returns the requested page size or the default one
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return requests.DefaultPageLimit
+	}
+	return limit
+}
+
+// parseTime parses an optional RFC 3339 timestamp of a query
+func parseTime(text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return time.Time{}, utils.ErrInvalidQuery
+	}
+	return parsed, nil
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+
+	"github.com/google/uuid"
+)
+
+// Reverse undoes a deposit, a withdrawal or both legs of a transfer, in full
+// or in part, with compensating transactions linked to the originals. A
+// withdrawal is undone by a refund and a deposit by a reversal. Transfers are
+// reversed from either leg with the amount in the source account currency.
+// No leg can be reversed for more than what is left of it
+func (service *TransactionService) Reverse(transactionId string, request requests.ReversalRequest) ([]models.Transaction, error) {
+	// Validate and parse the transaction UUID
+	parsedTransactionUUID, err := uuid.Parse(transactionId)
+	if err != nil {
+		return []models.Transaction{}, utils.ErrInvalidUUID
+	}
+
+	now := service.Clock.Now()
+	reversals := []models.Transaction{}
+	err = lockResolved(service.Storage, parsedTransactionUUID, legAccounts(parsedTransactionUUID), func(store storage.Storage) error {
+		// Find the transaction and the other leg of a transfer
+		original, err := store.FindTransaction(parsedTransactionUUID)
+		if err != nil {
+			return err
+		}
+		legs, err := reversibleLegs(store, original)
+		if err != nil {
+			return err
+		}
+
+		// Work out what is left of every leg to reverse
+		remaining := []money.Money{}
+		for _, leg := range legs {
+			left, err := unreversed(store, leg)
+			if err != nil {
+				return err
+			}
+			remaining = append(remaining, left)
+		}
+
+		// Parse the amount in the currency of the first leg, everything
+		// left unless requested
+		amount := remaining[0]
+		if request.Amount != "" {
+			if amount, err = parseAmount(request.Amount, legs[0].Currency); err != nil {
+				return err
+			}
+		}
+		if !amount.IsPositive() || amount.Cmp(remaining[0]) > 0 {
+			return utils.ErrReversalExceedsAmount
+		}
+		amounts := []money.Money{amount}
+		if len(legs) == 2 {
+			deposit, err := reversedDeposit(legs, remaining, amount)
+			if err != nil {
+				return err
+			}
+			if !deposit.IsPositive() {
+				return utils.ErrReversalExceedsAmount
+			}
+			amounts = append(amounts, deposit)
+		}
+
+		// Record the compensating transactions. Reversals take money out of
+		// the account, so only holders allowed to debit it may ask for them
+		for index, leg := range legs {
+			reversal := models.Transaction{
+				AccountID: leg.AccountID,
+				Type:      utils.Refund,
+				Amount:    amounts[index],
+				RelatedID: leg.ID,
+				TimeStamp: now,
+			}
+			if leg.Type == utils.Deposit {
+				reversal.Type = utils.Reversal
+				account, err := store.FindAccount(leg.AccountID)
+				if err != nil {
+					return err
+				}
+				if err := authorize(store, account, request.ActingCustomerID, utils.HolderRole.CanDebit); err != nil {
+					return err
+				}
+			}
+			reversal, err = record(store, reversal)
+			if err != nil {
+				return err
+			}
+			reversals = append(reversals, reversal)
+		}
+
+		// Post the opposite of the original entry
+		entry := models.JournalEntry{Description: "reversal", TimeStamp: now}
+		switch {
+		case len(reversals) == 1 && reversals[0].Type == utils.Refund:
+			refund := reversals[0]
+			entry.Postings = []models.Posting{
+				debit(models.ExternalAccountID, refund.ID, refund.Currency, refund.Amount),
+				credit(refund.AccountID, refund.ID, refund.Currency, refund.Amount),
+			}
+		case len(reversals) == 1:
+			reversal := reversals[0]
+			entry.Postings = []models.Posting{
+				debit(reversal.AccountID, reversal.ID, reversal.Currency, reversal.Amount),
+				credit(models.ExternalAccountID, reversal.ID, reversal.Currency, reversal.Amount),
+			}
+		case legs[0].Exchange == nil:
+			refund, reversal := reversals[0], reversals[1]
+			entry.Postings = []models.Posting{
+				debit(reversal.AccountID, reversal.ID, reversal.Currency, reversal.Amount),
+				credit(refund.AccountID, refund.ID, refund.Currency, refund.Amount),
+			}
+		default:
+			refund, reversal := reversals[0], reversals[1]
+			entry.Postings = []models.Posting{
+				debit(reversal.AccountID, reversal.ID, reversal.Currency, reversal.Amount),
+				credit(models.ExchangeAccountID, reversal.ID, reversal.Currency, reversal.Amount),
+				debit(models.ExchangeAccountID, refund.ID, refund.Currency, refund.Amount),
+				credit(refund.AccountID, refund.ID, refund.Currency, refund.Amount),
+			}
+		}
+		if _, err = post(store, entry); err != nil {
+			return err
+		}
+
+		// Record how much of a transfer is reversed
+		if legs[0].TransferID == uuid.Nil {
+			return nil
+		}
+		transfer, err := store.FindTransfer(legs[0].TransferID)
+		if err != nil {
+			return err
+		}
+		transfer.Status = utils.TransferPartiallyReversed
+		if amount.Cmp(remaining[0]) == 0 {
+			transfer.Status = utils.TransferReversed
+		}
+		return store.UpdateTransfer(transfer)
+	})
+	if err != nil {
+		return []models.Transaction{}, err
+	}
+
+	return reversals, nil
+}
+
+// reversibleLegs returns the transactions reversed together with the given
+// one: the withdrawal and deposit legs of a transfer in that order, or the
+// deposit or withdrawal alone. Other transactions cannot be reversed
+func reversibleLegs(store storage.Storage, transaction models.Transaction) ([]models.Transaction, error) {
+	if transaction.Type != utils.Deposit && transaction.Type != utils.Withdrawal {
+		return nil, utils.ErrNotReversible
+	}
+	if transaction.TransferID == uuid.Nil {
+		return []models.Transaction{transaction}, nil
+	}
+
+	// Find both legs of the transfer, leaving out its fees
+	transfer, err := loadTransfer(store, transaction.TransferID)
+	if err != nil {
+		return nil, err
+	}
+	return []models.Transaction{transfer.Withdrawal, transfer.Deposit}, nil
+}
+
+// legAccounts resolves the accounts of the legs reversed together with a
+// transaction, which units of work reversing it lock
+func legAccounts(transactionID uuid.UUID) func(store storage.Storage) ([]uuid.UUID, error) {
+	return func(store storage.Storage) ([]uuid.UUID, error) {
+		transaction, err := store.FindTransaction(transactionID)
+		if err != nil {
+			return nil, err
+		}
+		legs, err := reversibleLegs(store, transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := []uuid.UUID{}
+		for _, leg := range legs {
+			ids = append(ids, leg.AccountID)
+		}
+		return ids, nil
+	}
+}
+
+// unreversed returns the part of a transaction that its refunds or reversals
+// have not undone yet
+func unreversed(store storage.Storage, transaction models.Transaction) (money.Money, error) {
+	related, err := store.FindRelatedTransactions(transaction.ID)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	left := transaction.Amount
+	for _, reversal := range related {
+		if reversal.Type == utils.Refund || reversal.Type == utils.Reversal {
+			if left, err = left.Sub(reversal.Amount); err != nil {
+				return money.Money{}, err
+			}
+		}
+	}
+	return left, nil
+}
+
+// reversedDeposit returns the part of the deposit leg of a transfer to reverse
+// with the given part of its withdrawal leg. What is left of the deposit is
+// reversed with the last part of the withdrawal so rounding never strands any
+func reversedDeposit(legs []models.Transaction, remaining []money.Money, amount money.Money) (money.Money, error) {
+	if amount.Cmp(remaining[0]) == 0 {
+		return remaining[1], nil
+	}
+	if legs[0].Exchange == nil {
+		return amount, nil
+	}
+
+	// Convert at the rate of the transfer, never past what is left
+	converted, err := legs[0].Exchange.Rate.Convert(amount, legs[1].Amount.Scale)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if !converted.IsPositive() {
+		return money.Money{}, utils.ErrInvalidAmount
+	}
+	if converted.Cmp(remaining[1]) > 0 {
+		return remaining[1], nil
+	}
+	return converted, nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountRules configures the rules each account type enforces on its
+// transactions. Statement cycles and withdrawal limits run per calendar
+// month in UTC
+type AccountRules struct {
+	SavingsWithdrawals       int                    // Withdrawals a savings account allows per month, 0 for no limit
+	CheckingFreeTransactions int                    // Transactions a checking account makes per cycle without a fee, 0 for no limit
+	CheckingTransactionFees  map[string]money.Money // Fee charged for each checking transaction beyond the allowance by currency code, none in other currencies
+}
+
+// DefaultAccountRules limits savings accounts to six withdrawals a month and
+// lets checking accounts transact without fees
+var DefaultAccountRules = AccountRules{
+	SavingsWithdrawals: 6,
+}
+
+// cycleStart returns the start of the calendar month, in UTC, holding t
+func cycleStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// parseMaturity parses the maturity date of a new account. Term deposits
+// need one after now and other account types none. Dates without a time
+// mature at midnight UTC
+func parseMaturity(accountType utils.AccountType, text string, now time.Time) (time.Time, error) {
+	if accountType != utils.TermDeposit {
+		if text != "" {
+			return time.Time{}, utils.ErrInvalidMaturity
+		}
+		return time.Time{}, nil
+	}
+
+	maturesAt, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		if maturesAt, err = time.Parse(time.DateOnly, text); err != nil {
+			return time.Time{}, utils.ErrInvalidMaturity
+		}
+	}
+	if !maturesAt.After(now) {
+		return time.Time{}, utils.ErrInvalidMaturity
+	}
+	return maturesAt, nil
+}
+
+// enforce checks a new deposit or withdrawal against the rules of the
+// account type and returns the fee it costs, which is zero when it is free.
+// It must be called inside a unit of work before the transaction is recorded
+func (rules AccountRules) enforce(store storage.Storage, account models.Account, transaction models.Transaction) (money.Money, error) {
+	switch account.Type {
+	case utils.TermDeposit:
+		// Term deposits only pay out once they mature
+		if transaction.Type == utils.Withdrawal && transaction.TimeStamp.Before(account.MaturesAt) {
+			return money.Money{}, utils.ErrAccountLocked
+		}
+
+	case utils.Savings:
+		// Savings accounts allow a number of withdrawals per month
+		if transaction.Type != utils.Withdrawal || rules.SavingsWithdrawals <= 0 {
+			break
+		}
+		count, err := countTransactions(store, account.ID, cycleStart(transaction.TimeStamp), rules.SavingsWithdrawals, utils.Withdrawal)
+		if err != nil {
+			return money.Money{}, err
+		}
+		if count >= rules.SavingsWithdrawals {
+			return money.Money{}, utils.ErrWithdrawalLimit
+		}
+
+	case utils.Checking:
+		// Checking accounts pay a fee in their currency for transactions
+		// beyond the allowance
+		fee, ok := rules.CheckingTransactionFees[account.Currency]
+		if rules.CheckingFreeTransactions <= 0 || !ok || fee.IsZero() {
+			break
+		}
+		count, err := countTransactions(store, account.ID, cycleStart(transaction.TimeStamp), rules.CheckingFreeTransactions, utils.Deposit, utils.Withdrawal)
+		if err != nil {
+			return money.Money{}, err
+		}
+		if count >= rules.CheckingFreeTransactions {
+			return fee, nil
+		}
+	}
+	return money.Money{}, nil
+}
+
+// countTransactions counts the transactions of the given types an account
+// made since from, counting no further than limit
+func countTransactions(store storage.Storage, accountID uuid.UUID, from time.Time, limit int, types ...utils.TransactionType) (int, error) {
+	count := 0
+	for _, transactionType := range types {
+		transactions, err := store.SearchTransactions(models.TransactionFilter{
+			AccountID: accountID,
+			Type:      &transactionType,
+			From:      from,
+			Limit:     limit,
+		})
+		if err != nil {
+			return 0, err
+		}
+		count += len(transactions)
+	}
+	return count, nil
+}
@@ -2,21 +2,39 @@ package services
 
 import (
 	"bank-account-manager/models"
+	"bank-account-manager/money"
 	"bank-account-manager/requests"
 	"bank-account-manager/storage"
 	"bank-account-manager/utils"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type TransactionService struct {
-	Storage *storage.Storage
+	Storage  storage.Storage
+	Exchange *ExchangeService // Rates used for cross-currency transfers
+	Rules    AccountRules     // Rules enforced per account type
+	Fees     *FeeService      // Fee schedule of deposits, withdrawals and transfers
+	Clock    utils.Clock      // Source of transaction timestamps and hold expiry
+	HoldTTL  time.Duration    // How long holds stay pending before they expire
 }
 
-func CreateTransactionService(storage *storage.Storage) *TransactionService {
+// DefaultHoldTTL is how long holds reserve funds unless configured otherwise
+const DefaultHoldTTL = 7 * 24 * time.Hour
+
+// CreateTransactionService initializes a new TransactionService with the
+// provided storage, an empty exchange rate table, the default account rules,
+// an empty fee schedule and the system clock
+func CreateTransactionService(storage storage.Storage) *TransactionService {
 	return &TransactionService{
-		Storage: storage,
+		Storage:  storage,
+		Exchange: CreateExchangeService(),
+		Rules:    DefaultAccountRules,
+		Fees:     CreateFeeService(),
+		Clock:    utils.SystemClock{},
+		HoldTTL:  DefaultHoldTTL,
 	}
 }
 
@@ -28,51 +46,52 @@ func (service *TransactionService) Create(accountId string, request requests.Tra
 		return models.Transaction{}, utils.ErrInvalidUUID
 	}
 
-	// Validate and parse the transaction type
+	// Validate and parse the transaction type. Fees, interest, refunds and
+	// reversals are only booked by the bank
 	parsedType, err := utils.ParseTransactionType(request.Type)
-	if err != nil {
+	if err != nil || (parsedType != utils.Deposit && parsedType != utils.Withdrawal) {
 		return models.Transaction{}, utils.ErrInvalidTxType
 	}
 
-	// Lock storage for thread safety
-	service.Storage.Mutex.Lock()
-	defer service.Storage.Mutex.Unlock()
-
-	// Find the account in storage
-	accountIndex, err := service.Storage.FindAccount(parsedAccountUUID)
-	if err != nil {
-		return models.Transaction{}, err
-	}
+	// Check and update the balance in a single unit of work on the account
+	var transaction models.Transaction
+	err = service.Storage.Lock([]uuid.UUID{parsedAccountUUID}, func(store storage.Storage) error {
+		// Find the account to learn its currency
+		account, err := store.FindAccount(parsedAccountUUID)
+		if err != nil {
+			return err
+		}
 
-	// Get account and check balance for withdrawals
-	account := service.Storage.Accounts[accountIndex]
-	if parsedType == utils.Withdrawal && account.Balance < request.Amount {
-		return models.Transaction{}, utils.ErrInsufficientFunds
-	}
+		// Reject transactions in a currency other than the account's
+		if request.Currency != "" && !strings.EqualFold(request.Currency, account.Currency) {
+			return utils.ErrCurrencyMismatch
+		}
 
-	// Update account balance based on transaction type
-	if parsedType == utils.Deposit {
-		account.Balance += request.Amount
-	} else if parsedType == utils.Withdrawal {
-		account.Balance -= request.Amount
-	}
+		// Only holders allowed to debit the account may withdraw from it
+		if parsedType == utils.Withdrawal {
+			if err := authorize(store, account, request.ActingCustomerID, utils.HolderRole.CanDebit); err != nil {
+				return err
+			}
+		}
 
-	// Update storage with new account balance
-	service.Storage.Accounts[accountIndex] = account
+		// Validate and parse the amount in the account currency
+		amount, err := parseAmount(request.Amount, account.Currency)
+		if err != nil {
+			return err
+		}
 
-	// Create new transaction with unique ID
-	newUUID := uuid.New()
-	transaction := models.Transaction{
-		ID:        newUUID,
-		AccountID: parsedAccountUUID,
-		Type:      parsedType,
-		Amount:    request.Amount,
-		TimeStamp: time.Now(),
+		transaction, err = service.settle(store, account, models.Transaction{
+			AccountID: parsedAccountUUID,
+			Type:      parsedType,
+			Amount:    amount,
+			TimeStamp: service.Clock.Now(),
+		})
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, err
 	}
 
-	// Add transaction to storage
-	service.Storage.Transactions = append(service.Storage.Transactions, transaction)
-
 	return transaction, nil
 }
 
@@ -84,45 +103,452 @@ func (service *TransactionService) ReadByAccount(accountId string) ([]models.Tra
 		return []models.Transaction{}, utils.ErrInvalidUUID
 	}
 
-	// Lock storage for thread safety
-	service.Storage.Mutex.Lock()
-	defer service.Storage.Mutex.Unlock()
+	// Retrieve transactions for the specified account
+	return service.Storage.FindTransactions(parsedAccountUUID)
+}
+
+// Search retrieves one page of the transactions of an account matching the
+// query, sorted by timestamp
+func (service *TransactionService) Search(accountId string, query requests.TransactionQuery) (models.TransactionPage, error) {
+	// Validate and parse the account UUID
+	parsedAccountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return models.TransactionPage{}, utils.ErrInvalidUUID
+	}
+
+	// Parse the cursor, the time range and the transaction type
+	filter := models.TransactionFilter{
+		AccountID:  parsedAccountUUID,
+		Descending: query.Sort == "desc",
+		Limit:      pageLimit(query.Limit),
+	}
+	if filter.After, err = decodeCursor(query.Cursor); err != nil {
+		return models.TransactionPage{}, err
+	}
+	if filter.From, err = parseTime(query.From); err != nil {
+		return models.TransactionPage{}, err
+	}
+	if filter.To, err = parseTime(query.To); err != nil {
+		return models.TransactionPage{}, err
+	}
+	if query.Type != "" {
+		parsedType, err := utils.ParseTransactionType(query.Type)
+		if err != nil {
+			return models.TransactionPage{}, utils.ErrInvalidTxType
+		}
+		filter.Type = &parsedType
+	}
 
-	// Filter transactions for the specified account
-	transactions := []models.Transaction{}
-	for _, transaction := range service.Storage.Transactions {
-		if transaction.AccountID == parsedAccountUUID {
-			transactions = append(transactions, transaction)
+	var page models.TransactionPage
+	err = service.Storage.View([]uuid.UUID{parsedAccountUUID}, func(store storage.Storage) error {
+		// Find the account to parse the amount bounds in its currency
+		account, err := store.FindAccount(parsedAccountUUID)
+		if err != nil {
+			return err
 		}
+		if filter.MinAmount, err = parseBound(query.MinAmount, account.Currency); err != nil {
+			return err
+		}
+		if filter.MaxAmount, err = parseBound(query.MaxAmount, account.Currency); err != nil {
+			return err
+		}
+
+		// Fetch one transaction more than the page to learn if another follows
+		limit := filter.Limit
+		filter.Limit++
+		transactions, err := store.SearchTransactions(filter)
+		if err != nil {
+			return err
+		}
+
+		page.Transactions = transactions
+		if len(transactions) > limit {
+			page.Transactions = transactions[:limit]
+			last := page.Transactions[limit-1]
+			page.NextCursor = encodeCursor(models.Cursor{Value: last.TimeStamp.UnixNano(), ID: last.ID})
+		}
+		return nil
+	})
+	if err != nil {
+		return models.TransactionPage{}, err
 	}
 
-	return transactions, nil
+	return page, nil
 }
 
-// Transfer handles money transfer between two accounts
-func (services *TransactionService) Transfer(request requests.TransferRequest) error {
-	// Create withdrawal transaction from source account
-	_, err := services.Create(request.FromAccountID, requests.TransactionRequest{
-		Type:   utils.Withdrawal.String(),
-		Amount: request.Amount,
+// Transfer handles money transfer between two accounts, recorded as a
+// transfer referenced by both legs and their fees. Everything is committed
+// together or not at all. The amount is taken in the currency of the source
+// account and converted when the destination uses another one
+func (services *TransactionService) Transfer(request requests.TransferRequest) (models.Transfer, error) {
+	// Validate and parse both account UUIDs
+	fromAccountUUID, err := uuid.Parse(request.FromAccountID)
+	if err != nil {
+		return models.Transfer{}, utils.ErrInvalidUUID
+	}
+	toAccountUUID, err := uuid.Parse(request.ToAccountID)
+	if err != nil {
+		return models.Transfer{}, utils.ErrInvalidUUID
+	}
+
+	// Refuse to move money from an account into itself
+	if fromAccountUUID == toAccountUUID {
+		return models.Transfer{}, utils.ErrSameAccountTransfer
+	}
+
+	// Run the withdrawal and the deposit in one unit of work locking both
+	// accounts so a failure of either leg restores the balances of both
+	timestamp := services.Clock.Now()
+	var transfer models.Transfer
+	err = services.Storage.Lock([]uuid.UUID{fromAccountUUID, toAccountUUID}, func(store storage.Storage) error {
+		// Find both accounts to learn their currencies
+		fromAccount, err := store.FindAccount(fromAccountUUID)
+		if err != nil {
+			return err
+		}
+		toAccount, err := store.FindAccount(toAccountUUID)
+		if err != nil {
+			return err
+		}
+
+		// Only holders allowed to debit the source account may send from it
+		if err := authorize(store, fromAccount, request.ActingCustomerID, utils.HolderRole.CanDebit); err != nil {
+			return err
+		}
+
+		// Validate and parse the amount in the source account currency
+		amount, err := parseAmount(request.Amount, fromAccount.Currency)
+		if err != nil {
+			return err
+		}
+
+		// Convert the amount into the destination account currency
+		depositAmount, exchange, err := services.convert(amount, fromAccount.Currency, toAccount.Currency)
+		if err != nil {
+			return err
+		}
+
+		// Describe the transfer and its two legs
+		transfer = models.Transfer{
+			ID:            uuid.New(),
+			FromAccountID: fromAccountUUID,
+			ToAccountID:   toAccountUUID,
+			Currency:      fromAccount.Currency,
+			Amount:        amount,
+			Status:        utils.TransferCompleted,
+			TimeStamp:     timestamp,
+		}
+		withdrawal := models.Transaction{
+			AccountID:  fromAccountUUID,
+			Type:       utils.Withdrawal,
+			Amount:     amount,
+			Exchange:   exchange,
+			TransferID: transfer.ID,
+			TimeStamp:  timestamp,
+		}
+		deposit := models.Transaction{
+			AccountID:  toAccountUUID,
+			Type:       utils.Deposit,
+			Amount:     depositAmount,
+			Exchange:   exchange,
+			TransferID: transfer.ID,
+			TimeStamp:  timestamp,
+		}
+
+		// Apply the rules of both account types
+		withdrawalFee, err := services.Rules.enforce(store, fromAccount, withdrawal)
+		if err != nil {
+			return err
+		}
+		depositFee, err := services.Rules.enforce(store, toAccount, deposit)
+		if err != nil {
+			return err
+		}
+
+		// Charge the source account the transfer fee of the schedule
+		scheduled, err := services.Fees.Quote(models.FeeOnTransfer, fromAccount, amount)
+		if err != nil {
+			return err
+		}
+		if withdrawalFee, err = withdrawalFee.Add(scheduled); err != nil {
+			return err
+		}
+
+		// Store the transfer before the legs referencing it
+		if err := store.CreateTransfer(transfer); err != nil {
+			return err
+		}
+
+		// Create withdrawal transaction from source account
+		withdrawal, err = record(store, withdrawal)
+		if err != nil {
+			return err
+		}
+
+		// Create deposit transaction to destination account
+		deposit, err = record(store, deposit)
+		if err != nil {
+			return err
+		}
+
+		// Post both legs as one entry. A conversion goes through the
+		// exchange account so that each currency balances on its own
+		entry := models.JournalEntry{Description: "transfer", TimeStamp: timestamp}
+		if exchange == nil {
+			entry.Postings = []models.Posting{
+				debit(fromAccount.ID, withdrawal.ID, fromAccount.Currency, amount),
+				credit(toAccount.ID, deposit.ID, toAccount.Currency, depositAmount),
+			}
+		} else {
+			entry.Postings = []models.Posting{
+				debit(fromAccount.ID, withdrawal.ID, fromAccount.Currency, amount),
+				credit(models.ExchangeAccountID, withdrawal.ID, fromAccount.Currency, amount),
+				debit(models.ExchangeAccountID, deposit.ID, toAccount.Currency, depositAmount),
+				credit(toAccount.ID, deposit.ID, toAccount.Currency, depositAmount),
+			}
+		}
+		if _, err = post(store, entry); err != nil {
+			return err
+		}
+
+		// Charge the fees of both legs
+		transfer.Withdrawal, transfer.Deposit, transfer.Fees = withdrawal, deposit, []models.Transaction{}
+		withdrawalCharge, err := charge(store, fromAccount, withdrawalFee, withdrawal)
+		if err != nil {
+			return err
+		}
+		depositCharge, err := charge(store, toAccount, depositFee, deposit)
+		if err != nil {
+			return err
+		}
+		for _, charged := range []models.Transaction{withdrawalCharge, depositCharge} {
+			if charged.ID != uuid.Nil {
+				transfer.Fees = append(transfer.Fees, charged)
+			}
+		}
+
+		// Record the completed transfer after the money its legs moved
+		return emit(store, fromAccount, models.Event{
+			Type:           utils.EventTransferCompleted,
+			CounterpartyID: toAccount.ID,
+			ReferenceID:    transfer.ID,
+			Amount:         amount,
+			TimeStamp:      timestamp,
+		})
 	})
+	if err != nil {
+		return models.Transfer{}, err
+	}
 
+	return transfer, nil
+}
+
+// settle applies the rules and fee schedule of the account to a deposit or
+// withdrawal, records it, posts it against the external account and charges
+// its fees. It must be called inside a unit of work
+func (service *TransactionService) settle(store storage.Storage, account models.Account, transaction models.Transaction) (models.Transaction, error) {
+	// Apply the rules of the account type
+	fee, err := service.Rules.enforce(store, account, transaction)
 	if err != nil {
-		return err
+		return models.Transaction{}, err
+	}
+
+	// Add the fee of the schedule
+	scheduled, err := service.Fees.Quote(transaction.Type.String(), account, transaction.Amount)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if fee, err = fee.Add(scheduled); err != nil {
+		return models.Transaction{}, err
+	}
+
+	transaction, err = record(store, transaction)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	// Post the movement against the external account
+	amount := transaction.Amount
+	entry := models.JournalEntry{Description: transaction.Type.String(), TimeStamp: transaction.TimeStamp}
+	if transaction.Type == utils.Deposit {
+		entry.Postings = []models.Posting{
+			debit(models.ExternalAccountID, transaction.ID, account.Currency, amount),
+			credit(account.ID, transaction.ID, account.Currency, amount),
+		}
+	} else {
+		entry.Postings = []models.Posting{
+			debit(account.ID, transaction.ID, account.Currency, amount),
+			credit(models.ExternalAccountID, transaction.ID, account.Currency, amount),
+		}
+	}
+	if _, err = post(store, entry); err != nil {
+		return models.Transaction{}, err
+	}
+	if _, err = charge(store, account, fee, transaction); err != nil {
+		return models.Transaction{}, err
+	}
+	return transaction, nil
+}
+
+// parseAmount converts a requested amount into minor units of the given
+// currency and requires it to be positive
+func parseAmount(value money.Decimal, currencyCode string) (money.Money, error) {
+	currency, err := money.LookupCurrency(currencyCode)
+	if err != nil {
+		return money.Money{}, err
+	}
+	amount, err := currency.Parse(value)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if !amount.IsPositive() {
+		return money.Money{}, utils.ErrInvalidAmount
+	}
+	return amount, nil
+}
+
+// parseBound converts an optional amount bound of a query into minor units
+// of the given currency
+func parseBound(value money.Decimal, currencyCode string) (*money.Money, error) {
+	if value == "" {
+		return nil, nil
+	}
+	currency, err := money.LookupCurrency(currencyCode)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := currency.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
+}
+
+// convert turns an amount in one currency into the other currency using
+// the exchange rate table. It returns no exchange record for amounts that
+// need no conversion
+func (services *TransactionService) convert(amount money.Money, from string, to string) (money.Money, *models.Exchange, error) {
+	if from == to {
+		return amount, nil, nil
+	}
+
+	// Find the rate and the minor unit of the destination currency
+	rate, err := services.Exchange.Find(from, to)
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+	currency, err := money.LookupCurrency(to)
+	if err != nil {
+		return money.Money{}, nil, err
 	}
 
-	// Create deposit transaction to destination account
-	_, err = services.Create(request.ToAccountID, requests.TransactionRequest{
-		Type:   utils.Deposit.String(),
-		Amount: request.Amount,
+	// Convert, rejecting amounts too small to be worth a minor unit
+	converted, err := rate.Rate.Convert(amount, currency.Scale)
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+	if !converted.IsPositive() {
+		return money.Money{}, nil, utils.ErrInvalidAmount
+	}
+
+	return converted, &models.Exchange{
+		Rate:                rate.Rate,
+		SourceCurrency:      from,
+		SourceAmount:        amount,
+		DestinationCurrency: to,
+		DestinationAmount:   converted,
+	}, nil
+}
+
+// record checks that the account of a new transaction accepts and can cover
+// it and stores it with a fresh ID in the account currency. The balance
+// itself is changed by posting the matching journal entry. It must be called
+// inside a unit of work
+func record(store storage.Storage, transaction models.Transaction) (models.Transaction, error) {
+	// Find the account in storage
+	account, err := store.FindAccount(transaction.AccountID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	// Closed accounts accept nothing and frozen accounts only receive money,
+	// so they are not debited by withdrawals, fees or reversals either
+	if account.Status == utils.Closed {
+		return models.Transaction{}, utils.ErrAccountClosed
+	}
+	if account.Status == utils.Frozen && transaction.Type.IsDebit() {
+		return models.Transaction{}, utils.ErrAccountFrozen
+	}
+
+	// Free the funds of expired holds before checking what is available
+	account, err = expireHolds(store, account, transaction.TimeStamp)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	// Check the balance, overdraft and holds for withdrawals and fees
+	if transaction.Type.IsDebit() {
+		available, err := account.Available()
+		if err != nil {
+			return models.Transaction{}, err
+		}
+		if available.Cmp(transaction.Amount) < 0 {
+			return models.Transaction{}, utils.ErrInsufficientFunds
+		}
+	}
+
+	// Give the transaction a unique ID and the account currency
+	transaction.ID = uuid.New()
+	transaction.Currency = account.Currency
+
+	// Add transaction to storage
+	if err := store.CreateTransaction(transaction); err != nil {
+		return models.Transaction{}, err
+	}
+
+	// Record the money moving in or out of the account
+	event := models.Event{Type: utils.EventFundsDeposited, ReferenceID: transaction.ID, Amount: transaction.Amount, TimeStamp: transaction.TimeStamp}
+	if transaction.Type.IsDebit() {
+		event.Type = utils.EventFundsWithdrawn
+	}
+	if err := emit(store, account, event); err != nil {
+		return models.Transaction{}, err
+	}
+	return transaction, nil
+}
+
+// charge records a fee taken from an account for the origin transaction, as
+// part of the same transfer if any, and posts it to the fees account. Zero
+// fees, and fees of frozen accounts which cannot be debited but still
+// receive money, are not charged and return a zero transaction. It must be
+// called inside a unit of work
+func charge(store storage.Storage, account models.Account, fee money.Money, origin models.Transaction) (models.Transaction, error) {
+	if !fee.IsPositive() || account.Status == utils.Frozen {
+		return models.Transaction{}, nil
+	}
+
+	transaction, err := record(store, models.Transaction{
+		AccountID:  account.ID,
+		Type:       utils.Fee,
+		Amount:     fee,
+		RelatedID:  origin.ID,
+		TransferID: origin.TransferID,
+		TimeStamp:  origin.TimeStamp,
 	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
 
-	// If deposit fails, rollback the withdrawal by removing the last transaction
+	_, err = post(store, models.JournalEntry{
+		Description: "fee",
+		Postings: []models.Posting{
+			debit(account.ID, transaction.ID, account.Currency, fee),
+			credit(models.FeesAccountID, transaction.ID, account.Currency, fee),
+		},
+		TimeStamp: origin.TimeStamp,
+	})
 	if err != nil {
-		services.Storage.Mutex.Lock()
-		services.Storage.Transactions = services.Storage.Transactions[:len(services.Storage.Transactions)-1]
-		services.Storage.Mutex.Unlock()
-		return err
+		return models.Transaction{}, err
 	}
-	return nil
+	return transaction, nil
 }
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/requests"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+
+	"github.com/google/uuid"
+)
+
+// ReadTransfer retrieves a transfer with its legs and fees
+func (service *TransactionService) ReadTransfer(transferId string) (models.Transfer, error) {
+	// Validate and parse the transfer UUID
+	parsedTransferUUID, err := uuid.Parse(transferId)
+	if err != nil {
+		return models.Transfer{}, utils.ErrInvalidUUID
+	}
+
+	// Read the transfer in a view of both its accounts, which own its legs
+	// and fees
+	var transfer models.Transfer
+	err = viewResolved(service.Storage, []uuid.UUID{parsedTransferUUID}, func(store storage.Storage) ([]uuid.UUID, error) {
+		transfer, err = loadTransfer(store, parsedTransferUUID)
+		return []uuid.UUID{transfer.FromAccountID, transfer.ToAccountID}, err
+	})
+	if err != nil {
+		return models.Transfer{}, err
+	}
+
+	return transfer, nil
+}
+
+// SearchTransfers retrieves one page of the transfers, optionally only those
+// from or to an account, sorted by timestamp
+func (service *TransactionService) SearchTransfers(query requests.TransferQuery) (models.TransferPage, error) {
+	// Parse the account and the cursor
+	filter := models.TransferFilter{
+		Descending: query.Sort == "desc",
+		Limit:      pageLimit(query.Limit),
+	}
+	if query.AccountID != "" {
+		accountUUID, err := uuid.Parse(query.AccountID)
+		if err != nil {
+			return models.TransferPage{}, utils.ErrInvalidUUID
+		}
+		filter.AccountID = accountUUID
+	}
+	cursor, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return models.TransferPage{}, err
+	}
+	filter.After = cursor
+
+	// Units of work on a transfer lock both of its accounts, so viewing the
+	// account of the filter keeps its transfers and their legs still
+	var page models.TransferPage
+	err = service.Storage.View([]uuid.UUID{filter.AccountID}, func(store storage.Storage) error {
+		// Fetch one transfer more than the page to learn if another follows
+		limit := filter.Limit
+		filter.Limit++
+		transfers, err := store.SearchTransfers(filter)
+		if err != nil {
+			return err
+		}
+		if len(transfers) > limit {
+			transfers = transfers[:limit]
+			last := transfers[limit-1]
+			page.NextCursor = encodeCursor(models.Cursor{Value: last.TimeStamp.UnixNano(), ID: last.ID})
+		}
+
+		// Fill in the legs and fees of every transfer
+		page.Transfers = []models.Transfer{}
+		for _, transfer := range transfers {
+			loaded, err := loadTransfer(store, transfer.ID)
+			if err != nil {
+				return err
+			}
+			page.Transfers = append(page.Transfers, loaded)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.TransferPage{}, err
+	}
+
+	return page, nil
+}
+
+// loadTransfer finds a transfer and fills in its legs and fees. It must be
+// called inside a unit of work
+func loadTransfer(store storage.Storage, id uuid.UUID) (models.Transfer, error) {
+	transfer, err := store.FindTransfer(id)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+
+	transactions, err := store.FindTransferTransactions(id)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	transfer.Fees = []models.Transaction{}
+	for _, transaction := range transactions {
+		switch transaction.Type {
+		case utils.Withdrawal:
+			transfer.Withdrawal = transaction
+		case utils.Deposit:
+			transfer.Deposit = transaction
+		case utils.Fee:
+			transfer.Fees = append(transfer.Fees, transaction)
+		}
+	}
+	return transfer, nil
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"bank-account-manager/models"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// index files the positions of records in their slice under the IDs they
+// are looked up by, in ascending order, which is the order the records were
+// added in. Records are never filed under uuid.Nil
+type index map[uuid.UUID][]int
+
+// add files position under each of the keys. New records come last, so
+// this is an append but for undone removals
+func (idx index) add(position int, keys ...uuid.UUID) {
+	for _, key := range keys {
+		if key == uuid.Nil {
+			continue
+		}
+		if at, found := slices.BinarySearch(idx[key], position); !found {
+			idx[key] = slices.Insert(idx[key], at, position)
+		}
+	}
+}
+
+// remove takes position out of the positions of each of the keys
+func (idx index) remove(position int, keys ...uuid.UUID) {
+	for _, key := range keys {
+		at, found := slices.BinarySearch(idx[key], position)
+		if !found {
+			continue
+		}
+		if len(idx[key]) == 1 {
+			delete(idx, key)
+		} else {
+			idx[key] = slices.Delete(idx[key], at, at+1)
+		}
+	}
+}
+
+// positionsOf maps the ID of every live record to its position in the slice
+func positionsOf[T any](records []T, id func(record T) uuid.UUID) map[uuid.UUID]int {
+	positions := make(map[uuid.UUID]int, len(records))
+	for position, record := range records {
+		if id(record) != uuid.Nil {
+			positions[id(record)] = position
+		}
+	}
+	return positions
+}
+
+// reindex rebuilds every index from the record slices, as needed once they
+// were loaded or changed in bulk
+func (memory *Memory) reindex() {
+	memory.accountIndex = positionsOf(memory.accounts, func(account models.Account) uuid.UUID { return account.ID })
+	memory.customerAccounts = index{}
+	for position, account := range memory.accounts {
+		memory.customerAccounts.add(position, account.CustomerID)
+	}
+	memory.customerIndex = positionsOf(memory.customers, func(customer models.Customer) uuid.UUID { return customer.ID })
+	memory.accountHolders = index{}
+	memory.customerHoldings = index{}
+	for position, holder := range memory.holders {
+		memory.accountHolders.add(position, holder.AccountID)
+		memory.customerHoldings.add(position, holder.CustomerID)
+	}
+
+	memory.transactionIndex = positionsOf(memory.transactions, func(transaction models.Transaction) uuid.UUID { return transaction.ID })
+	memory.accountTransactions = index{}
+	memory.relatedTransactions = index{}
+	memory.transferTransactions = index{}
+	for position, transaction := range memory.transactions {
+		memory.accountTransactions.add(position, transaction.AccountID)
+		memory.relatedTransactions.add(position, transaction.RelatedID)
+		memory.transferTransactions.add(position, transaction.TransferID)
+	}
+
+	memory.transferIndex = positionsOf(memory.transfers, func(transfer models.Transfer) uuid.UUID { return transfer.ID })
+	memory.accountTransfers = index{}
+	for position, transfer := range memory.transfers {
+		memory.accountTransfers.add(position, transfer.FromAccountID, transfer.ToAccountID)
+	}
+
+	memory.holdIndex = positionsOf(memory.holds, func(hold models.Hold) uuid.UUID { return hold.ID })
+	memory.accountHolds = index{}
+	for position, hold := range memory.holds {
+		memory.accountHolds.add(position, hold.AccountID)
+	}
+
+	memory.accountAccruals = index{}
+	for position, accrual := range memory.accruals {
+		memory.accountAccruals.add(position, accrual.AccountID)
+	}
+	memory.accountEvents = index{}
+	for position, event := range memory.events {
+		memory.accountEvents.add(position, event.AccountID, event.CounterpartyID)
+	}
+	memory.accountCheckpoints = index{}
+	for position, checkpoint := range memory.checkpoints {
+		memory.accountCheckpoints.add(position, checkpoint.AccountID)
+	}
+}
+
+// addToIndex files the position of a record under its keys, remembering
+// how to undo it
+func (tx *memoryTx) addToIndex(idx index, position int, keys ...uuid.UUID) {
+	idx.add(position, keys...)
+	tx.undo = append(tx.undo, func() { idx.remove(position, keys...) })
+}
+
+// removeFromIndex takes the position of a record out of its keys,
+// remembering how to undo it
+func (tx *memoryTx) removeFromIndex(idx index, position int, keys ...uuid.UUID) {
+	idx.remove(position, keys...)
+	tx.undo = append(tx.undo, func() { idx.add(position, keys...) })
+}
+
+// moveInIndex refiles the position of a changed record from its old key to
+// its new one, remembering how to undo it
+func (tx *memoryTx) moveInIndex(idx index, position int, from uuid.UUID, to uuid.UUID) {
+	if from != to {
+		tx.removeFromIndex(idx, position, from)
+		tx.addToIndex(idx, position, to)
+	}
+}
+
+// setPosition maps the ID of a record to its position, or unmaps it when
+// position is negative, remembering how to undo it
+func (tx *memoryTx) setPosition(positions map[uuid.UUID]int, id uuid.UUID, position int) {
+	previous, existed := positions[id]
+	if position < 0 {
+		delete(positions, id)
+	} else {
+		positions[id] = position
+	}
+	tx.undo = append(tx.undo, func() {
+		if existed {
+			positions[id] = previous
+		} else {
+			delete(positions, id)
+		}
+	})
+}
+
+// appendRecord adds a record to the end of a slice and returns its
+// position, remembering to undo it by leaving a zero record in its place.
+// Units of work run concurrently, so later records may follow it by then
+// and positions must not shift
+func appendRecord[T any](tx *memoryTx, records *[]T, record T) int {
+	position := len(*records)
+	*records = append(*records, record)
+	tx.undo = append(tx.undo, func() {
+		var zero T
+		(*records)[position] = zero
+	})
+	return position
+}
+
+// replaceRecord stores a record at a position of a slice, remembering how to
+// restore the previous one
+func replaceRecord[T any](tx *memoryTx, records *[]T, position int, record T) {
+	previous := (*records)[position]
+	(*records)[position] = record
+	tx.undo = append(tx.undo, func() { (*records)[position] = previous })
+}
+
+// lookup returns the records at the given positions in order
+func lookup[T any](records []T, positions []int) []T {
+	found := make([]T, 0, len(positions))
+	for _, position := range positions {
+		found = append(found, records[position])
+	}
+	return found
+}
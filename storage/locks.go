@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// gateMode is how a unit of work holds the gate of a lockTable
+type gateMode int
+
+const (
+	readingIDs  gateMode = iota // Reads the records of some IDs
+	changingIDs                 // Changes the records of some IDs
+	readingAll                  // Reads any record
+	changingAll                 // Changes any record
+)
+
+// gateConflicts tells which modes of the gate cannot be held together.
+// Units on IDs share the gate and leave conflicts to the locks of the IDs,
+// while units on every record wait for the units changing records to end
+var gateConflicts = [4][4]bool{
+	readingIDs:  {changingAll: true},
+	changingIDs: {readingAll: true, changingAll: true},
+	readingAll:  {changingIDs: true, changingAll: true},
+	changingAll: {true, true, true, true},
+}
+
+// lockTable isolates concurrent units of work. Units take the gate first
+// and then the locks of the IDs they read or change, in ascending ID order
+// so that units locking the same IDs, such as both accounts of a transfer,
+// can never wait on each other in a cycle
+type lockTable struct {
+	mutex   sync.Mutex
+	changed *sync.Cond
+	held    [4]int                // Units holding the gate in every mode
+	waiting [4]int                // Units waiting for the gate in every mode
+	ids     map[uuid.UUID]*idLock // Locks of the IDs units hold or wait for
+}
+
+// idLock is the lock of one ID, kept in its table while units use it
+type idLock struct {
+	sync.RWMutex
+	users int // Units holding or waiting for the lock
+}
+
+// createLockTable initializes a lockTable with nothing locked
+func createLockTable() *lockTable {
+	locks := &lockTable{ids: map[uuid.UUID]*idLock{}}
+	locks.changed = sync.NewCond(&locks.mutex)
+	return locks
+}
+
+// lock takes the gate in the given mode and the locks of the IDs, shared
+// when reading and exclusive when changing. uuid.Nil is ignored. It returns
+// the function releasing everything it took
+func (locks *lockTable) lock(mode gateMode, ids ...uuid.UUID) func() {
+	// Lock every ID once, in ascending order
+	ids = slices.DeleteFunc(slices.Clone(ids), func(id uuid.UUID) bool { return id == uuid.Nil })
+	slices.SortFunc(ids, func(left, right uuid.UUID) int { return bytes.Compare(left[:], right[:]) })
+	ids = slices.Compact(ids)
+	held := locks.enter(mode, ids)
+	for _, lock := range held {
+		if mode == changingIDs {
+			lock.Lock()
+		} else {
+			lock.RLock()
+		}
+	}
+
+	return func() {
+		for index := len(held) - 1; index >= 0; index-- {
+			if mode == changingIDs {
+				held[index].Unlock()
+			} else {
+				held[index].RUnlock()
+			}
+		}
+		locks.leave(mode, ids)
+	}
+}
+
+// use returns the locks of the IDs, adding those no unit uses yet. It must
+// be called with the mutex of the table locked
+func (locks *lockTable) use(ids []uuid.UUID) []*idLock {
+	used := make([]*idLock, len(ids))
+	for index, id := range ids {
+		lock, ok := locks.ids[id]
+		if !ok {
+			lock = &idLock{}
+			locks.ids[id] = lock
+		}
+		lock.users++
+		used[index] = lock
+	}
+	return used
+}
+
+// release drops the locks of the IDs that no unit uses anymore. It must be
+// called with the mutex of the table locked
+func (locks *lockTable) release(ids []uuid.UUID) {
+	for _, id := range ids {
+		lock := locks.ids[id]
+		if lock.users--; lock.users == 0 {
+			delete(locks.ids, id)
+		}
+	}
+}
+
+// enter waits until the gate can be held in the given mode and returns the
+// locks of the IDs, taking the mutex of the table once for both. Units on
+// IDs also wait behind units on every record that are waiting, so that a
+// steady stream of the former cannot starve the latter
+func (locks *lockTable) enter(mode gateMode, ids []uuid.UUID) []*idLock {
+	locks.mutex.Lock()
+	defer locks.mutex.Unlock()
+
+	if locks.blocked(mode) {
+		locks.waiting[mode]++
+		for locks.blocked(mode) {
+			locks.changed.Wait()
+		}
+		locks.waiting[mode]--
+	}
+	locks.held[mode]++
+	return locks.use(ids)
+}
+
+// blocked reports whether the gate cannot be held in the given mode yet. It
+// must be called with the mutex of the table locked
+func (locks *lockTable) blocked(mode gateMode) bool {
+	for other, conflicts := range gateConflicts[mode] {
+		if !conflicts {
+			continue
+		}
+		if locks.held[other] > 0 || (mode < readingAll && locks.waiting[other] > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// leave releases the gate held in the given mode and the locks of the IDs,
+// only waking the units waiting for the gate if there are any
+func (locks *lockTable) leave(mode gateMode, ids []uuid.UUID) {
+	locks.mutex.Lock()
+	defer locks.mutex.Unlock()
+
+	locks.release(ids)
+	locks.held[mode]--
+	if locks.waiting != [4]int{} {
+		locks.changed.Broadcast()
+	}
+}
+
+// KeyLockID returns the ID that units of work lock to claim or change the
+// record of an idempotency key
+func KeyLockID(key string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("idempotency:"+key))
+}
@@ -0,0 +1,1322 @@
+package storage
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"bytes"
+	"cmp"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Memory represents an in-memory data store for accounts and transactions
+// with thread-safe operations through mutex locking. Records are kept in
+// slices in the order they were added and indexed by the IDs they are
+// looked up by, so lookups do not depend on how many records are stored.
+// Units of work lock the IDs they touch, so units on unrelated accounts run
+// concurrently. Every kind of record has a guard of its own that operations
+// only hold while they read or change the slice and indexes of that kind,
+// so units only wait on each other for the length of an append. Records
+// are never moved once added, and those removed or rolled back leave a zero
+// record behind
+type Memory struct {
+	accounts     []models.Account                    // Slice containing all bank accounts
+	customers    []models.Customer                   // Slice containing all customers
+	holders      []models.AccountHolder              // Slice containing all account holders
+	transactions []models.Transaction                // Slice containing all transactions
+	journal      []models.JournalEntry               // Slice containing all journal entries
+	accruals     []models.InterestAccrual            // Slice containing all interest accruals
+	holds        []models.Hold                       // Slice containing all holds
+	transfers    []models.Transfer                   // Slice containing all transfers
+	events       []models.Event                      // Slice containing all events in sequence order
+	checkpoints  []models.AccountBalance             // Slice containing all balance checkpoints
+	idempotency  map[string]models.IdempotencyRecord // Idempotency records keyed by key
+	guards       [recordKinds]sync.RWMutex           // Guards of the slice and indexes of every kind of record
+	locks        *lockTable                          // Locks isolating concurrent units of work
+	wal          *writeAheadLog                      // Log making committed work durable, nil to keep none
+
+	accountIndex         map[uuid.UUID]int // Position of every account by ID
+	customerIndex        map[uuid.UUID]int // Position of every customer by ID
+	transactionIndex     map[uuid.UUID]int // Position of every transaction by ID
+	transferIndex        map[uuid.UUID]int // Position of every transfer by ID
+	holdIndex            map[uuid.UUID]int // Position of every hold by ID
+	customerAccounts     index             // Accounts of every customer
+	accountHolders       index             // Holders of every account
+	customerHoldings     index             // Holdings of every customer
+	accountTransactions  index             // Transactions of every account
+	relatedTransactions  index             // Fees and reversals of every transaction
+	transferTransactions index             // Legs and fees of every transfer
+	accountTransfers     index             // Transfers from or to every account
+	accountHolds         index             // Holds of every account
+	accountAccruals      index             // Interest accruals of every account
+	accountEvents        index             // Events of or transferring to every account
+	accountCheckpoints   index             // Balance checkpoints of every account
+}
+
+// recordKind is a kind of record of a Memory store, whose slice and indexes
+// share one guard
+type recordKind int
+
+const (
+	accountRecords recordKind = iota
+	customerRecords
+	holderRecords
+	transactionRecords
+	journalRecords
+	accrualRecords
+	holdRecords
+	transferRecords
+	eventRecords
+	checkpointRecords
+	idempotencyRecords
+	recordKinds // Number of kinds of records
+)
+
+// memoryTx is the view of a Memory store handed to a unit of work. It
+// relies on the unit holding the locks of what it touches, guards the
+// records of a kind for the duration of every operation on them and records
+// how to undo every change, and the changes themselves for the write-ahead
+// log
+type memoryTx struct {
+	memory     *Memory
+	undo       []func()
+	operations []walOperation
+}
+
+// Create initializes and returns a new in-memory Storage with empty
+// accounts, customers, holders, transactions, journal, accrual, hold,
+// transfer, event and checkpoint slices, no idempotency records, empty
+// indexes and nothing locked
+func Create() *Memory {
+	accounts := []models.Account{}
+	customers := []models.Customer{}
+	holders := []models.AccountHolder{}
+	transactions := []models.Transaction{}
+	journal := []models.JournalEntry{}
+	accruals := []models.InterestAccrual{}
+	holds := []models.Hold{}
+	transfers := []models.Transfer{}
+	events := []models.Event{}
+	checkpoints := []models.AccountBalance{}
+	idempotency := map[string]models.IdempotencyRecord{}
+
+	memory := &Memory{
+		accounts:     accounts,
+		customers:    customers,
+		holders:      holders,
+		transactions: transactions,
+		journal:      journal,
+		accruals:     accruals,
+		holds:        holds,
+		transfers:    transfers,
+		events:       events,
+		checkpoints:  checkpoints,
+		idempotency:  idempotency,
+		locks:        createLockTable(),
+	}
+	memory.reindex()
+	return memory
+}
+
+// Atomic runs fn with the whole store to itself and rolls back every change
+// made by fn if it returns an error. With a write-ahead log the changes are
+// only committed once they reached the disk
+func (memory *Memory) Atomic(fn func(store Storage) error) error {
+	return memory.run(changingAll, nil, fn)
+}
+
+// Lock runs fn like Atomic but only isolated from the units of work on the
+// given IDs, so units on other IDs run alongside it. fn may only change the
+// records owned by those IDs or by none, like the journal
+func (memory *Memory) Lock(ids []uuid.UUID, fn func(store Storage) error) error {
+	return memory.run(changingIDs, ids, fn)
+}
+
+// View runs fn as a unit of work reading the records owned by the given IDs
+// while no unit changes them. Without IDs it reads a consistent view of the
+// whole store, waiting for every unit changing records to commit
+func (memory *Memory) View(ids []uuid.UUID, fn func(store Storage) error) error {
+	if slices.ContainsFunc(ids, func(id uuid.UUID) bool { return id != uuid.Nil }) {
+		return memory.run(readingIDs, ids, fn)
+	}
+	return memory.run(readingAll, nil, fn)
+}
+
+// run takes the locks of a unit of work in the given mode and commits it
+func (memory *Memory) run(mode gateMode, ids []uuid.UUID, fn func(store Storage) error) error {
+	unlock := memory.locks.lock(mode, ids...)
+	compact, err := memory.commit(fn)
+	unlock()
+	if err != nil || !compact {
+		return err
+	}
+
+	// Compact the log once it grew long, with every other unit shut out.
+	// The work is committed either way, so a failure only leaves a longer
+	// log to replay
+	defer memory.locks.lock(changingAll)()
+	if memory.wal != nil && memory.wal.due() {
+		if err := memory.wal.compact(memory); err != nil {
+			log.Printf("Journal snapshot error: %v", err)
+		}
+	}
+	return nil
+}
+
+// commit runs fn and rolls back every change made by fn if it returns an
+// error or the changes cannot be made durable. It reports whether the log
+// is due for compaction and must be called with the locks of the unit taken
+func (memory *Memory) commit(fn func(store Storage) error) (bool, error) {
+	tx := &memoryTx{memory: memory}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return false, err
+	}
+	if memory.wal == nil || len(tx.operations) == 0 {
+		return false, nil
+	}
+
+	// Make the changes durable before anyone can see them
+	if err := memory.wal.append(tx.operations); err != nil {
+		tx.rollback()
+		return false, err
+	}
+	return memory.wal.due(), nil
+}
+
+// viewOwned runs read in a view of the IDs owning the records it reads,
+// such as the account of a transaction, which read returns and which are
+// only known once the records were read. read runs first outside any unit
+// of work to find them, then again in a view of the IDs it returned until
+// they stop changing, so that every record it read last was committed
+func (memory *Memory) viewOwned(read func(store Storage) ([]uuid.UUID, error)) error {
+	ids, err := read(&memoryTx{memory: memory})
+	for err == nil && len(ids) > 0 {
+		var owners []uuid.UUID
+		err = memory.View(ids, func(store Storage) (err error) {
+			owners, err = read(store)
+			return err
+		})
+		if slices.Equal(owners, ids) {
+			break
+		}
+		ids = owners
+	}
+	return err
+}
+
+// transactionOwners returns the accounts of the transactions in order
+func transactionOwners(transactions []models.Transaction) []uuid.UUID {
+	owners := make([]uuid.UUID, 0, len(transactions))
+	for _, transaction := range transactions {
+		owners = append(owners, transaction.AccountID)
+	}
+	return owners
+}
+
+// reading guards the records of a kind for a read and returns the function
+// releasing them
+func (memory *Memory) reading(kind recordKind) func() {
+	memory.guards[kind].RLock()
+	return memory.guards[kind].RUnlock
+}
+
+// writing guards the records of a kind for a change and returns the
+// function releasing them
+func (memory *Memory) writing(kind recordKind) func() {
+	memory.guards[kind].Lock()
+	return memory.guards[kind].Unlock
+}
+
+// writingAll guards the records of every kind for a change, in a fixed
+// order, and returns the function releasing them
+func (memory *Memory) writingAll() func() {
+	for kind := range memory.guards {
+		memory.guards[kind].Lock()
+	}
+	return func() {
+		for kind := range memory.guards {
+			memory.guards[kind].Unlock()
+		}
+	}
+}
+
+func (memory *Memory) CreateAccount(account models.Account) error {
+	return memory.Lock([]uuid.UUID{account.ID, account.CustomerID}, func(store Storage) error {
+		return store.CreateAccount(account)
+	})
+}
+
+func (memory *Memory) FindAccount(id uuid.UUID) (account models.Account, err error) {
+	err = memory.View([]uuid.UUID{id}, func(store Storage) error {
+		account, err = store.FindAccount(id)
+		return err
+	})
+	return account, err
+}
+
+func (memory *Memory) FindAccounts() (accounts []models.Account, err error) {
+	err = memory.View(nil, func(store Storage) error {
+		accounts, err = store.FindAccounts()
+		return err
+	})
+	return accounts, err
+}
+
+func (memory *Memory) SearchAccounts(filter models.AccountFilter) (accounts []models.Account, err error) {
+	err = memory.View(nil, func(store Storage) error {
+		accounts, err = store.SearchAccounts(filter)
+		return err
+	})
+	return accounts, err
+}
+
+func (memory *Memory) UpdateAccount(account models.Account) error {
+	return memory.Lock([]uuid.UUID{account.ID, account.CustomerID}, func(store Storage) error {
+		return store.UpdateAccount(account)
+	})
+}
+
+func (memory *Memory) FindCustomerAccounts(customerID uuid.UUID) (accounts []models.Account, err error) {
+	err = memory.View([]uuid.UUID{customerID}, func(store Storage) error {
+		accounts, err = store.FindCustomerAccounts(customerID)
+		return err
+	})
+	return accounts, err
+}
+
+func (memory *Memory) FindAccountHolders(accountID uuid.UUID) (holders []models.AccountHolder, err error) {
+	err = memory.View([]uuid.UUID{accountID}, func(store Storage) error {
+		holders, err = store.FindAccountHolders(accountID)
+		return err
+	})
+	return holders, err
+}
+
+func (memory *Memory) FindCustomerHoldings(customerID uuid.UUID) (holders []models.AccountHolder, err error) {
+	err = memory.View([]uuid.UUID{customerID}, func(store Storage) error {
+		holders, err = store.FindCustomerHoldings(customerID)
+		return err
+	})
+	return holders, err
+}
+
+func (memory *Memory) SaveAccountHolder(holder models.AccountHolder) error {
+	return memory.Lock([]uuid.UUID{holder.AccountID, holder.CustomerID}, func(store Storage) error {
+		return store.SaveAccountHolder(holder)
+	})
+}
+
+func (memory *Memory) DeleteAccountHolder(accountID uuid.UUID, customerID uuid.UUID) error {
+	return memory.Lock([]uuid.UUID{accountID, customerID}, func(store Storage) error {
+		return store.DeleteAccountHolder(accountID, customerID)
+	})
+}
+
+func (memory *Memory) CreateCustomer(customer models.Customer) error {
+	return memory.Lock([]uuid.UUID{customer.ID}, func(store Storage) error {
+		return store.CreateCustomer(customer)
+	})
+}
+
+func (memory *Memory) FindCustomer(id uuid.UUID) (customer models.Customer, err error) {
+	err = memory.View([]uuid.UUID{id}, func(store Storage) error {
+		customer, err = store.FindCustomer(id)
+		return err
+	})
+	return customer, err
+}
+
+func (memory *Memory) FindCustomers() (customers []models.Customer, err error) {
+	err = memory.View(nil, func(store Storage) error {
+		customers, err = store.FindCustomers()
+		return err
+	})
+	return customers, err
+}
+
+func (memory *Memory) UpdateCustomer(customer models.Customer) error {
+	return memory.Lock([]uuid.UUID{customer.ID}, func(store Storage) error {
+		return store.UpdateCustomer(customer)
+	})
+}
+
+func (memory *Memory) DeleteCustomer(id uuid.UUID) error {
+	return memory.Lock([]uuid.UUID{id}, func(store Storage) error {
+		return store.DeleteCustomer(id)
+	})
+}
+
+func (memory *Memory) CreateTransaction(transaction models.Transaction) error {
+	return memory.Lock([]uuid.UUID{transaction.AccountID}, func(store Storage) error {
+		return store.CreateTransaction(transaction)
+	})
+}
+
+func (memory *Memory) FindTransaction(id uuid.UUID) (transaction models.Transaction, err error) {
+	err = memory.viewOwned(func(store Storage) ([]uuid.UUID, error) {
+		transaction, err = store.FindTransaction(id)
+		return []uuid.UUID{transaction.AccountID}, err
+	})
+	return transaction, err
+}
+
+func (memory *Memory) FindRelatedTransactions(id uuid.UUID) (transactions []models.Transaction, err error) {
+	err = memory.viewOwned(func(store Storage) ([]uuid.UUID, error) {
+		transactions, err = store.FindRelatedTransactions(id)
+		return transactionOwners(transactions), err
+	})
+	return transactions, err
+}
+
+func (memory *Memory) FindTransferTransactions(transferID uuid.UUID) (transactions []models.Transaction, err error) {
+	err = memory.viewOwned(func(store Storage) ([]uuid.UUID, error) {
+		transactions, err = store.FindTransferTransactions(transferID)
+		return transactionOwners(transactions), err
+	})
+	return transactions, err
+}
+
+func (memory *Memory) CreateTransfer(transfer models.Transfer) error {
+	return memory.Lock([]uuid.UUID{transfer.FromAccountID, transfer.ToAccountID}, func(store Storage) error {
+		return store.CreateTransfer(transfer)
+	})
+}
+
+func (memory *Memory) FindTransfer(id uuid.UUID) (transfer models.Transfer, err error) {
+	err = memory.viewOwned(func(store Storage) ([]uuid.UUID, error) {
+		transfer, err = store.FindTransfer(id)
+		return []uuid.UUID{transfer.FromAccountID, transfer.ToAccountID}, err
+	})
+	return transfer, err
+}
+
+func (memory *Memory) SearchTransfers(filter models.TransferFilter) (transfers []models.Transfer, err error) {
+	err = memory.View([]uuid.UUID{filter.AccountID}, func(store Storage) error {
+		transfers, err = store.SearchTransfers(filter)
+		return err
+	})
+	return transfers, err
+}
+
+func (memory *Memory) UpdateTransfer(transfer models.Transfer) error {
+	return memory.Lock([]uuid.UUID{transfer.FromAccountID, transfer.ToAccountID}, func(store Storage) error {
+		return store.UpdateTransfer(transfer)
+	})
+}
+
+func (memory *Memory) FindTransactions(accountID uuid.UUID) (transactions []models.Transaction, err error) {
+	err = memory.View([]uuid.UUID{accountID}, func(store Storage) error {
+		transactions, err = store.FindTransactions(accountID)
+		return err
+	})
+	return transactions, err
+}
+
+func (memory *Memory) SearchTransactions(filter models.TransactionFilter) (transactions []models.Transaction, err error) {
+	err = memory.View([]uuid.UUID{filter.AccountID}, func(store Storage) error {
+		transactions, err = store.SearchTransactions(filter)
+		return err
+	})
+	return transactions, err
+}
+
+func (memory *Memory) CreateJournalEntry(entry models.JournalEntry) error {
+	return memory.Lock(nil, func(store Storage) error {
+		return store.CreateJournalEntry(entry)
+	})
+}
+
+func (memory *Memory) FindLedgerBalances() (balances []models.LedgerBalance, err error) {
+	err = memory.View(nil, func(store Storage) error {
+		balances, err = store.FindLedgerBalances()
+		return err
+	})
+	return balances, err
+}
+
+func (memory *Memory) CreateHold(hold models.Hold) error {
+	return memory.Lock([]uuid.UUID{hold.AccountID}, func(store Storage) error {
+		return store.CreateHold(hold)
+	})
+}
+
+func (memory *Memory) FindHold(id uuid.UUID) (hold models.Hold, err error) {
+	err = memory.viewOwned(func(store Storage) ([]uuid.UUID, error) {
+		hold, err = store.FindHold(id)
+		return []uuid.UUID{hold.AccountID}, err
+	})
+	return hold, err
+}
+
+func (memory *Memory) FindAccountHolds(accountID uuid.UUID) (holds []models.Hold, err error) {
+	err = memory.View([]uuid.UUID{accountID}, func(store Storage) error {
+		holds, err = store.FindAccountHolds(accountID)
+		return err
+	})
+	return holds, err
+}
+
+func (memory *Memory) UpdateHold(hold models.Hold) error {
+	return memory.Lock([]uuid.UUID{hold.AccountID}, func(store Storage) error {
+		return store.UpdateHold(hold)
+	})
+}
+
+func (memory *Memory) AppendEvent(event models.Event) error {
+	return memory.Lock([]uuid.UUID{event.AccountID, event.CounterpartyID}, func(store Storage) error {
+		return store.AppendEvent(event)
+	})
+}
+
+func (memory *Memory) FindEvents(after int64, limit int) (events []models.Event, err error) {
+	err = memory.viewOwned(func(store Storage) ([]uuid.UUID, error) {
+		events, err = store.FindEvents(after, limit)
+		owners := []uuid.UUID{}
+		for _, event := range events {
+			owners = append(owners, event.AccountID, event.CounterpartyID)
+		}
+		return owners, err
+	})
+	return events, err
+}
+
+func (memory *Memory) FindAccountEvents(filter models.EventFilter) (events []models.Event, err error) {
+	err = memory.View([]uuid.UUID{filter.AccountID}, func(store Storage) error {
+		events, err = store.FindAccountEvents(filter)
+		return err
+	})
+	return events, err
+}
+
+func (memory *Memory) SaveBalanceCheckpoint(balance models.AccountBalance) error {
+	return memory.Lock([]uuid.UUID{balance.AccountID}, func(store Storage) error {
+		return store.SaveBalanceCheckpoint(balance)
+	})
+}
+
+func (memory *Memory) FindBalanceCheckpoint(accountID uuid.UUID, asOf time.Time) (balance models.AccountBalance, err error) {
+	err = memory.View([]uuid.UUID{accountID}, func(store Storage) error {
+		balance, err = store.FindBalanceCheckpoint(accountID, asOf)
+		return err
+	})
+	return balance, err
+}
+
+func (memory *Memory) SaveInterestAccrual(accrual models.InterestAccrual) error {
+	return memory.Lock([]uuid.UUID{accrual.AccountID}, func(store Storage) error {
+		return store.SaveInterestAccrual(accrual)
+	})
+}
+
+func (memory *Memory) FindInterestAccruals(accountID uuid.UUID, from time.Time, to time.Time) (accruals []models.InterestAccrual, err error) {
+	err = memory.View([]uuid.UUID{accountID}, func(store Storage) error {
+		accruals, err = store.FindInterestAccruals(accountID, from, to)
+		return err
+	})
+	return accruals, err
+}
+
+func (memory *Memory) FindLatestInterestAccrual(accountID uuid.UUID) (accrual models.InterestAccrual, err error) {
+	err = memory.View([]uuid.UUID{accountID}, func(store Storage) error {
+		accrual, err = store.FindLatestInterestAccrual(accountID)
+		return err
+	})
+	return accrual, err
+}
+
+func (memory *Memory) FindIdempotencyRecord(key string) (record models.IdempotencyRecord, err error) {
+	err = memory.View([]uuid.UUID{KeyLockID(key)}, func(store Storage) error {
+		record, err = store.FindIdempotencyRecord(key)
+		return err
+	})
+	return record, err
+}
+
+func (memory *Memory) SaveIdempotencyRecord(record models.IdempotencyRecord) error {
+	return memory.Lock([]uuid.UUID{KeyLockID(record.Key)}, func(store Storage) error {
+		return store.SaveIdempotencyRecord(record)
+	})
+}
+
+func (memory *Memory) DeleteIdempotencyRecord(key string) error {
+	return memory.Lock([]uuid.UUID{KeyLockID(key)}, func(store Storage) error {
+		return store.DeleteIdempotencyRecord(key)
+	})
+}
+
+// DeleteExpiredIdempotencyRecords finds the expired keys first and then
+// removes them while locking their IDs, so no unit of work claiming one of
+// the keys runs alongside. Keys claimed again in between are kept
+func (memory *Memory) DeleteExpiredIdempotencyRecords(now time.Time) error {
+	var keys []string
+	var ids []uuid.UUID
+	release := memory.reading(idempotencyRecords)
+	for key, record := range memory.idempotency {
+		if !record.ExpiresAt.After(now) {
+			keys = append(keys, key)
+			ids = append(ids, KeyLockID(key))
+		}
+	}
+	release()
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return memory.Lock(ids, func(store Storage) error {
+		for _, key := range keys {
+			record, err := store.FindIdempotencyRecord(key)
+			if err == utils.ErrIdempotencyKeyNotFound || (err == nil && record.ExpiresAt.After(now)) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := store.DeleteIdempotencyRecord(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Atomic joins the unit of work that is already running
+func (tx *memoryTx) Atomic(fn func(store Storage) error) error {
+	return fn(tx)
+}
+
+// Lock joins the unit of work that is already running
+func (tx *memoryTx) Lock(ids []uuid.UUID, fn func(store Storage) error) error {
+	return fn(tx)
+}
+
+// View joins the unit of work that is already running
+func (tx *memoryTx) View(ids []uuid.UUID, fn func(store Storage) error) error {
+	return fn(tx)
+}
+
+// rollback reverts the recorded changes in reverse order
+func (tx *memoryTx) rollback() {
+	defer tx.memory.writingAll()()
+	for index := len(tx.undo) - 1; index >= 0; index-- {
+		tx.undo[index]()
+	}
+	tx.undo = nil
+}
+
+// findAccount looks an account up by its UUID and returns its index in the
+// accounts slice. Returns -1 and ErrAccountNotFound if not found
+func (tx *memoryTx) findAccount(id uuid.UUID) (int, error) {
+	if index, ok := tx.memory.accountIndex[id]; ok {
+		return index, nil
+	}
+	return -1, utils.ErrAccountNotFound
+}
+
+func (tx *memoryTx) CreateAccount(account models.Account) error {
+	memory := tx.memory
+	defer memory.writing(accountRecords)()
+	position := appendRecord(tx, &memory.accounts, account)
+	tx.setPosition(memory.accountIndex, account.ID, position)
+	tx.addToIndex(memory.customerAccounts, position, account.CustomerID)
+	tx.record(walOperation{Kind: walCreateAccount, Account: &account})
+	return nil
+}
+
+func (tx *memoryTx) FindAccount(id uuid.UUID) (models.Account, error) {
+	defer tx.memory.reading(accountRecords)()
+	index, err := tx.findAccount(id)
+	if err != nil {
+		return models.Account{}, err
+	}
+	return tx.memory.accounts[index], nil
+}
+
+func (tx *memoryTx) FindAccounts() ([]models.Account, error) {
+	defer tx.memory.reading(accountRecords)()
+
+	// Copy the accounts so callers never share the backing slice
+	accounts := make([]models.Account, 0, len(tx.memory.accounts))
+	for _, account := range tx.memory.accounts {
+		if account.ID != uuid.Nil {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (tx *memoryTx) SearchAccounts(filter models.AccountFilter) ([]models.Account, error) {
+	defer tx.memory.reading(accountRecords)()
+
+	// Copy the accounts matching the filter
+	accounts := []models.Account{}
+	for _, account := range tx.memory.accounts {
+		if account.ID != uuid.Nil && matchesAccount(filter, account) {
+			accounts = append(accounts, account)
+		}
+	}
+
+	// Sort by the sort value, then ID, in the requested direction
+	sort.Slice(accounts, func(i, j int) bool {
+		return compareAccounts(filter, filter.SortValue(accounts[i]), accounts[i].ID, filter.SortValue(accounts[j]), accounts[j].ID) < 0
+	})
+
+	// Skip everything up to the cursor and cut the page
+	start := 0
+	if filter.After != nil {
+		start = sort.Search(len(accounts), func(index int) bool {
+			return compareAccounts(filter, filter.SortValue(accounts[index]), accounts[index].ID, filter.After.Value, filter.After.ID) > 0
+		})
+	}
+	end := min(start+filter.Limit, len(accounts))
+	return accounts[start:end], nil
+}
+
+// matchesAccount reports whether an account passes the owner, currency,
+// balance and status conditions of a filter
+func matchesAccount(filter models.AccountFilter, account models.Account) bool {
+	switch {
+	case filter.Owner != "" && !strings.Contains(strings.ToLower(account.Owner), strings.ToLower(filter.Owner)):
+		return false
+	case filter.Currency != "" && account.Currency != filter.Currency:
+		return false
+	case filter.MinBalance != nil && account.Balance.Cmp(*filter.MinBalance) < 0:
+		return false
+	case filter.MaxBalance != nil && account.Balance.Cmp(*filter.MaxBalance) > 0:
+		return false
+	case len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, account.Status):
+		return false
+	}
+	return true
+}
+
+// compareAccounts orders accounts by sort value, then ID, returning a
+// negative number when the left one comes first in the filter direction
+func compareAccounts(filter models.AccountFilter, leftValue int64, leftID uuid.UUID, rightValue int64, rightID uuid.UUID) int {
+	comparison := cmp.Compare(leftValue, rightValue)
+	if comparison == 0 {
+		comparison = bytes.Compare(leftID[:], rightID[:])
+	}
+	if filter.Descending {
+		return -comparison
+	}
+	return comparison
+}
+
+func (tx *memoryTx) UpdateAccount(account models.Account) error {
+	memory := tx.memory
+	defer memory.writing(accountRecords)()
+	index, err := tx.findAccount(account.ID)
+	if err != nil {
+		return err
+	}
+
+	previous := memory.accounts[index]
+	replaceRecord(tx, &memory.accounts, index, account)
+	tx.moveInIndex(memory.customerAccounts, index, previous.CustomerID, account.CustomerID)
+	tx.record(walOperation{Kind: walUpdateAccount, Account: &account})
+	return nil
+}
+
+func (tx *memoryTx) FindCustomerAccounts(customerID uuid.UUID) ([]models.Account, error) {
+	defer tx.memory.reading(accountRecords)()
+	return lookup(tx.memory.accounts, tx.memory.customerAccounts[customerID]), nil
+}
+
+func (tx *memoryTx) FindAccountHolders(accountID uuid.UUID) ([]models.AccountHolder, error) {
+	defer tx.memory.reading(holderRecords)()
+	return lookup(tx.memory.holders, tx.memory.accountHolders[accountID]), nil
+}
+
+func (tx *memoryTx) FindCustomerHoldings(customerID uuid.UUID) ([]models.AccountHolder, error) {
+	defer tx.memory.reading(holderRecords)()
+	return lookup(tx.memory.holders, tx.memory.customerHoldings[customerID]), nil
+}
+
+// findAccountHolder returns the index of a holder of an account in the
+// holders slice. Returns -1 and ErrHolderNotFound if not found
+func (tx *memoryTx) findAccountHolder(accountID uuid.UUID, customerID uuid.UUID) (int, error) {
+	for _, index := range tx.memory.accountHolders[accountID] {
+		if tx.memory.holders[index].CustomerID == customerID {
+			return index, nil
+		}
+	}
+	return -1, utils.ErrHolderNotFound
+}
+
+func (tx *memoryTx) SaveAccountHolder(holder models.AccountHolder) error {
+	memory := tx.memory
+	defer memory.writing(holderRecords)()
+	tx.record(walOperation{Kind: walSaveAccountHolder, Holder: &holder})
+
+	// Replace the role of an existing holder in place
+	if index, err := tx.findAccountHolder(holder.AccountID, holder.CustomerID); err == nil {
+		replaceRecord(tx, &memory.holders, index, holder)
+		return nil
+	}
+
+	position := appendRecord(tx, &memory.holders, holder)
+	tx.addToIndex(memory.accountHolders, position, holder.AccountID)
+	tx.addToIndex(memory.customerHoldings, position, holder.CustomerID)
+	return nil
+}
+
+func (tx *memoryTx) DeleteAccountHolder(accountID uuid.UUID, customerID uuid.UUID) error {
+	memory := tx.memory
+	defer memory.writing(holderRecords)()
+	index, err := tx.findAccountHolder(accountID, customerID)
+	if err != nil {
+		return err
+	}
+
+	// Leave a zero holder in place of the removed one
+	replaceRecord(tx, &memory.holders, index, models.AccountHolder{})
+	tx.removeFromIndex(memory.accountHolders, index, accountID)
+	tx.removeFromIndex(memory.customerHoldings, index, customerID)
+	tx.record(walOperation{Kind: walDeleteAccountHolder, ID: accountID, OtherID: customerID})
+	return nil
+}
+
+// findCustomer looks a customer up by its UUID and returns its index in
+// the customers slice. Returns -1 and ErrCustomerNotFound if not found
+func (tx *memoryTx) findCustomer(id uuid.UUID) (int, error) {
+	if index, ok := tx.memory.customerIndex[id]; ok {
+		return index, nil
+	}
+	return -1, utils.ErrCustomerNotFound
+}
+
+func (tx *memoryTx) CreateCustomer(customer models.Customer) error {
+	memory := tx.memory
+	defer memory.writing(customerRecords)()
+	position := appendRecord(tx, &memory.customers, customer)
+	tx.setPosition(memory.customerIndex, customer.ID, position)
+	tx.record(walOperation{Kind: walCreateCustomer, Customer: &customer})
+	return nil
+}
+
+func (tx *memoryTx) FindCustomer(id uuid.UUID) (models.Customer, error) {
+	defer tx.memory.reading(customerRecords)()
+	index, err := tx.findCustomer(id)
+	if err != nil {
+		return models.Customer{}, err
+	}
+	return tx.memory.customers[index], nil
+}
+
+func (tx *memoryTx) FindCustomers() ([]models.Customer, error) {
+	defer tx.memory.reading(customerRecords)()
+
+	// Copy the customers so callers never share the backing slice
+	customers := make([]models.Customer, 0, len(tx.memory.customers))
+	for _, customer := range tx.memory.customers {
+		if customer.ID != uuid.Nil {
+			customers = append(customers, customer)
+		}
+	}
+	return customers, nil
+}
+
+func (tx *memoryTx) UpdateCustomer(customer models.Customer) error {
+	memory := tx.memory
+	defer memory.writing(customerRecords)()
+	index, err := tx.findCustomer(customer.ID)
+	if err != nil {
+		return err
+	}
+
+	replaceRecord(tx, &memory.customers, index, customer)
+	tx.record(walOperation{Kind: walUpdateCustomer, Customer: &customer})
+	return nil
+}
+
+func (tx *memoryTx) DeleteCustomer(id uuid.UUID) error {
+	memory := tx.memory
+	defer memory.writing(customerRecords)()
+	index, err := tx.findCustomer(id)
+	if err != nil {
+		return err
+	}
+
+	// Leave a zero customer in place of the removed one
+	replaceRecord(tx, &memory.customers, index, models.Customer{})
+	tx.setPosition(memory.customerIndex, id, -1)
+	tx.record(walOperation{Kind: walDeleteCustomer, ID: id})
+	return nil
+}
+
+func (tx *memoryTx) CreateTransaction(transaction models.Transaction) error {
+	memory := tx.memory
+	defer memory.writing(transactionRecords)()
+	position := appendRecord(tx, &memory.transactions, transaction)
+	tx.setPosition(memory.transactionIndex, transaction.ID, position)
+	tx.addToIndex(memory.accountTransactions, position, transaction.AccountID)
+	tx.addToIndex(memory.relatedTransactions, position, transaction.RelatedID)
+	tx.addToIndex(memory.transferTransactions, position, transaction.TransferID)
+	tx.record(walOperation{Kind: walCreateTransaction, Transaction: &transaction})
+	return nil
+}
+
+func (tx *memoryTx) FindTransaction(id uuid.UUID) (models.Transaction, error) {
+	defer tx.memory.reading(transactionRecords)()
+	if index, ok := tx.memory.transactionIndex[id]; ok {
+		return tx.memory.transactions[index], nil
+	}
+	return models.Transaction{}, utils.ErrTransactionNotFound
+}
+
+func (tx *memoryTx) FindRelatedTransactions(id uuid.UUID) ([]models.Transaction, error) {
+	defer tx.memory.reading(transactionRecords)()
+	return lookup(tx.memory.transactions, tx.memory.relatedTransactions[id]), nil
+}
+
+func (tx *memoryTx) FindTransferTransactions(transferID uuid.UUID) ([]models.Transaction, error) {
+	defer tx.memory.reading(transactionRecords)()
+	return lookup(tx.memory.transactions, tx.memory.transferTransactions[transferID]), nil
+}
+
+func (tx *memoryTx) CreateTransfer(transfer models.Transfer) error {
+	memory := tx.memory
+	defer memory.writing(transferRecords)()
+	stored := storedTransfer(transfer)
+	position := appendRecord(tx, &memory.transfers, stored)
+	tx.setPosition(memory.transferIndex, stored.ID, position)
+	tx.addToIndex(memory.accountTransfers, position, stored.FromAccountID, stored.ToAccountID)
+	tx.record(walOperation{Kind: walCreateTransfer, Transfer: &stored})
+	return nil
+}
+
+func (tx *memoryTx) FindTransfer(id uuid.UUID) (models.Transfer, error) {
+	defer tx.memory.reading(transferRecords)()
+	if index, ok := tx.memory.transferIndex[id]; ok {
+		return tx.memory.transfers[index], nil
+	}
+	return models.Transfer{}, utils.ErrTransferNotFound
+}
+
+func (tx *memoryTx) SearchTransfers(filter models.TransferFilter) ([]models.Transfer, error) {
+	defer tx.memory.reading(transferRecords)()
+
+	// Keep the transfers from or to the account of the filter
+	transfers := slices.DeleteFunc(slices.Clone(tx.memory.transfers), func(transfer models.Transfer) bool {
+		return transfer.ID == uuid.Nil
+	})
+	if filter.AccountID != uuid.Nil {
+		transfers = lookup(tx.memory.transfers, tx.memory.accountTransfers[filter.AccountID])
+	}
+
+	// Sort by timestamp, then ID, in the requested direction
+	sort.Slice(transfers, func(i, j int) bool {
+		return compareTransfers(transfers[i], transfers[j], filter.Descending) < 0
+	})
+
+	// Skip everything up to the cursor and cut the page
+	start := 0
+	if filter.After != nil {
+		cursor := models.Transfer{ID: filter.After.ID, TimeStamp: time.Unix(0, filter.After.Value)}
+		start = sort.Search(len(transfers), func(index int) bool {
+			return compareTransfers(transfers[index], cursor, filter.Descending) > 0
+		})
+	}
+	end := min(start+filter.Limit, len(transfers))
+	return transfers[start:end], nil
+}
+
+func (tx *memoryTx) UpdateTransfer(transfer models.Transfer) error {
+	memory := tx.memory
+	defer memory.writing(transferRecords)()
+	index, ok := memory.transferIndex[transfer.ID]
+	if !ok {
+		return utils.ErrTransferNotFound
+	}
+
+	updated := storedTransfer(transfer)
+	replaceRecord(tx, &memory.transfers, index, updated)
+	tx.record(walOperation{Kind: walUpdateTransfer, Transfer: &updated})
+	return nil
+}
+
+// storedTransfer drops the legs and fees of a transfer, which are stored as
+// transactions of their own
+func storedTransfer(transfer models.Transfer) models.Transfer {
+	transfer.Withdrawal = models.Transaction{}
+	transfer.Deposit = models.Transaction{}
+	transfer.Fees = nil
+	return transfer
+}
+
+// compareTransfers orders transfers by timestamp, then ID
+func compareTransfers(left models.Transfer, right models.Transfer, descending bool) int {
+	comparison := cmp.Compare(left.TimeStamp.UnixNano(), right.TimeStamp.UnixNano())
+	if comparison == 0 {
+		comparison = bytes.Compare(left.ID[:], right.ID[:])
+	}
+	if descending {
+		return -comparison
+	}
+	return comparison
+}
+
+func (tx *memoryTx) FindTransactions(accountID uuid.UUID) ([]models.Transaction, error) {
+	defer tx.memory.reading(transactionRecords)()
+	return lookup(tx.memory.transactions, tx.memory.accountTransactions[accountID]), nil
+}
+
+func (tx *memoryTx) SearchTransactions(filter models.TransactionFilter) ([]models.Transaction, error) {
+	defer tx.memory.reading(transactionRecords)()
+
+	// Keep the transactions of the account matching the filter
+	transactions := []models.Transaction{}
+	for _, index := range tx.memory.accountTransactions[filter.AccountID] {
+		if transaction := tx.memory.transactions[index]; matchesTransaction(filter, transaction) {
+			transactions = append(transactions, transaction)
+		}
+	}
+
+	// Sort by timestamp, then ID, in the requested direction
+	sort.Slice(transactions, func(i, j int) bool {
+		return compareTransactions(transactions[i], transactions[j], filter.Descending) < 0
+	})
+
+	// Skip everything up to the cursor and cut the page
+	start := 0
+	if filter.After != nil {
+		cursor := models.Transaction{ID: filter.After.ID, TimeStamp: time.Unix(0, filter.After.Value)}
+		start = sort.Search(len(transactions), func(index int) bool {
+			return compareTransactions(transactions[index], cursor, filter.Descending) > 0
+		})
+	}
+	end := min(start+filter.Limit, len(transactions))
+	return transactions[start:end], nil
+}
+
+// matchesTransaction reports whether a transaction passes the type, amount
+// and time conditions of a filter
+func matchesTransaction(filter models.TransactionFilter, transaction models.Transaction) bool {
+	switch {
+	case filter.Type != nil && transaction.Type != *filter.Type:
+		return false
+	case filter.MinAmount != nil && transaction.Amount.Cmp(*filter.MinAmount) < 0:
+		return false
+	case filter.MaxAmount != nil && transaction.Amount.Cmp(*filter.MaxAmount) > 0:
+		return false
+	case !filter.From.IsZero() && transaction.TimeStamp.Before(filter.From):
+		return false
+	case !filter.To.IsZero() && !transaction.TimeStamp.Before(filter.To):
+		return false
+	}
+	return true
+}
+
+// compareTransactions orders transactions by timestamp, then ID, returning a
+// negative number when left comes first in the given direction
+func compareTransactions(left models.Transaction, right models.Transaction, descending bool) int {
+	comparison := cmp.Compare(left.TimeStamp.UnixNano(), right.TimeStamp.UnixNano())
+	if comparison == 0 {
+		comparison = bytes.Compare(left.ID[:], right.ID[:])
+	}
+	if descending {
+		return -comparison
+	}
+	return comparison
+}
+
+func (tx *memoryTx) CreateJournalEntry(entry models.JournalEntry) error {
+	// Copy the postings so the caller cannot change the stored entry
+	entry.Postings = append([]models.Posting{}, entry.Postings...)
+
+	memory := tx.memory
+	defer memory.writing(journalRecords)()
+	appendRecord(tx, &memory.journal, entry)
+	tx.record(walOperation{Kind: walCreateJournalEntry, Entry: &entry})
+	return nil
+}
+
+func (tx *memoryTx) FindLedgerBalances() ([]models.LedgerBalance, error) {
+	defer tx.memory.reading(journalRecords)()
+
+	// Sum the postings per ledger account and currency in order of appearance
+	type key struct {
+		accountID uuid.UUID
+		currency  string
+	}
+	indexes := map[key]int{}
+	balances := []models.LedgerBalance{}
+	for _, entry := range tx.memory.journal {
+		for _, posting := range entry.Postings {
+			balanceKey := key{posting.AccountID, posting.Currency}
+			index, ok := indexes[balanceKey]
+			if !ok {
+				index = len(balances)
+				indexes[balanceKey] = index
+				balances = append(balances, models.LedgerBalance{
+					AccountID: posting.AccountID,
+					Currency:  posting.Currency,
+					Debits:    money.Zero(posting.Amount.Scale),
+					Credits:   money.Zero(posting.Amount.Scale),
+				})
+			}
+
+			var err error
+			if posting.Side == utils.Debit {
+				balances[index].Debits, err = balances[index].Debits.Add(posting.Amount)
+			} else {
+				balances[index].Credits, err = balances[index].Credits.Add(posting.Amount)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return balances, nil
+}
+
+// findHold looks a hold up by its UUID and returns its index in the holds
+// slice. Returns -1 and ErrHoldNotFound if not found
+func (tx *memoryTx) findHold(id uuid.UUID) (int, error) {
+	if index, ok := tx.memory.holdIndex[id]; ok {
+		return index, nil
+	}
+	return -1, utils.ErrHoldNotFound
+}
+
+func (tx *memoryTx) CreateHold(hold models.Hold) error {
+	memory := tx.memory
+	defer memory.writing(holdRecords)()
+	position := appendRecord(tx, &memory.holds, hold)
+	tx.setPosition(memory.holdIndex, hold.ID, position)
+	tx.addToIndex(memory.accountHolds, position, hold.AccountID)
+	tx.record(walOperation{Kind: walCreateHold, Hold: &hold})
+	return nil
+}
+
+func (tx *memoryTx) FindHold(id uuid.UUID) (models.Hold, error) {
+	defer tx.memory.reading(holdRecords)()
+	index, err := tx.findHold(id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	return tx.memory.holds[index], nil
+}
+
+func (tx *memoryTx) FindAccountHolds(accountID uuid.UUID) ([]models.Hold, error) {
+	defer tx.memory.reading(holdRecords)()
+	return lookup(tx.memory.holds, tx.memory.accountHolds[accountID]), nil
+}
+
+func (tx *memoryTx) UpdateHold(hold models.Hold) error {
+	memory := tx.memory
+	defer memory.writing(holdRecords)()
+	index, err := tx.findHold(hold.ID)
+	if err != nil {
+		return err
+	}
+
+	replaceRecord(tx, &memory.holds, index, hold)
+	tx.record(walOperation{Kind: walUpdateHold, Hold: &hold})
+	return nil
+}
+
+func (tx *memoryTx) AppendEvent(event models.Event) error {
+	defer tx.memory.writing(eventRecords)()
+	tx.appendEvent(event, int64(len(tx.memory.events))+1)
+	return nil
+}
+
+// appendEvent stores an event under the given sequence, at the position
+// before it. Replay passes the logged sequence, as units of work may reach
+// the log in another order than they numbered their events
+func (tx *memoryTx) appendEvent(event models.Event, sequence int64) {
+	memory := tx.memory
+	event.Sequence = sequence
+	position := int(sequence) - 1
+	for len(memory.events) < position {
+		memory.events = append(memory.events, models.Event{})
+	}
+	if position < len(memory.events) {
+		replaceRecord(tx, &memory.events, position, event)
+	} else {
+		appendRecord(tx, &memory.events, event)
+	}
+	tx.addToIndex(memory.accountEvents, position, event.AccountID, event.CounterpartyID)
+	tx.record(walOperation{Kind: walAppendEvent, Event: &event})
+}
+
+func (tx *memoryTx) FindEvents(after int64, limit int) ([]models.Event, error) {
+	defer tx.memory.reading(eventRecords)()
+
+	// Sequences start at 1 and follow the slice order, skipping the events
+	// of units rolled back
+	events := []models.Event{}
+	for _, event := range tx.memory.events[min(int(max(after, 0)), len(tx.memory.events)):] {
+		if len(events) == limit {
+			break
+		}
+		if event.Sequence != 0 {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (tx *memoryTx) FindAccountEvents(filter models.EventFilter) ([]models.Event, error) {
+	defer tx.memory.reading(eventRecords)()
+
+	// Skip the events of the account up to the sequence of the filter,
+	// which is one past the position of the event
+	positions := tx.memory.accountEvents[filter.AccountID]
+	start, _ := slices.BinarySearch(positions, int(max(filter.After, 0)))
+
+	events := []models.Event{}
+	for _, index := range positions[start:] {
+		event := tx.memory.events[index]
+		if filter.Until.IsZero() || !event.TimeStamp.After(filter.Until) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (tx *memoryTx) SaveBalanceCheckpoint(balance models.AccountBalance) error {
+	memory := tx.memory
+	defer memory.writing(checkpointRecords)()
+	position := appendRecord(tx, &memory.checkpoints, balance)
+	tx.addToIndex(memory.accountCheckpoints, position, balance.AccountID)
+	tx.record(walOperation{Kind: walSaveBalanceCheckpoint, Checkpoint: &balance})
+	return nil
+}
+
+func (tx *memoryTx) FindBalanceCheckpoint(accountID uuid.UUID, asOf time.Time) (models.AccountBalance, error) {
+	defer tx.memory.reading(checkpointRecords)()
+
+	// Checkpoints are saved in version order, so the last match is the latest
+	positions := tx.memory.accountCheckpoints[accountID]
+	for index := len(positions) - 1; index >= 0; index-- {
+		checkpoint := tx.memory.checkpoints[positions[index]]
+		if asOf.IsZero() || !checkpoint.UpdatedAt.After(asOf) {
+			return checkpoint, nil
+		}
+	}
+	return models.AccountBalance{}, utils.ErrCheckpointNotFound
+}
+
+func (tx *memoryTx) SaveInterestAccrual(accrual models.InterestAccrual) error {
+	memory := tx.memory
+	defer memory.writing(accrualRecords)()
+	tx.record(walOperation{Kind: walSaveInterestAccrual, Accrual: &accrual})
+
+	// Replace the accrual of the same day in place
+	for _, index := range memory.accountAccruals[accrual.AccountID] {
+		if memory.accruals[index].Date.Equal(accrual.Date) {
+			replaceRecord(tx, &memory.accruals, index, accrual)
+			return nil
+		}
+	}
+
+	position := appendRecord(tx, &memory.accruals, accrual)
+	tx.addToIndex(memory.accountAccruals, position, accrual.AccountID)
+	return nil
+}
+
+func (tx *memoryTx) FindInterestAccruals(accountID uuid.UUID, from time.Time, to time.Time) ([]models.InterestAccrual, error) {
+	defer tx.memory.reading(accrualRecords)()
+	return tx.findInterestAccruals(accountID, from, to), nil
+}
+
+// findInterestAccruals returns the accruals of an account in a date range
+// in date order
+func (tx *memoryTx) findInterestAccruals(accountID uuid.UUID, from time.Time, to time.Time) []models.InterestAccrual {
+	// Filter accruals for the specified account and date range
+	accruals := []models.InterestAccrual{}
+	for _, index := range tx.memory.accountAccruals[accountID] {
+		accrual := tx.memory.accruals[index]
+		if (!from.IsZero() && accrual.Date.Before(from)) || (!to.IsZero() && !accrual.Date.Before(to)) {
+			continue
+		}
+		accruals = append(accruals, accrual)
+	}
+
+	// Accruals may be added out of order when a range is accrued again
+	slices.SortStableFunc(accruals, func(left, right models.InterestAccrual) int {
+		return left.Date.Compare(right.Date)
+	})
+	return accruals
+}
+
+func (tx *memoryTx) FindLatestInterestAccrual(accountID uuid.UUID) (models.InterestAccrual, error) {
+	defer tx.memory.reading(accrualRecords)()
+	accruals := tx.findInterestAccruals(accountID, time.Time{}, time.Time{})
+	if len(accruals) == 0 {
+		return models.InterestAccrual{}, utils.ErrAccrualNotFound
+	}
+	return accruals[len(accruals)-1], nil
+}
+
+func (tx *memoryTx) FindIdempotencyRecord(key string) (models.IdempotencyRecord, error) {
+	defer tx.memory.reading(idempotencyRecords)()
+	record, ok := tx.memory.idempotency[key]
+	if !ok {
+		return models.IdempotencyRecord{}, utils.ErrIdempotencyKeyNotFound
+	}
+	return record, nil
+}
+
+func (tx *memoryTx) SaveIdempotencyRecord(record models.IdempotencyRecord) error {
+	// Copy the body so the caller cannot change the stored response
+	record.Body = append([]byte{}, record.Body...)
+
+	defer tx.memory.writing(idempotencyRecords)()
+	tx.replaceIdempotencyRecord(record.Key, &record)
+	tx.record(walOperation{Kind: walSaveIdempotencyRecord, Idempotency: &record})
+	return nil
+}
+
+func (tx *memoryTx) DeleteIdempotencyRecord(key string) error {
+	defer tx.memory.writing(idempotencyRecords)()
+	if _, ok := tx.memory.idempotency[key]; ok {
+		tx.replaceIdempotencyRecord(key, nil)
+		tx.record(walOperation{Kind: walDeleteIdempotencyRecord, Key: key})
+	}
+	return nil
+}
+
+func (tx *memoryTx) DeleteExpiredIdempotencyRecords(now time.Time) error {
+	defer tx.memory.writing(idempotencyRecords)()
+	expired := false
+	for key, record := range tx.memory.idempotency {
+		if !record.ExpiresAt.After(now) {
+			tx.replaceIdempotencyRecord(key, nil)
+			expired = true
+		}
+	}
+	if expired {
+		tx.record(walOperation{Kind: walDeleteExpiredIdempotencyRecords, Time: now})
+	}
+	return nil
+}
+
+// replaceIdempotencyRecord stores record under key, or removes the key when
+// record is nil, remembering how to restore the previous state
+func (tx *memoryTx) replaceIdempotencyRecord(key string, record *models.IdempotencyRecord) {
+	memory := tx.memory
+	previous, existed := memory.idempotency[key]
+	if record == nil {
+		delete(memory.idempotency, key)
+	} else {
+		memory.idempotency[key] = *record
+	}
+	tx.undo = append(tx.undo, func() {
+		if existed {
+			memory.idempotency[key] = previous
+		} else {
+			delete(memory.idempotency, key)
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// sqliteMigrations lists the schema changes of the SQLite backend. The
+// version of a migration is its position in the slice starting at 1.
+// Migrations are forward-only: never edit or reorder an entry, append a
+// new one instead
+var sqliteMigrations = []string{
+	// 1: accounts and transactions
+	`CREATE TABLE accounts (
+		id      TEXT PRIMARY KEY,
+		owner   TEXT NOT NULL,
+		balance REAL NOT NULL
+	);
+	CREATE TABLE transactions (
+		id         TEXT PRIMARY KEY,
+		account_id TEXT NOT NULL REFERENCES accounts (id),
+		type       TEXT NOT NULL,
+		amount     REAL NOT NULL,
+		timestamp  INTEGER NOT NULL
+	);
+	CREATE INDEX transactions_account_id ON transactions (account_id);`,
+
+	// 2: store amounts as integer minor units with their scale
+	`ALTER TABLE accounts ADD COLUMN balance_units INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE accounts ADD COLUMN scale INTEGER NOT NULL DEFAULT 2;
+	UPDATE accounts SET balance_units = CAST(ROUND(balance * 100) AS INTEGER);
+	ALTER TABLE accounts DROP COLUMN balance;
+	ALTER TABLE accounts RENAME COLUMN balance_units TO balance;
+	ALTER TABLE transactions ADD COLUMN amount_units INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE transactions ADD COLUMN scale INTEGER NOT NULL DEFAULT 2;
+	UPDATE transactions SET amount_units = CAST(ROUND(amount * 100) AS INTEGER);
+	ALTER TABLE transactions DROP COLUMN amount;
+	ALTER TABLE transactions RENAME COLUMN amount_units TO amount;`,
+
+	// 3: ISO 4217 currency of accounts and transactions
+	`ALTER TABLE accounts ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD';
+	ALTER TABLE transactions ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD';`,
+
+	// 4: currency conversion of cross-currency transfer legs as JSON
+	`ALTER TABLE transactions ADD COLUMN exchange TEXT;`,
+
+	// 5: double-entry journal, opening existing balances against the
+	// external account with one entry per account that reuses its ID
+	`CREATE TABLE journal_entries (
+		id          TEXT PRIMARY KEY,
+		description TEXT NOT NULL,
+		timestamp   INTEGER NOT NULL
+	);
+	CREATE TABLE postings (
+		entry_id       TEXT NOT NULL REFERENCES journal_entries (id),
+		position       INTEGER NOT NULL,
+		account_id     TEXT NOT NULL,
+		transaction_id TEXT,
+		side           TEXT NOT NULL,
+		currency       TEXT NOT NULL,
+		amount         INTEGER NOT NULL,
+		scale          INTEGER NOT NULL,
+		PRIMARY KEY (entry_id, position)
+	);
+	CREATE INDEX postings_account_id ON postings (account_id);
+	INSERT INTO journal_entries (id, description, timestamp)
+		SELECT id, 'opening balance', CAST(strftime('%s', 'now') AS INTEGER) * 1000000000
+		FROM accounts WHERE balance <> 0;
+	INSERT INTO postings (entry_id, position, account_id, side, currency, amount, scale)
+		SELECT id, 0, '00000000-0000-0000-0000-000000000001',
+			CASE WHEN balance > 0 THEN 'debit' ELSE 'credit' END, currency, ABS(balance), scale
+		FROM accounts WHERE balance <> 0;
+	INSERT INTO postings (entry_id, position, account_id, side, currency, amount, scale)
+		SELECT id, 1, id, CASE WHEN balance > 0 THEN 'credit' ELSE 'debit' END, currency, ABS(balance), scale
+		FROM accounts WHERE balance <> 0;`,
+
+	// 6: responses stored for idempotency keys
+	`CREATE TABLE idempotency_keys (
+		key          TEXT PRIMARY KEY,
+		fingerprint  TEXT NOT NULL,
+		completed    INTEGER NOT NULL,
+		status_code  INTEGER NOT NULL,
+		content_type TEXT NOT NULL,
+		body         BLOB,
+		expires_at   INTEGER NOT NULL
+	);
+	CREATE INDEX idempotency_keys_expires_at ON idempotency_keys (expires_at);`,
+
+	// 7: page through the transactions of an account by timestamp
+	`CREATE INDEX transactions_account_id_timestamp ON transactions (account_id, timestamp, id);`,
+
+	// 8: account status and creation time, existing accounts count as
+	// created now
+	`ALTER TABLE accounts ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+	ALTER TABLE accounts ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0;
+	UPDATE accounts SET created_at = CAST(strftime('%s', 'now') AS INTEGER) * 1000000000;
+	CREATE INDEX accounts_created_at ON accounts (created_at, id);`,
+
+	// 9: customers holding accounts
+	`CREATE TABLE customers (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		email      TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	ALTER TABLE accounts ADD COLUMN customer_id TEXT REFERENCES customers (id);
+	CREATE INDEX accounts_customer_id ON accounts (customer_id);`,
+
+	// 10: joint account holders, starting with the customer of each account
+	`CREATE TABLE account_holders (
+		account_id  TEXT NOT NULL REFERENCES accounts (id),
+		customer_id TEXT NOT NULL REFERENCES customers (id),
+		role        TEXT NOT NULL,
+		PRIMARY KEY (account_id, customer_id)
+	);
+	CREATE INDEX account_holders_customer_id ON account_holders (customer_id);
+	INSERT INTO account_holders (account_id, customer_id, role)
+		SELECT id, customer_id, 'owner' FROM accounts WHERE customer_id IS NOT NULL ORDER BY rowid;`,
+
+	// 11: account types, existing accounts being checking accounts
+	`ALTER TABLE accounts ADD COLUMN type TEXT NOT NULL DEFAULT 'checking';
+	ALTER TABLE accounts ADD COLUMN matures_at INTEGER NOT NULL DEFAULT 0;`,
+
+	// 12: overdraft limits, in minor units at the scale of the balance
+	`ALTER TABLE accounts ADD COLUMN overdraft INTEGER NOT NULL DEFAULT 0;`,
+
+	// 13: interest rates of accounts and their daily interest accruals
+	`ALTER TABLE accounts ADD COLUMN interest_rate TEXT;
+	ALTER TABLE accounts ADD COLUMN day_count TEXT NOT NULL DEFAULT 'ACT/365';
+	CREATE TABLE interest_accruals (
+		account_id     TEXT NOT NULL REFERENCES accounts (id),
+		date           INTEGER NOT NULL,
+		balance        INTEGER NOT NULL,
+		balance_scale  INTEGER NOT NULL,
+		amount         INTEGER NOT NULL,
+		amount_scale   INTEGER NOT NULL,
+		transaction_id TEXT REFERENCES transactions (id),
+		PRIMARY KEY (account_id, date)
+	);`,
+
+	// 14: transactions a fee was charged for
+	`ALTER TABLE transactions ADD COLUMN related_id TEXT REFERENCES transactions (id);`,
+
+	// 15: holds reserving funds of accounts, in minor units at the scale of
+	// the balance
+	`ALTER TABLE accounts ADD COLUMN held INTEGER NOT NULL DEFAULT 0;
+	CREATE TABLE holds (
+		id             TEXT PRIMARY KEY,
+		account_id     TEXT NOT NULL REFERENCES accounts (id),
+		amount         INTEGER NOT NULL,
+		captured       INTEGER NOT NULL,
+		scale          INTEGER NOT NULL,
+		transaction_id TEXT REFERENCES transactions (id),
+		status         TEXT NOT NULL,
+		created_at     INTEGER NOT NULL,
+		expires_at     INTEGER NOT NULL
+	);
+	CREATE INDEX holds_account_id ON holds (account_id);`,
+
+	// 16: finding the reversals and fees of a transaction
+	`CREATE INDEX transactions_related_id ON transactions (related_id);`,
+
+	// 17: transfers referenced by their legs and fees
+	`CREATE TABLE transfers (
+		id              TEXT PRIMARY KEY,
+		from_account_id TEXT NOT NULL REFERENCES accounts (id),
+		to_account_id   TEXT NOT NULL REFERENCES accounts (id),
+		currency        TEXT NOT NULL,
+		amount          INTEGER NOT NULL,
+		scale           INTEGER NOT NULL,
+		status          TEXT NOT NULL,
+		timestamp       INTEGER NOT NULL
+	);
+	CREATE INDEX transfers_timestamp ON transfers (timestamp, id);
+	ALTER TABLE transactions ADD COLUMN transfer_id TEXT REFERENCES transfers (id);
+	CREATE INDEX transactions_transfer_id ON transactions (transfer_id);`,
+
+	// 18: the event store, opening existing accounts with their current
+	// balance, overdraft and pending holds
+	`CREATE TABLE events (
+		sequence        INTEGER PRIMARY KEY AUTOINCREMENT,
+		type            TEXT NOT NULL,
+		account_id      TEXT NOT NULL REFERENCES accounts (id),
+		counterparty_id TEXT REFERENCES accounts (id),
+		reference_id    TEXT,
+		currency        TEXT NOT NULL,
+		amount          INTEGER NOT NULL,
+		scale           INTEGER NOT NULL,
+		status          TEXT NOT NULL,
+		timestamp       INTEGER NOT NULL
+	);
+	CREATE INDEX events_account_id ON events (account_id);
+	CREATE INDEX events_counterparty_id ON events (counterparty_id);
+	INSERT INTO events (type, account_id, currency, amount, scale, status, timestamp)
+		SELECT 'account_opened', id, currency, balance, scale, status, created_at FROM accounts ORDER BY rowid;
+	INSERT INTO events (type, account_id, currency, amount, scale, status, timestamp)
+		SELECT 'overdraft_changed', id, currency, overdraft, scale, status, created_at FROM accounts WHERE overdraft != 0 ORDER BY rowid;
+	INSERT INTO events (type, account_id, reference_id, currency, amount, scale, status, timestamp)
+		SELECT 'funds_held', holds.account_id, holds.id, accounts.currency, holds.amount, holds.scale, accounts.status, holds.created_at
+		FROM holds JOIN accounts ON accounts.id = holds.account_id WHERE holds.status = 'pending' ORDER BY holds.rowid;`,
+
+	// 19: checkpoints of the balances projected from the events, in minor
+	// units at the scale of the balance
+	`CREATE TABLE balance_checkpoints (
+		account_id TEXT NOT NULL REFERENCES accounts (id),
+		version    INTEGER NOT NULL REFERENCES events (sequence),
+		currency   TEXT NOT NULL,
+		balance    INTEGER NOT NULL,
+		held       INTEGER NOT NULL,
+		overdraft  INTEGER NOT NULL,
+		scale      INTEGER NOT NULL,
+		status     TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (account_id, version)
+	);`,
+}
+
+// migrate brings the database schema up to the latest version, applying
+// every pending migration in its own transaction
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	// Find the version the database is currently at
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	if current > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", current, len(sqliteMigrations))
+	}
+
+	// Apply the pending migrations in order
+	for version := current + 1; version <= len(sqliteMigrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[version-1]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UnixNano()); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
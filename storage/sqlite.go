@@ -0,0 +1,1152 @@
+package storage
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+// querier is the subset of *sql.DB and *sql.Tx used by the SQLite queries
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// sqliteQueries implements the data access methods of the SQLite backend on
+// top of either the database handle or a running transaction
+type sqliteQueries struct {
+	db querier
+}
+
+// SQLite is a Storage backed by an embedded SQLite database file
+type SQLite struct {
+	sqliteQueries
+	database *sql.DB
+}
+
+// sqliteTx is the view of a SQLite store handed to a unit of work
+type sqliteTx struct {
+	sqliteQueries
+}
+
+// CreateSQLite opens the SQLite database at the given path, creating the
+// file if needed, and applies any pending schema migrations
+func CreateSQLite(path string) (*SQLite, error) {
+	database, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+
+	// A single connection serializes units of work the same way the
+	// in-memory store does and avoids SQLITE_BUSY between writers
+	database.SetMaxOpenConns(1)
+
+	if err := migrate(database); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	return &SQLite{
+		sqliteQueries: sqliteQueries{db: database},
+		database:      database,
+	}, nil
+}
+
+// Close releases the underlying database handle
+func (sqlite *SQLite) Close() error {
+	return sqlite.database.Close()
+}
+
+// Atomic runs fn inside a database transaction that is committed when fn
+// returns nil and rolled back otherwise
+func (sqlite *SQLite) Atomic(fn func(store Storage) error) error {
+	tx, err := sqlite.database.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&sqliteTx{sqliteQueries{db: tx}}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Lock runs fn as an Atomic unit of work. The single connection serializes
+// units of work, so locking the given IDs alone gains nothing
+func (sqlite *SQLite) Lock(ids []uuid.UUID, fn func(store Storage) error) error {
+	return sqlite.Atomic(fn)
+}
+
+// View runs fn as an Atomic unit of work for the same reason as Lock
+func (sqlite *SQLite) View(ids []uuid.UUID, fn func(store Storage) error) error {
+	return sqlite.Atomic(fn)
+}
+
+// Atomic joins the transaction that is already running
+func (tx *sqliteTx) Atomic(fn func(store Storage) error) error {
+	return fn(tx)
+}
+
+// Lock joins the transaction that is already running
+func (tx *sqliteTx) Lock(ids []uuid.UUID, fn func(store Storage) error) error {
+	return fn(tx)
+}
+
+// View joins the transaction that is already running
+func (tx *sqliteTx) View(ids []uuid.UUID, fn func(store Storage) error) error {
+	return fn(tx)
+}
+
+// accountColumns lists the columns read by scanAccount
+const accountColumns = `id, customer_id, owner, currency, balance, scale, overdraft, held, interest_rate, day_count, type, matures_at, status, created_at`
+
+func (queries sqliteQueries) CreateAccount(account models.Account) error {
+	overdraft, held, err := accountUnits(account)
+	if err != nil {
+		return err
+	}
+	_, err = queries.db.Exec(
+		`INSERT INTO accounts (`+accountColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		account.ID.String(), nullableID(account.CustomerID), account.Owner, account.Currency, account.Balance.Units, account.Balance.Scale,
+		overdraft, held, nullableRate(account.InterestRate), account.DayCount.String(),
+		account.Type.String(), unixNano(account.MaturesAt), account.Status.String(), unixNano(account.CreatedAt),
+	)
+	return err
+}
+
+func (queries sqliteQueries) FindAccount(id uuid.UUID) (models.Account, error) {
+	row := queries.db.QueryRow(`SELECT `+accountColumns+` FROM accounts WHERE id = ?`, id.String())
+	account, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Account{}, utils.ErrAccountNotFound
+	}
+	return account, err
+}
+
+func (queries sqliteQueries) FindAccounts() ([]models.Account, error) {
+	rows, err := queries.db.Query(`SELECT ` + accountColumns + ` FROM accounts ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	return scanAccounts(rows)
+}
+
+func (queries sqliteQueries) SearchAccounts(filter models.AccountFilter) ([]models.Account, error) {
+	// Build the conditions of the filter. Balances are bounded and sorted
+	// within one currency, so they compare in its minor units
+	conditions := []string{"1 = 1"}
+	arguments := []any{}
+	if filter.Owner != "" {
+		conditions = append(conditions, `owner LIKE ? ESCAPE '\'`)
+		arguments = append(arguments, "%"+likeEscaper.Replace(filter.Owner)+"%")
+	}
+	if filter.Currency != "" {
+		conditions = append(conditions, "currency = ?")
+		arguments = append(arguments, filter.Currency)
+	}
+	if filter.MinBalance != nil {
+		conditions = append(conditions, "balance >= ?")
+		arguments = append(arguments, filter.MinBalance.Units)
+	}
+	if filter.MaxBalance != nil {
+		conditions = append(conditions, "balance <= ?")
+		arguments = append(arguments, filter.MaxBalance.Units)
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := []string{}
+		for _, status := range filter.Statuses {
+			placeholders = append(placeholders, "?")
+			arguments = append(arguments, status.String())
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	// Continue after the cursor in the direction of the sort
+	sortValue := "created_at"
+	if filter.SortBy == models.AccountSortBalance {
+		sortValue = "balance"
+	}
+	order, comparison := "ASC", ">"
+	if filter.Descending {
+		order, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		conditions = append(conditions, "("+sortValue+", id) "+comparison+" (?, ?)")
+		arguments = append(arguments, filter.After.Value, filter.After.ID.String())
+	}
+	arguments = append(arguments, filter.Limit)
+
+	rows, err := queries.db.Query(
+		`SELECT `+accountColumns+` FROM accounts WHERE `+strings.Join(conditions, " AND ")+
+			` ORDER BY `+sortValue+` `+order+`, id `+order+` LIMIT ?`,
+		arguments...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAccounts(rows)
+}
+
+func (queries sqliteQueries) UpdateAccount(account models.Account) error {
+	overdraft, held, err := accountUnits(account)
+	if err != nil {
+		return err
+	}
+	result, err := queries.db.Exec(
+		`UPDATE accounts SET customer_id = ?, owner = ?, currency = ?, balance = ?, scale = ?, overdraft = ?, held = ?, interest_rate = ?, day_count = ?, type = ?, matures_at = ?, status = ?, created_at = ? WHERE id = ?`,
+		nullableID(account.CustomerID), account.Owner, account.Currency, account.Balance.Units, account.Balance.Scale,
+		overdraft, held, nullableRate(account.InterestRate), account.DayCount.String(), account.Type.String(), unixNano(account.MaturesAt), account.Status.String(), unixNano(account.CreatedAt), account.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, utils.ErrAccountNotFound)
+}
+
+func (queries sqliteQueries) FindCustomerAccounts(customerID uuid.UUID) ([]models.Account, error) {
+	rows, err := queries.db.Query(`SELECT `+accountColumns+` FROM accounts WHERE customer_id = ? ORDER BY rowid`, customerID.String())
+	if err != nil {
+		return nil, err
+	}
+	return scanAccounts(rows)
+}
+
+func (queries sqliteQueries) FindAccountHolders(accountID uuid.UUID) ([]models.AccountHolder, error) {
+	rows, err := queries.db.Query(`SELECT account_id, customer_id, role FROM account_holders WHERE account_id = ? ORDER BY rowid`, accountID.String())
+	if err != nil {
+		return nil, err
+	}
+	return scanAccountHolders(rows)
+}
+
+func (queries sqliteQueries) FindCustomerHoldings(customerID uuid.UUID) ([]models.AccountHolder, error) {
+	rows, err := queries.db.Query(`SELECT account_id, customer_id, role FROM account_holders WHERE customer_id = ? ORDER BY rowid`, customerID.String())
+	if err != nil {
+		return nil, err
+	}
+	return scanAccountHolders(rows)
+}
+
+func (queries sqliteQueries) SaveAccountHolder(holder models.AccountHolder) error {
+	_, err := queries.db.Exec(
+		`INSERT INTO account_holders (account_id, customer_id, role) VALUES (?, ?, ?)
+		ON CONFLICT (account_id, customer_id) DO UPDATE SET role = excluded.role`,
+		holder.AccountID.String(), holder.CustomerID.String(), holder.Role.String(),
+	)
+	return err
+}
+
+func (queries sqliteQueries) DeleteAccountHolder(accountID uuid.UUID, customerID uuid.UUID) error {
+	result, err := queries.db.Exec(`DELETE FROM account_holders WHERE account_id = ? AND customer_id = ?`, accountID.String(), customerID.String())
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, utils.ErrHolderNotFound)
+}
+
+func (queries sqliteQueries) CreateCustomer(customer models.Customer) error {
+	_, err := queries.db.Exec(
+		`INSERT INTO customers (id, name, email, created_at) VALUES (?, ?, ?, ?)`,
+		customer.ID.String(), customer.Name, customer.Email, unixNano(customer.CreatedAt),
+	)
+	return err
+}
+
+func (queries sqliteQueries) FindCustomer(id uuid.UUID) (models.Customer, error) {
+	row := queries.db.QueryRow(`SELECT id, name, email, created_at FROM customers WHERE id = ?`, id.String())
+	customer, err := scanCustomer(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Customer{}, utils.ErrCustomerNotFound
+	}
+	return customer, err
+}
+
+func (queries sqliteQueries) FindCustomers() ([]models.Customer, error) {
+	rows, err := queries.db.Query(`SELECT id, name, email, created_at FROM customers ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := []models.Customer{}
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	return customers, rows.Err()
+}
+
+func (queries sqliteQueries) UpdateCustomer(customer models.Customer) error {
+	result, err := queries.db.Exec(
+		`UPDATE customers SET name = ?, email = ?, created_at = ? WHERE id = ?`,
+		customer.Name, customer.Email, unixNano(customer.CreatedAt), customer.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, utils.ErrCustomerNotFound)
+}
+
+func (queries sqliteQueries) DeleteCustomer(id uuid.UUID) error {
+	result, err := queries.db.Exec(`DELETE FROM customers WHERE id = ?`, id.String())
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, utils.ErrCustomerNotFound)
+}
+
+// transactionColumns lists the columns read by scanTransaction
+const transactionColumns = `id, account_id, type, currency, amount, scale, exchange, related_id, transfer_id, timestamp`
+
+func (queries sqliteQueries) CreateTransaction(transaction models.Transaction) error {
+	exchange, err := encodeJSON(transaction.Exchange)
+	if err != nil {
+		return err
+	}
+
+	_, err = queries.db.Exec(
+		`INSERT INTO transactions (`+transactionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		transaction.ID.String(), transaction.AccountID.String(), transaction.Type.String(), transaction.Currency,
+		transaction.Amount.Units, transaction.Amount.Scale, exchange, nullableID(transaction.RelatedID), nullableID(transaction.TransferID),
+		transaction.TimeStamp.UnixNano(),
+	)
+	return err
+}
+
+func (queries sqliteQueries) FindTransaction(id uuid.UUID) (models.Transaction, error) {
+	row := queries.db.QueryRow(`SELECT `+transactionColumns+` FROM transactions WHERE id = ?`, id.String())
+	transaction, err := scanTransaction(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Transaction{}, utils.ErrTransactionNotFound
+	}
+	return transaction, err
+}
+
+func (queries sqliteQueries) FindRelatedTransactions(id uuid.UUID) ([]models.Transaction, error) {
+	rows, err := queries.db.Query(
+		`SELECT `+transactionColumns+` FROM transactions WHERE related_id = ? ORDER BY rowid`,
+		id.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanTransactions(rows)
+}
+
+func (queries sqliteQueries) FindTransferTransactions(transferID uuid.UUID) ([]models.Transaction, error) {
+	rows, err := queries.db.Query(
+		`SELECT `+transactionColumns+` FROM transactions WHERE transfer_id = ? ORDER BY rowid`,
+		transferID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanTransactions(rows)
+}
+
+// transferColumns lists the columns read by scanTransfer
+const transferColumns = `id, from_account_id, to_account_id, currency, amount, scale, status, timestamp`
+
+func (queries sqliteQueries) CreateTransfer(transfer models.Transfer) error {
+	_, err := queries.db.Exec(
+		`INSERT INTO transfers (`+transferColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		transfer.ID.String(), transfer.FromAccountID.String(), transfer.ToAccountID.String(), transfer.Currency,
+		transfer.Amount.Units, transfer.Amount.Scale, transfer.Status.String(), transfer.TimeStamp.UnixNano(),
+	)
+	return err
+}
+
+func (queries sqliteQueries) FindTransfer(id uuid.UUID) (models.Transfer, error) {
+	row := queries.db.QueryRow(`SELECT `+transferColumns+` FROM transfers WHERE id = ?`, id.String())
+	transfer, err := scanTransfer(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Transfer{}, utils.ErrTransferNotFound
+	}
+	return transfer, err
+}
+
+func (queries sqliteQueries) SearchTransfers(filter models.TransferFilter) ([]models.Transfer, error) {
+	// Build the conditions of the filter
+	conditions := []string{"1 = 1"}
+	arguments := []any{}
+	if filter.AccountID != uuid.Nil {
+		conditions = append(conditions, "(from_account_id = ? OR to_account_id = ?)")
+		arguments = append(arguments, filter.AccountID.String(), filter.AccountID.String())
+	}
+
+	// Continue after the cursor in the direction of the sort
+	order, comparison := "ASC", ">"
+	if filter.Descending {
+		order, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		conditions = append(conditions, "(timestamp, id) "+comparison+" (?, ?)")
+		arguments = append(arguments, filter.After.Value, filter.After.ID.String())
+	}
+	arguments = append(arguments, filter.Limit)
+
+	rows, err := queries.db.Query(
+		`SELECT `+transferColumns+` FROM transfers WHERE `+
+			strings.Join(conditions, " AND ")+` ORDER BY timestamp `+order+`, id `+order+` LIMIT ?`,
+		arguments...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []models.Transfer{}
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, rows.Err()
+}
+
+func (queries sqliteQueries) UpdateTransfer(transfer models.Transfer) error {
+	result, err := queries.db.Exec(
+		`UPDATE transfers SET from_account_id = ?, to_account_id = ?, currency = ?, amount = ?, scale = ?, status = ?, timestamp = ? WHERE id = ?`,
+		transfer.FromAccountID.String(), transfer.ToAccountID.String(), transfer.Currency, transfer.Amount.Units,
+		transfer.Amount.Scale, transfer.Status.String(), transfer.TimeStamp.UnixNano(), transfer.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, utils.ErrTransferNotFound)
+}
+
+func (queries sqliteQueries) FindTransactions(accountID uuid.UUID) ([]models.Transaction, error) {
+	rows, err := queries.db.Query(
+		`SELECT `+transactionColumns+` FROM transactions WHERE account_id = ? ORDER BY rowid`,
+		accountID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanTransactions(rows)
+}
+
+func (queries sqliteQueries) SearchTransactions(filter models.TransactionFilter) ([]models.Transaction, error) {
+	// Build the conditions of the filter
+	conditions := []string{"account_id = ?"}
+	arguments := []any{filter.AccountID.String()}
+	if filter.Type != nil {
+		conditions = append(conditions, "type = ?")
+		arguments = append(arguments, filter.Type.String())
+	}
+	if filter.MinAmount != nil {
+		conditions = append(conditions, "amount >= ?")
+		arguments = append(arguments, filter.MinAmount.Units)
+	}
+	if filter.MaxAmount != nil {
+		conditions = append(conditions, "amount <= ?")
+		arguments = append(arguments, filter.MaxAmount.Units)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		arguments = append(arguments, filter.From.UnixNano())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "timestamp < ?")
+		arguments = append(arguments, filter.To.UnixNano())
+	}
+
+	// Continue after the cursor in the direction of the sort
+	order, comparison := "ASC", ">"
+	if filter.Descending {
+		order, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		conditions = append(conditions, "(timestamp, id) "+comparison+" (?, ?)")
+		arguments = append(arguments, filter.After.Value, filter.After.ID.String())
+	}
+	arguments = append(arguments, filter.Limit)
+
+	rows, err := queries.db.Query(
+		`SELECT `+transactionColumns+` FROM transactions WHERE `+
+			strings.Join(conditions, " AND ")+` ORDER BY timestamp `+order+`, id `+order+` LIMIT ?`,
+		arguments...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanTransactions(rows)
+}
+
+func (queries sqliteQueries) CreateJournalEntry(entry models.JournalEntry) error {
+	_, err := queries.db.Exec(
+		`INSERT INTO journal_entries (id, description, timestamp) VALUES (?, ?, ?)`,
+		entry.ID.String(), entry.Description, entry.TimeStamp.UnixNano(),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Store the postings in their original order
+	for position, posting := range entry.Postings {
+		var transactionID sql.NullString
+		if posting.TransactionID != uuid.Nil {
+			transactionID = sql.NullString{String: posting.TransactionID.String(), Valid: true}
+		}
+
+		_, err := queries.db.Exec(
+			`INSERT INTO postings (entry_id, position, account_id, transaction_id, side, currency, amount, scale) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			entry.ID.String(), position, posting.AccountID.String(), transactionID, posting.Side.String(),
+			posting.Currency, posting.Amount.Units, posting.Amount.Scale,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (queries sqliteQueries) FindLedgerBalances() ([]models.LedgerBalance, error) {
+	rows, err := queries.db.Query(`
+		SELECT account_id, currency, MAX(scale),
+			SUM(CASE WHEN side = 'debit' THEN amount ELSE 0 END),
+			SUM(CASE WHEN side = 'credit' THEN amount ELSE 0 END)
+		FROM postings
+		GROUP BY account_id, currency
+		ORDER BY MIN(rowid)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []models.LedgerBalance{}
+	for rows.Next() {
+		var balance models.LedgerBalance
+		var accountID string
+		var scale int
+		if err := rows.Scan(&accountID, &balance.Currency, &scale, &balance.Debits.Units, &balance.Credits.Units); err != nil {
+			return nil, err
+		}
+		if balance.AccountID, err = uuid.Parse(accountID); err != nil {
+			return nil, err
+		}
+		balance.Debits.Scale = scale
+		balance.Credits.Scale = scale
+		balances = append(balances, balance)
+	}
+	return balances, rows.Err()
+}
+
+// holdColumns lists the columns read by scanHold
+const holdColumns = `id, account_id, amount, captured, scale, transaction_id, status, created_at, expires_at`
+
+func (queries sqliteQueries) CreateHold(hold models.Hold) error {
+	captured, err := capturedUnits(hold)
+	if err != nil {
+		return err
+	}
+	_, err = queries.db.Exec(
+		`INSERT INTO holds (`+holdColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		hold.ID.String(), hold.AccountID.String(), hold.Amount.Units, captured, hold.Amount.Scale,
+		nullableID(hold.TransactionID), hold.Status.String(), unixNano(hold.CreatedAt), unixNano(hold.ExpiresAt),
+	)
+	return err
+}
+
+func (queries sqliteQueries) FindHold(id uuid.UUID) (models.Hold, error) {
+	row := queries.db.QueryRow(`SELECT `+holdColumns+` FROM holds WHERE id = ?`, id.String())
+	hold, err := scanHold(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Hold{}, utils.ErrHoldNotFound
+	}
+	return hold, err
+}
+
+func (queries sqliteQueries) FindAccountHolds(accountID uuid.UUID) ([]models.Hold, error) {
+	rows, err := queries.db.Query(`SELECT `+holdColumns+` FROM holds WHERE account_id = ? ORDER BY rowid`, accountID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []models.Hold{}
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+func (queries sqliteQueries) UpdateHold(hold models.Hold) error {
+	captured, err := capturedUnits(hold)
+	if err != nil {
+		return err
+	}
+	result, err := queries.db.Exec(
+		`UPDATE holds SET account_id = ?, amount = ?, captured = ?, scale = ?, transaction_id = ?, status = ?, created_at = ?, expires_at = ? WHERE id = ?`,
+		hold.AccountID.String(), hold.Amount.Units, captured, hold.Amount.Scale,
+		nullableID(hold.TransactionID), hold.Status.String(), unixNano(hold.CreatedAt), unixNano(hold.ExpiresAt), hold.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, utils.ErrHoldNotFound)
+}
+
+// eventColumns lists the columns read by scanEvent
+const eventColumns = `sequence, type, account_id, counterparty_id, reference_id, currency, amount, scale, status, timestamp`
+
+func (queries sqliteQueries) AppendEvent(event models.Event) error {
+	_, err := queries.db.Exec(
+		`INSERT INTO events (type, account_id, counterparty_id, reference_id, currency, amount, scale, status, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.Type.String(), event.AccountID.String(), nullableID(event.CounterpartyID), nullableID(event.ReferenceID), event.Currency,
+		event.Amount.Units, event.Amount.Scale, event.Status.String(), event.TimeStamp.UnixNano(),
+	)
+	return err
+}
+
+func (queries sqliteQueries) FindEvents(after int64, limit int) ([]models.Event, error) {
+	rows, err := queries.db.Query(`SELECT `+eventColumns+` FROM events WHERE sequence > ? ORDER BY sequence LIMIT ?`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (queries sqliteQueries) FindAccountEvents(filter models.EventFilter) ([]models.Event, error) {
+	conditions := []string{"(account_id = ? OR counterparty_id = ?)", "sequence > ?"}
+	args := []any{filter.AccountID.String(), filter.AccountID.String(), filter.After}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, filter.Until.UnixNano())
+	}
+
+	rows, err := queries.db.Query(
+		`SELECT `+eventColumns+` FROM events WHERE `+strings.Join(conditions, " AND ")+` ORDER BY sequence`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+// checkpointColumns lists the columns read by scanCheckpoint
+const checkpointColumns = `account_id, version, currency, balance, held, overdraft, scale, status, updated_at`
+
+func (queries sqliteQueries) SaveBalanceCheckpoint(balance models.AccountBalance) error {
+	_, err := queries.db.Exec(
+		`INSERT INTO balance_checkpoints (`+checkpointColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		balance.AccountID.String(), balance.Version, balance.Currency, balance.Balance.Units, balance.Held.Units,
+		balance.Overdraft.Units, balance.Balance.Scale, balance.Status.String(), balance.UpdatedAt.UnixNano(),
+	)
+	return err
+}
+
+func (queries sqliteQueries) FindBalanceCheckpoint(accountID uuid.UUID, asOf time.Time) (models.AccountBalance, error) {
+	query := `SELECT ` + checkpointColumns + ` FROM balance_checkpoints WHERE account_id = ?`
+	args := []any{accountID.String()}
+	if !asOf.IsZero() {
+		query += ` AND updated_at <= ?`
+		args = append(args, asOf.UnixNano())
+	}
+
+	row := queries.db.QueryRow(query+` ORDER BY version DESC LIMIT 1`, args...)
+	balance, err := scanCheckpoint(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AccountBalance{}, utils.ErrCheckpointNotFound
+	}
+	return balance, err
+}
+
+func (queries sqliteQueries) SaveInterestAccrual(accrual models.InterestAccrual) error {
+	_, err := queries.db.Exec(
+		`INSERT INTO interest_accruals (account_id, date, balance, balance_scale, amount, amount_scale, transaction_id) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (account_id, date) DO UPDATE SET balance = excluded.balance, balance_scale = excluded.balance_scale,
+			amount = excluded.amount, amount_scale = excluded.amount_scale, transaction_id = excluded.transaction_id`,
+		accrual.AccountID.String(), unixNano(accrual.Date), accrual.Balance.Units, accrual.Balance.Scale,
+		accrual.Amount.Units, accrual.Amount.Scale, nullableID(accrual.TransactionID),
+	)
+	return err
+}
+
+func (queries sqliteQueries) FindInterestAccruals(accountID uuid.UUID, from time.Time, to time.Time) ([]models.InterestAccrual, error) {
+	conditions := []string{"account_id = ?"}
+	args := []any{accountID.String()}
+	if !from.IsZero() {
+		conditions = append(conditions, "date >= ?")
+		args = append(args, from.UnixNano())
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, "date < ?")
+		args = append(args, to.UnixNano())
+	}
+
+	rows, err := queries.db.Query(
+		`SELECT `+accrualColumns+` FROM interest_accruals WHERE `+strings.Join(conditions, " AND ")+` ORDER BY date`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accruals := []models.InterestAccrual{}
+	for rows.Next() {
+		accrual, err := scanInterestAccrual(rows)
+		if err != nil {
+			return nil, err
+		}
+		accruals = append(accruals, accrual)
+	}
+	return accruals, rows.Err()
+}
+
+func (queries sqliteQueries) FindLatestInterestAccrual(accountID uuid.UUID) (models.InterestAccrual, error) {
+	row := queries.db.QueryRow(
+		`SELECT `+accrualColumns+` FROM interest_accruals WHERE account_id = ? ORDER BY date DESC LIMIT 1`,
+		accountID.String(),
+	)
+	accrual, err := scanInterestAccrual(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.InterestAccrual{}, utils.ErrAccrualNotFound
+	}
+	return accrual, err
+}
+
+func (queries sqliteQueries) FindIdempotencyRecord(key string) (models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	var expiresAt int64
+	err := queries.db.QueryRow(
+		`SELECT key, fingerprint, completed, status_code, content_type, body, expires_at FROM idempotency_keys WHERE key = ?`, key,
+	).Scan(&record.Key, &record.Fingerprint, &record.Completed, &record.StatusCode, &record.ContentType, &record.Body, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.IdempotencyRecord{}, utils.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return models.IdempotencyRecord{}, err
+	}
+	record.ExpiresAt = time.Unix(0, expiresAt)
+	return record, nil
+}
+
+func (queries sqliteQueries) SaveIdempotencyRecord(record models.IdempotencyRecord) error {
+	_, err := queries.db.Exec(
+		`INSERT OR REPLACE INTO idempotency_keys (key, fingerprint, completed, status_code, content_type, body, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		record.Key, record.Fingerprint, record.Completed, record.StatusCode, record.ContentType, record.Body, record.ExpiresAt.UnixNano(),
+	)
+	return err
+}
+
+func (queries sqliteQueries) DeleteIdempotencyRecord(key string) error {
+	_, err := queries.db.Exec(`DELETE FROM idempotency_keys WHERE key = ?`, key)
+	return err
+}
+
+func (queries sqliteQueries) DeleteExpiredIdempotencyRecords(now time.Time) error {
+	_, err := queries.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, now.UnixNano())
+	return err
+}
+
+// requireAffected returns notFound when a statement did not change any row
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+func scanAccount(row scanner) (models.Account, error) {
+	var account models.Account
+	var id, dayCount, accountType, status string
+	var customerID, interestRate sql.NullString
+	var overdraft, held, maturesAt, createdAt int64
+	if err := row.Scan(&id, &customerID, &account.Owner, &account.Currency, &account.Balance.Units, &account.Balance.Scale, &overdraft, &held, &interestRate, &dayCount, &accountType, &maturesAt, &status, &createdAt); err != nil {
+		return models.Account{}, err
+	}
+
+	var err error
+	if account.ID, err = uuid.Parse(id); err != nil {
+		return models.Account{}, err
+	}
+	if customerID.Valid {
+		if account.CustomerID, err = uuid.Parse(customerID.String); err != nil {
+			return models.Account{}, err
+		}
+	}
+	if interestRate.Valid {
+		if account.InterestRate, err = money.ParseRate(interestRate.String); err != nil {
+			return models.Account{}, err
+		}
+	}
+	if account.DayCount, err = utils.ParseDayCount(dayCount); err != nil {
+		return models.Account{}, err
+	}
+	if account.Type, err = utils.ParseAccountType(accountType); err != nil {
+		return models.Account{}, err
+	}
+	if account.Status, err = utils.ParseAccountStatus(status); err != nil {
+		return models.Account{}, err
+	}
+	account.Overdraft = money.New(overdraft, account.Balance.Scale)
+	account.Held = money.New(held, account.Balance.Scale)
+	account.MaturesAt = fromUnixNano(maturesAt)
+	account.CreatedAt = fromUnixNano(createdAt)
+	return account, nil
+}
+
+// scanAccountHolders reads every account holder of a result set and closes it
+func scanAccountHolders(rows *sql.Rows) ([]models.AccountHolder, error) {
+	defer rows.Close()
+
+	holders := []models.AccountHolder{}
+	for rows.Next() {
+		var accountID, customerID, role string
+		if err := rows.Scan(&accountID, &customerID, &role); err != nil {
+			return nil, err
+		}
+
+		var holder models.AccountHolder
+		var err error
+		if holder.AccountID, err = uuid.Parse(accountID); err != nil {
+			return nil, err
+		}
+		if holder.CustomerID, err = uuid.Parse(customerID); err != nil {
+			return nil, err
+		}
+		if holder.Role, err = utils.ParseHolderRole(role); err != nil {
+			return nil, err
+		}
+		holders = append(holders, holder)
+	}
+	return holders, rows.Err()
+}
+
+func scanCustomer(row scanner) (models.Customer, error) {
+	var customer models.Customer
+	var id string
+	var createdAt int64
+	if err := row.Scan(&id, &customer.Name, &customer.Email, &createdAt); err != nil {
+		return models.Customer{}, err
+	}
+
+	var err error
+	if customer.ID, err = uuid.Parse(id); err != nil {
+		return models.Customer{}, err
+	}
+	customer.CreatedAt = fromUnixNano(createdAt)
+	return customer, nil
+}
+
+// accountUnits returns the overdraft limit and the held funds of an account
+// in minor units at the scale of its balance
+func accountUnits(account models.Account) (int64, int64, error) {
+	overdraft, err := account.Overdraft.Rescale(account.Balance.Scale)
+	if err != nil {
+		return 0, 0, err
+	}
+	held, err := account.Held.Rescale(account.Balance.Scale)
+	if err != nil {
+		return 0, 0, err
+	}
+	return overdraft.Units, held.Units, nil
+}
+
+// nullableRate stores an optional rate, or NULL when it is zero
+func nullableRate(rate money.Rate) sql.NullString {
+	if rate.Units == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: rate.String(), Valid: true}
+}
+
+// nullableID stores an optional reference, or NULL when it is uuid.Nil
+func nullableID(id uuid.UUID) sql.NullString {
+	if id == uuid.Nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: id.String(), Valid: true}
+}
+
+// scanAccounts reads every account of a result set and closes it
+func scanAccounts(rows *sql.Rows) ([]models.Account, error) {
+	defer rows.Close()
+
+	accounts := []models.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// unixNano stores a time as nanoseconds since the epoch, keeping the zero
+// time as 0
+func unixNano(value time.Time) int64 {
+	if value.IsZero() {
+		return 0
+	}
+	return value.UnixNano()
+}
+
+// fromUnixNano reverses unixNano
+func fromUnixNano(value int64) time.Time {
+	if value == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, value)
+}
+
+func scanTransaction(row scanner) (models.Transaction, error) {
+	var transaction models.Transaction
+	var id, accountID, transactionType string
+	var exchange, relatedID, transferID sql.NullString
+	var timestamp int64
+	if err := row.Scan(&id, &accountID, &transactionType, &transaction.Currency, &transaction.Amount.Units, &transaction.Amount.Scale, &exchange, &relatedID, &transferID, &timestamp); err != nil {
+		return models.Transaction{}, err
+	}
+
+	var err error
+	if transaction.ID, err = uuid.Parse(id); err != nil {
+		return models.Transaction{}, err
+	}
+	if transaction.AccountID, err = uuid.Parse(accountID); err != nil {
+		return models.Transaction{}, err
+	}
+	if transaction.Type, err = utils.ParseTransactionType(transactionType); err != nil {
+		return models.Transaction{}, err
+	}
+	if exchange.Valid {
+		transaction.Exchange = &models.Exchange{}
+		if err := json.Unmarshal([]byte(exchange.String), transaction.Exchange); err != nil {
+			return models.Transaction{}, err
+		}
+	}
+	if relatedID.Valid {
+		if transaction.RelatedID, err = uuid.Parse(relatedID.String); err != nil {
+			return models.Transaction{}, err
+		}
+	}
+	if transferID.Valid {
+		if transaction.TransferID, err = uuid.Parse(transferID.String); err != nil {
+			return models.Transaction{}, err
+		}
+	}
+	transaction.TimeStamp = time.Unix(0, timestamp)
+	return transaction, nil
+}
+
+// scanTransactions reads every transaction of a result set and closes it
+func scanTransactions(rows *sql.Rows) ([]models.Transaction, error) {
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, rows.Err()
+}
+
+// encodeJSON stores an optional value as JSON text, or NULL when it is nil
+func encodeJSON[T any](value *T) (sql.NullString, error) {
+	if value == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// accrualColumns lists the columns read by scanInterestAccrual
+const accrualColumns = `account_id, date, balance, balance_scale, amount, amount_scale, transaction_id`
+
+func scanInterestAccrual(row scanner) (models.InterestAccrual, error) {
+	var accrual models.InterestAccrual
+	var accountID string
+	var transactionID sql.NullString
+	var date int64
+	if err := row.Scan(&accountID, &date, &accrual.Balance.Units, &accrual.Balance.Scale, &accrual.Amount.Units, &accrual.Amount.Scale, &transactionID); err != nil {
+		return models.InterestAccrual{}, err
+	}
+
+	var err error
+	if accrual.AccountID, err = uuid.Parse(accountID); err != nil {
+		return models.InterestAccrual{}, err
+	}
+	if transactionID.Valid {
+		if accrual.TransactionID, err = uuid.Parse(transactionID.String); err != nil {
+			return models.InterestAccrual{}, err
+		}
+	}
+	accrual.Date = fromUnixNano(date).UTC()
+	return accrual, nil
+}
+
+// capturedUnits returns the captured amount of a hold in minor units at the
+// scale of the amount held
+func capturedUnits(hold models.Hold) (int64, error) {
+	captured, err := hold.Captured.Rescale(hold.Amount.Scale)
+	if err != nil {
+		return 0, err
+	}
+	return captured.Units, nil
+}
+
+func scanHold(row scanner) (models.Hold, error) {
+	var hold models.Hold
+	var id, accountID, status string
+	var transactionID sql.NullString
+	var captured, createdAt, expiresAt int64
+	if err := row.Scan(&id, &accountID, &hold.Amount.Units, &captured, &hold.Amount.Scale, &transactionID, &status, &createdAt, &expiresAt); err != nil {
+		return models.Hold{}, err
+	}
+
+	var err error
+	if hold.ID, err = uuid.Parse(id); err != nil {
+		return models.Hold{}, err
+	}
+	if hold.AccountID, err = uuid.Parse(accountID); err != nil {
+		return models.Hold{}, err
+	}
+	if transactionID.Valid {
+		if hold.TransactionID, err = uuid.Parse(transactionID.String); err != nil {
+			return models.Hold{}, err
+		}
+	}
+	if hold.Status, err = utils.ParseHoldStatus(status); err != nil {
+		return models.Hold{}, err
+	}
+	hold.Captured = money.New(captured, hold.Amount.Scale)
+	hold.CreatedAt = fromUnixNano(createdAt)
+	hold.ExpiresAt = fromUnixNano(expiresAt)
+	return hold, nil
+}
+
+func scanTransfer(row scanner) (models.Transfer, error) {
+	var transfer models.Transfer
+	var id, fromAccountID, toAccountID, status string
+	var timestamp int64
+	if err := row.Scan(&id, &fromAccountID, &toAccountID, &transfer.Currency, &transfer.Amount.Units, &transfer.Amount.Scale, &status, &timestamp); err != nil {
+		return models.Transfer{}, err
+	}
+
+	var err error
+	if transfer.ID, err = uuid.Parse(id); err != nil {
+		return models.Transfer{}, err
+	}
+	if transfer.FromAccountID, err = uuid.Parse(fromAccountID); err != nil {
+		return models.Transfer{}, err
+	}
+	if transfer.ToAccountID, err = uuid.Parse(toAccountID); err != nil {
+		return models.Transfer{}, err
+	}
+	if transfer.Status, err = utils.ParseTransferStatus(status); err != nil {
+		return models.Transfer{}, err
+	}
+	transfer.TimeStamp = time.Unix(0, timestamp)
+	return transfer, nil
+}
+
+func scanEvent(row scanner) (models.Event, error) {
+	var event models.Event
+	var eventType, accountID, status string
+	var counterpartyID, referenceID sql.NullString
+	var timestamp int64
+	if err := row.Scan(&event.Sequence, &eventType, &accountID, &counterpartyID, &referenceID, &event.Currency, &event.Amount.Units, &event.Amount.Scale, &status, &timestamp); err != nil {
+		return models.Event{}, err
+	}
+
+	var err error
+	if event.Type, err = utils.ParseEventType(eventType); err != nil {
+		return models.Event{}, err
+	}
+	if event.AccountID, err = uuid.Parse(accountID); err != nil {
+		return models.Event{}, err
+	}
+	if counterpartyID.Valid {
+		if event.CounterpartyID, err = uuid.Parse(counterpartyID.String); err != nil {
+			return models.Event{}, err
+		}
+	}
+	if referenceID.Valid {
+		if event.ReferenceID, err = uuid.Parse(referenceID.String); err != nil {
+			return models.Event{}, err
+		}
+	}
+	if event.Status, err = utils.ParseAccountStatus(status); err != nil {
+		return models.Event{}, err
+	}
+	event.TimeStamp = time.Unix(0, timestamp)
+	return event, nil
+}
+
+// scanEvents reads every event of a result set and closes it
+func scanEvents(rows *sql.Rows) ([]models.Event, error) {
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func scanCheckpoint(row scanner) (models.AccountBalance, error) {
+	var balance models.AccountBalance
+	var accountID, status string
+	var held, overdraft, updatedAt int64
+	if err := row.Scan(&accountID, &balance.Version, &balance.Currency, &balance.Balance.Units, &held, &overdraft, &balance.Balance.Scale, &status, &updatedAt); err != nil {
+		return models.AccountBalance{}, err
+	}
+
+	var err error
+	if balance.AccountID, err = uuid.Parse(accountID); err != nil {
+		return models.AccountBalance{}, err
+	}
+	if balance.Status, err = utils.ParseAccountStatus(status); err != nil {
+		return models.AccountBalance{}, err
+	}
+	balance.Held = money.New(held, balance.Balance.Scale)
+	balance.Overdraft = money.New(overdraft, balance.Balance.Scale)
+	balance.UpdatedAt = time.Unix(0, updatedAt)
+	return balance, nil
+}
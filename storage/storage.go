@@ -3,40 +3,183 @@ package storage
 import (
 	"bank-account-manager/models"
 	"bank-account-manager/utils"
-	"sync"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// Storage represents an in-memory data store for accounts and transactions
-// with thread-safe operations through mutex locking
-type Storage struct {
-	Accounts     []models.Account     // Slice containing all bank accounts
-	Transactions []models.Transaction // Slice containing all transactions
-	Mutex        *sync.Mutex          // Mutex for thread-safe operations
+// Supported storage driver names
+const (
+	DriverMemory  = "memory"
+	DriverSQLite  = "sqlite"
+	DriverJournal = "journal"
+)
+
+// Config selects and configures a storage backend
+type Config struct {
+	Driver        string // Backend name, defaults to DriverMemory when empty
+	Path          string // Database or journal file used by file-backed drivers
+	SnapshotEvery int    // Journal records between snapshots, DefaultSnapshotEvery when 0
 }
 
-// Create initializes and returns a new Storage instance with empty
-// accounts and transactions slices and a mutex lock
-func Create() *Storage {
-	accounts := []models.Account{}
-	transactions := []models.Transaction{}
-	lock := sync.Mutex{}
-
-	return &Storage{
-		Accounts:     accounts,
-		Transactions: transactions,
-		Mutex:        &lock,
-	}
+// Storage is the persistence contract used by the services. Implementations
+// must be safe for concurrent use by multiple goroutines. Calls outside a
+// unit of work run as a unit of their own, so a goroutine running a unit
+// must not call the store it was started from until the unit ends
+type Storage interface {
+	// CreateAccount adds a new account to the store
+	CreateAccount(account models.Account) error
+	// FindAccount returns the account with the given ID or ErrAccountNotFound
+	FindAccount(id uuid.UUID) (models.Account, error)
+	// FindAccounts returns a copy of all accounts in creation order
+	FindAccounts() ([]models.Account, error)
+	// SearchAccounts returns up to filter.Limit accounts matching the filter,
+	// ordered by the sort value of the filter and then ID
+	SearchAccounts(filter models.AccountFilter) ([]models.Account, error)
+	// UpdateAccount replaces the stored account with the same ID
+	UpdateAccount(account models.Account) error
+
+	// FindCustomerAccounts returns the accounts of a customer in creation order
+	FindCustomerAccounts(customerID uuid.UUID) ([]models.Account, error)
+
+	// FindAccountHolders returns the holders of an account in the order they
+	// were added
+	FindAccountHolders(accountID uuid.UUID) ([]models.AccountHolder, error)
+	// FindCustomerHoldings returns the holdings of a customer on every account
+	FindCustomerHoldings(customerID uuid.UUID) ([]models.AccountHolder, error)
+	// SaveAccountHolder adds a holder to an account or replaces their role
+	SaveAccountHolder(holder models.AccountHolder) error
+	// DeleteAccountHolder removes a holder from an account or returns
+	// ErrHolderNotFound
+	DeleteAccountHolder(accountID uuid.UUID, customerID uuid.UUID) error
+
+	// CreateCustomer adds a new customer to the store
+	CreateCustomer(customer models.Customer) error
+	// FindCustomer returns the customer with the given ID or ErrCustomerNotFound
+	FindCustomer(id uuid.UUID) (models.Customer, error)
+	// FindCustomers returns a copy of all customers in creation order
+	FindCustomers() ([]models.Customer, error)
+	// UpdateCustomer replaces the stored customer with the same ID
+	UpdateCustomer(customer models.Customer) error
+	// DeleteCustomer removes the customer with the given ID
+	DeleteCustomer(id uuid.UUID) error
+
+	// CreateTransaction adds a new transaction to the store
+	CreateTransaction(transaction models.Transaction) error
+	// FindTransaction returns the transaction with the given ID or
+	// ErrTransactionNotFound
+	FindTransaction(id uuid.UUID) (models.Transaction, error)
+	// FindTransactions returns the transactions of an account in creation order
+	FindTransactions(accountID uuid.UUID) ([]models.Transaction, error)
+	// FindRelatedTransactions returns the transactions linked to the given
+	// one by their RelatedID in creation order
+	FindRelatedTransactions(id uuid.UUID) ([]models.Transaction, error)
+	// FindTransferTransactions returns the legs of a transfer and their fees
+	// in creation order
+	FindTransferTransactions(transferID uuid.UUID) ([]models.Transaction, error)
+	// CreateTransfer adds a new transfer to the store
+	CreateTransfer(transfer models.Transfer) error
+	// FindTransfer returns the transfer with the given ID or ErrTransferNotFound
+	FindTransfer(id uuid.UUID) (models.Transfer, error)
+	// SearchTransfers returns up to filter.Limit transfers matching the
+	// filter, ordered by timestamp and ID
+	SearchTransfers(filter models.TransferFilter) ([]models.Transfer, error)
+	// UpdateTransfer replaces the stored transfer with the same ID or returns
+	// ErrTransferNotFound
+	UpdateTransfer(transfer models.Transfer) error
+	// SearchTransactions returns up to filter.Limit transactions of an
+	// account matching the filter, ordered by timestamp and then ID
+	SearchTransactions(filter models.TransactionFilter) ([]models.Transaction, error)
+
+	// CreateJournalEntry adds a journal entry together with its postings
+	CreateJournalEntry(entry models.JournalEntry) error
+	// FindLedgerBalances returns the posting totals of every ledger account
+	// and currency that has postings
+	FindLedgerBalances() ([]models.LedgerBalance, error)
+
+	// CreateHold adds a new hold to the store
+	CreateHold(hold models.Hold) error
+	// FindHold returns the hold with the given ID or ErrHoldNotFound
+	FindHold(id uuid.UUID) (models.Hold, error)
+	// FindAccountHolds returns the holds of an account in creation order
+	FindAccountHolds(accountID uuid.UUID) ([]models.Hold, error)
+	// UpdateHold replaces the stored hold with the same ID
+	UpdateHold(hold models.Hold) error
+
+	// AppendEvent adds an event to the end of the event store, numbering it
+	// after the last one. Events are never changed once appended
+	AppendEvent(event models.Event) error
+	// FindEvents returns up to limit events numbered after the given
+	// sequence, in order
+	FindEvents(after int64, limit int) ([]models.Event, error)
+	// FindAccountEvents returns the events of an account matching the
+	// filter, including the transfers it received, in order
+	FindAccountEvents(filter models.EventFilter) ([]models.Event, error)
+	// SaveBalanceCheckpoint stores the projected balance of an account at
+	// its version
+	SaveBalanceCheckpoint(balance models.AccountBalance) error
+	// FindBalanceCheckpoint returns the latest checkpoint of an account
+	// whose events all happened no later than asOf, the latest of all when
+	// asOf is zero, or ErrCheckpointNotFound
+	FindBalanceCheckpoint(accountID uuid.UUID, asOf time.Time) (models.AccountBalance, error)
+
+	// SaveInterestAccrual adds the accrual of an account for a day or
+	// replaces the one already stored for that day
+	SaveInterestAccrual(accrual models.InterestAccrual) error
+	// FindInterestAccruals returns the accruals of an account dated from
+	// from up to, but excluding, to in date order. Zero times leave the
+	// range open
+	FindInterestAccruals(accountID uuid.UUID, from time.Time, to time.Time) ([]models.InterestAccrual, error)
+	// FindLatestInterestAccrual returns the most recent accrual of an
+	// account or ErrAccrualNotFound
+	FindLatestInterestAccrual(accountID uuid.UUID) (models.InterestAccrual, error)
+
+	// FindIdempotencyRecord returns the record of an idempotency key or
+	// ErrIdempotencyKeyNotFound
+	FindIdempotencyRecord(key string) (models.IdempotencyRecord, error)
+	// SaveIdempotencyRecord adds or replaces the record of an idempotency key
+	SaveIdempotencyRecord(record models.IdempotencyRecord) error
+	// DeleteIdempotencyRecord removes the record of an idempotency key, if any
+	DeleteIdempotencyRecord(key string) error
+	// DeleteExpiredIdempotencyRecords removes the records expired at the given time
+	DeleteExpiredIdempotencyRecords(now time.Time) error
+
+	// Atomic runs fn as a single unit of work. Every change made through the
+	// store passed to fn is committed when fn returns nil and discarded when
+	// it returns an error. Calling Atomic, Lock or View on that store joins
+	// the running unit. No other unit of work runs alongside it
+	Atomic(fn func(store Storage) error) error
+	// Lock runs fn as a unit of work like Atomic, isolated only from the
+	// units locking or viewing any of the given IDs. fn may only change the
+	// records of those IDs, such as accounts and customers and everything
+	// filed under them, and records owned by no ID, such as the journal.
+	// IDs are locked in a fixed order, so units locking several, such as
+	// both accounts of a transfer, never deadlock
+	Lock(ids []uuid.UUID, fn func(store Storage) error) error
+	// View runs fn as a unit of work that only reads. With IDs it waits for
+	// the units locking them and runs alongside other readers of them.
+	// Without IDs it reads the whole store while no unit changes anything
+	View(ids []uuid.UUID, fn func(store Storage) error) error
 }
 
-// FindAccount searches for an account by its UUID and returns its index
-// in the Accounts slice. Returns -1 and ErrAccountNotFound if not found
-func (storage *Storage) FindAccount(id uuid.UUID) (int, error) {
-	for index, account := range storage.Accounts {
-		if account.ID == id {
-			return index, nil
+// Open creates the storage backend selected by the given configuration
+func Open(config Config) (Storage, error) {
+	switch config.Driver {
+	case "", DriverMemory:
+		return Create(), nil
+	case DriverSQLite:
+		sqlite, err := CreateSQLite(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		return sqlite, nil
+	case DriverJournal:
+		memory, err := CreateJournaled(config.Path, config.SnapshotEvery)
+		if err != nil {
+			return nil, err
 		}
+		return memory, nil
 	}
-	return -1, utils.ErrAccountNotFound
+	return nil, fmt.Errorf("%w: %s", utils.ErrUnknownStorageDriver, config.Driver)
 }
@@ -0,0 +1,438 @@
+package storage
+
+import (
+	"bank-account-manager/models"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSnapshotEvery is how many committed units of work the write-ahead
+// log holds before the store is compacted into a snapshot
+const DefaultSnapshotEvery = 1000
+
+// walHeaderSize is the size of the length and checksum written before the
+// payload of every record
+const walHeaderSize = 8
+
+// walMaxRecordSize bounds the payload length read from a record header so
+// that a corrupted length cannot make replay allocate unbounded memory
+const walMaxRecordSize = 64 << 20
+
+// crcTable is the Castagnoli polynomial table checksumming every record
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Kinds of the mutations recorded in the write-ahead log
+const (
+	walCreateAccount                   = "create_account"
+	walUpdateAccount                   = "update_account"
+	walCreateCustomer                  = "create_customer"
+	walUpdateCustomer                  = "update_customer"
+	walDeleteCustomer                  = "delete_customer"
+	walSaveAccountHolder               = "save_account_holder"
+	walDeleteAccountHolder             = "delete_account_holder"
+	walCreateTransaction               = "create_transaction"
+	walCreateTransfer                  = "create_transfer"
+	walUpdateTransfer                  = "update_transfer"
+	walCreateJournalEntry              = "create_journal_entry"
+	walSaveInterestAccrual             = "save_interest_accrual"
+	walCreateHold                      = "create_hold"
+	walUpdateHold                      = "update_hold"
+	walAppendEvent                     = "append_event"
+	walSaveBalanceCheckpoint           = "save_balance_checkpoint"
+	walSaveIdempotencyRecord           = "save_idempotency_record"
+	walDeleteIdempotencyRecord         = "delete_idempotency_record"
+	walDeleteExpiredIdempotencyRecords = "delete_expired_idempotency_records"
+)
+
+// walOperation is one mutation of a unit of work. Only the fields used by
+// its kind are set
+type walOperation struct {
+	Kind        string
+	Account     *models.Account
+	Customer    *models.Customer
+	Holder      *models.AccountHolder
+	Transaction *models.Transaction
+	Transfer    *models.Transfer
+	Entry       *models.JournalEntry
+	Accrual     *models.InterestAccrual
+	Hold        *models.Hold
+	Event       *models.Event
+	Checkpoint  *models.AccountBalance
+	Idempotency *models.IdempotencyRecord
+	ID          uuid.UUID // Customer or account to delete from
+	OtherID     uuid.UUID // Customer of the account holder to delete
+	Key         string    // Idempotency key to delete
+	Time        time.Time // Time idempotency records expire at
+}
+
+// walRecord holds the mutations of one committed unit of work, numbered so
+// that records already compacted into a snapshot are skipped on replay
+type walRecord struct {
+	Sequence   uint64
+	Operations []walOperation
+}
+
+// memorySnapshot is the whole content of a Memory store after the record
+// with the given sequence number
+type memorySnapshot struct {
+	Sequence     uint64
+	Accounts     []models.Account
+	Customers    []models.Customer
+	Holders      []models.AccountHolder
+	Transactions []models.Transaction
+	Journal      []models.JournalEntry
+	Accruals     []models.InterestAccrual
+	Holds        []models.Hold
+	Transfers    []models.Transfer
+	Events       []models.Event
+	Checkpoints  []models.AccountBalance
+	Idempotency  map[string]models.IdempotencyRecord
+}
+
+// writeAheadLog appends the units of work committed to a Memory store to an
+// fsync'd file. Every record is written as its payload length and CRC-32C
+// checksum followed by the gob encoded walRecord. Units of work committing
+// concurrently append their records one at a time
+type writeAheadLog struct {
+	mutex         sync.Mutex
+	file          *os.File
+	path          string // Log file, the snapshot is kept next to it
+	sequence      uint64 // Sequence number of the last record written
+	records       int    // Records written since the last snapshot
+	snapshotEvery int    // Records after which the store is compacted
+}
+
+// CreateJournaled opens an in-memory Storage made durable by the
+// write-ahead log at path. The latest snapshot is loaded and the log
+// replayed on top of it. A torn or corrupted record at the end of the log,
+// left by a crash while it was written, is truncated away
+func CreateJournaled(path string, snapshotEvery int) (*Memory, error) {
+	if snapshotEvery <= 0 {
+		snapshotEvery = DefaultSnapshotEvery
+	}
+
+	// Load the state compacted into the snapshot, if any
+	memory := Create()
+	snapshot, err := readSnapshot(snapshotPath(path))
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil {
+		memory.restore(*snapshot)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	wal := &writeAheadLog{file: file, path: path, snapshotEvery: snapshotEvery}
+	if snapshot != nil {
+		wal.sequence = snapshot.Sequence
+	}
+
+	// Replay the records written after the snapshot
+	if err := wal.replay(memory); err != nil {
+		file.Close()
+		return nil, err
+	}
+	memory.wal = wal
+	return memory, nil
+}
+
+// Close closes the write-ahead log of a journaled store
+func (memory *Memory) Close() error {
+	defer memory.locks.lock(changingAll)()
+
+	if memory.wal == nil {
+		return nil
+	}
+	err := memory.wal.file.Close()
+	memory.wal = nil
+	return err
+}
+
+// replay applies every intact record of the log to the store and truncates
+// the log after the last one
+func (wal *writeAheadLog) replay(memory *Memory) error {
+	offset := int64(0)
+	for {
+		record, size, err := readRecord(wal.file)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Cut the torn or corrupted tail so new records follow the
+			// last intact one
+			if err := wal.file.Truncate(offset); err != nil {
+				return err
+			}
+			if err := wal.file.Sync(); err != nil {
+				return err
+			}
+			break
+		}
+		offset += size
+
+		// Records up to the snapshot are already part of the store
+		if record.Sequence <= wal.sequence {
+			continue
+		}
+		tx := &memoryTx{memory: memory}
+		for _, operation := range record.Operations {
+			if err := tx.apply(operation); err != nil {
+				return fmt.Errorf("replay record %d: %w", record.Sequence, err)
+			}
+		}
+		wal.sequence = record.Sequence
+		wal.records++
+	}
+
+	_, err := wal.file.Seek(offset, io.SeekStart)
+	return err
+}
+
+// append writes the operations of a unit of work as one record and waits
+// until it reached the disk
+func (wal *writeAheadLog) append(operations []walOperation) error {
+	wal.mutex.Lock()
+	defer wal.mutex.Unlock()
+
+	var payload bytes.Buffer
+	record := walRecord{Sequence: wal.sequence + 1, Operations: operations}
+	if err := gob.NewEncoder(&payload).Encode(record); err != nil {
+		return err
+	}
+
+	header := make([]byte, walHeaderSize)
+	binary.LittleEndian.PutUint32(header[0:4], uint32(payload.Len()))
+	binary.LittleEndian.PutUint32(header[4:8], crc32.Checksum(payload.Bytes(), crcTable))
+	if _, err := wal.file.Write(append(header, payload.Bytes()...)); err != nil {
+		return err
+	}
+	if err := wal.file.Sync(); err != nil {
+		return err
+	}
+
+	wal.sequence = record.Sequence
+	wal.records++
+	return nil
+}
+
+// due reports whether the log holds enough records to be compacted
+func (wal *writeAheadLog) due() bool {
+	wal.mutex.Lock()
+	defer wal.mutex.Unlock()
+	return wal.records >= wal.snapshotEvery
+}
+
+// compact writes the whole store to a new snapshot and empties the log. The
+// snapshot replaces the previous one atomically, and the sequence number it
+// holds keeps replay correct should the log not be emptied before a crash.
+// It must be called with no other unit of work running
+func (wal *writeAheadLog) compact(memory *Memory) error {
+	wal.mutex.Lock()
+	defer wal.mutex.Unlock()
+
+	snapshot := memory.snapshot()
+	snapshot.Sequence = wal.sequence
+	if err := writeSnapshot(snapshotPath(wal.path), snapshot); err != nil {
+		return err
+	}
+
+	if err := wal.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := wal.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := wal.file.Sync(); err != nil {
+		return err
+	}
+	wal.records = 0
+	return nil
+}
+
+// readRecord reads the next record and the number of bytes it took. It
+// returns io.EOF at the clean end of the log and another error for a torn
+// or corrupted record
+func readRecord(reader io.Reader) (walRecord, int64, error) {
+	header := make([]byte, walHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		if err == io.EOF {
+			return walRecord{}, 0, io.EOF
+		}
+		return walRecord{}, 0, err
+	}
+
+	length := binary.LittleEndian.Uint32(header[0:4])
+	if length > walMaxRecordSize {
+		return walRecord{}, 0, errors.New("write-ahead log record too large")
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return walRecord{}, 0, err
+	}
+	if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:8]) {
+		return walRecord{}, 0, errors.New("write-ahead log record checksum mismatch")
+	}
+
+	var record walRecord
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&record); err != nil {
+		return walRecord{}, 0, err
+	}
+	return record, int64(walHeaderSize + length), nil
+}
+
+// snapshotPath returns the snapshot file kept next to a log file
+func snapshotPath(path string) string {
+	return path + ".snapshot"
+}
+
+// readSnapshot loads a snapshot file, returning nil when there is none
+func readSnapshot(path string) (*memorySnapshot, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var snapshot memorySnapshot
+	if err := gob.NewDecoder(file).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// writeSnapshot writes a snapshot to a temporary file and renames it over
+// the previous one once it reached the disk
+func writeSnapshot(path string, snapshot memorySnapshot) error {
+	temporary := path + ".tmp"
+	file, err := os.Create(temporary)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(file).Encode(snapshot); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return err
+	}
+
+	// Make the rename itself durable
+	directory, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer directory.Close()
+	return directory.Sync()
+}
+
+// snapshot copies the content of the store. It must be called with the
+// store locked
+func (memory *Memory) snapshot() memorySnapshot {
+	return memorySnapshot{
+		Accounts:     memory.accounts,
+		Customers:    memory.customers,
+		Holders:      memory.holders,
+		Transactions: memory.transactions,
+		Journal:      memory.journal,
+		Accruals:     memory.accruals,
+		Holds:        memory.holds,
+		Transfers:    memory.transfers,
+		Events:       memory.events,
+		Checkpoints:  memory.checkpoints,
+		Idempotency:  memory.idempotency,
+	}
+}
+
+// restore replaces the content of the store with a snapshot
+func (memory *Memory) restore(snapshot memorySnapshot) {
+	memory.accounts = append(memory.accounts, snapshot.Accounts...)
+	memory.customers = append(memory.customers, snapshot.Customers...)
+	memory.holders = append(memory.holders, snapshot.Holders...)
+	memory.transactions = append(memory.transactions, snapshot.Transactions...)
+	memory.journal = append(memory.journal, snapshot.Journal...)
+	memory.accruals = append(memory.accruals, snapshot.Accruals...)
+	memory.holds = append(memory.holds, snapshot.Holds...)
+	memory.transfers = append(memory.transfers, snapshot.Transfers...)
+	memory.events = append(memory.events, snapshot.Events...)
+	memory.checkpoints = append(memory.checkpoints, snapshot.Checkpoints...)
+	for key, record := range snapshot.Idempotency {
+		memory.idempotency[key] = record
+	}
+	memory.reindex()
+}
+
+// record remembers a mutation of the unit of work for the write-ahead log
+func (tx *memoryTx) record(operation walOperation) {
+	tx.operations = append(tx.operations, operation)
+}
+
+// apply replays a mutation read from the write-ahead log
+func (tx *memoryTx) apply(operation walOperation) error {
+	switch operation.Kind {
+	case walCreateAccount:
+		return tx.CreateAccount(*operation.Account)
+	case walUpdateAccount:
+		return tx.UpdateAccount(*operation.Account)
+	case walCreateCustomer:
+		return tx.CreateCustomer(*operation.Customer)
+	case walUpdateCustomer:
+		return tx.UpdateCustomer(*operation.Customer)
+	case walDeleteCustomer:
+		return tx.DeleteCustomer(operation.ID)
+	case walSaveAccountHolder:
+		return tx.SaveAccountHolder(*operation.Holder)
+	case walDeleteAccountHolder:
+		return tx.DeleteAccountHolder(operation.ID, operation.OtherID)
+	case walCreateTransaction:
+		return tx.CreateTransaction(*operation.Transaction)
+	case walCreateTransfer:
+		return tx.CreateTransfer(*operation.Transfer)
+	case walUpdateTransfer:
+		return tx.UpdateTransfer(*operation.Transfer)
+	case walCreateJournalEntry:
+		return tx.CreateJournalEntry(*operation.Entry)
+	case walSaveInterestAccrual:
+		return tx.SaveInterestAccrual(*operation.Accrual)
+	case walCreateHold:
+		return tx.CreateHold(*operation.Hold)
+	case walUpdateHold:
+		return tx.UpdateHold(*operation.Hold)
+	case walAppendEvent:
+		defer tx.memory.writing(eventRecords)()
+		tx.appendEvent(*operation.Event, operation.Event.Sequence)
+		return nil
+	case walSaveBalanceCheckpoint:
+		return tx.SaveBalanceCheckpoint(*operation.Checkpoint)
+	case walSaveIdempotencyRecord:
+		return tx.SaveIdempotencyRecord(*operation.Idempotency)
+	case walDeleteIdempotencyRecord:
+		return tx.DeleteIdempotencyRecord(operation.Key)
+	case walDeleteExpiredIdempotencyRecords:
+		return tx.DeleteExpiredIdempotencyRecords(operation.Time)
+	}
+	return fmt.Errorf("unknown write-ahead log operation: %s", operation.Kind)
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"bank-account-manager/middlewares"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// createAdminApp returns an app whose GET /admin endpoint is guarded by the
+// given admin token
+func createAdminApp(token string) *fiber.App {
+	app := fiber.New()
+	app.Get("/admin", middlewares.AdminOnly(token), func(context *fiber.Ctx) error {
+		return context.SendStatus(http.StatusNoContent)
+	})
+	return app
+}
+
+// call requests GET /admin with the given admin token and returns the status
+func call(t *testing.T, app *fiber.App, token string) int {
+	request := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	if token != "" {
+		request.Header.Set(middlewares.AdminTokenHeader, token)
+	}
+	response, err := app.Test(request)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return response.StatusCode
+}
+
+func TestAdminOnlyChecksToken(t *testing.T) {
+	// Setup
+	app := createAdminApp("secret")
+
+	// Only the configured token is let through
+	if status := call(t, app, "secret"); status != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, status)
+	}
+	for _, token := range []string{"", "wrong"} {
+		if status := call(t, app, token); status != http.StatusUnauthorized {
+			t.Errorf("Expected status %d for %q, got %d", http.StatusUnauthorized, token, status)
+		}
+	}
+}
+
+func TestAdminOnlyWithoutTokenRejectsEverything(t *testing.T) {
+	// Setup
+	app := createAdminApp("")
+
+	// Without a configured token no request gets through, whatever it sends
+	for _, token := range []string{"", "anything"} {
+		if status := call(t, app, token); status != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d for %q, got %d", http.StatusServiceUnavailable, token, status)
+		}
+	}
+}
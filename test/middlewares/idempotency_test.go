@@ -0,0 +1,94 @@
+package test
+
+import (
+	"bank-account-manager/middlewares"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// createApp returns an app whose POST /deposits endpoint counts its calls
+func createApp(calls *int) *fiber.App {
+	app := fiber.New()
+	app.Use(middlewares.Idempotency(services.CreateIdempotencyService(storage.Create())))
+	app.Post("/deposits", func(context *fiber.Ctx) error {
+		*calls++
+		return context.Status(http.StatusCreated).JSON(fiber.Map{"call": *calls})
+	})
+	return app
+}
+
+// send posts body to /deposits with the given idempotency key
+func send(t *testing.T, app *fiber.App, key string, body string) (*http.Response, string) {
+	request := httptest.NewRequest(http.MethodPost, "/deposits", strings.NewReader(body))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		request.Header.Set(middlewares.IdempotencyKeyHeader, key)
+	}
+	response, err := app.Test(request)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := io.ReadAll(response.Body)
+	return response, string(data)
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	// Setup
+	calls := 0
+	app := createApp(&calls)
+
+	// Send the same request twice with one key
+	first, firstBody := send(t, app, "key", `{"amount":10}`)
+	second, secondBody := send(t, app, "key", `{"amount":10}`)
+
+	// Validate the handler ran once and the response was replayed
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+	if second.StatusCode != first.StatusCode || secondBody != firstBody {
+		t.Errorf("Expected replay of %d %s, got %d %s", first.StatusCode, firstBody, second.StatusCode, secondBody)
+	}
+	if second.Header.Get(middlewares.IdempotencyReplayedHeader) != "true" {
+		t.Errorf("Expected the replayed header to be set")
+	}
+}
+
+func TestIdempotencyRejectsDifferentBody(t *testing.T) {
+	// Setup
+	calls := 0
+	app := createApp(&calls)
+
+	// Reuse a key with another body
+	send(t, app, "key", `{"amount":10}`)
+	response, _ := send(t, app, "key", `{"amount":20}`)
+
+	// Validate the retry was rejected without running the handler
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, response.StatusCode)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	// Setup
+	calls := 0
+	app := createApp(&calls)
+
+	// Send the same request twice without a key
+	for index := 0; index < 2; index++ {
+		_, body := send(t, app, "", `{"amount":10}`)
+		if body != `{"call":`+strconv.Itoa(index+1)+`}` {
+			t.Errorf("Expected call %d, got %s", index+1, body)
+		}
+	}
+}
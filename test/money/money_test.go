@@ -0,0 +1,247 @@
+package test
+
+import (
+	"bank-account-manager/money"
+	"bank-account-manager/utils"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	// Test data: input text, scale and expected minor units
+	cases := []struct {
+		text  string
+		scale int
+		units int64
+	}{
+		{"100", 2, 10000},
+		{"0.1", 2, 10},
+		{"12.34", 2, 1234},
+		{"-5.5", 2, -550},
+		{"7", 0, 7},
+		{"1.005", 3, 1005},
+	}
+
+	for _, testCase := range cases {
+		amount, err := money.Parse(testCase.text, testCase.scale)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got %v", testCase.text, err)
+		}
+		if amount.Units != testCase.units || amount.Scale != testCase.scale {
+			t.Errorf("Expected %d at scale %d for %q, got %+v", testCase.units, testCase.scale, testCase.text, amount)
+		}
+	}
+}
+
+func TestParseRejectsInvalidText(t *testing.T) {
+	for _, text := range []string{"", "abc", "1e3", "1.", ".5", "1,000.00", "+1", "1.2.3", "99999999999999999999"} {
+		if _, err := money.Parse(text, 2); err != utils.ErrInvalidAmount {
+			t.Errorf("Expected error %v for %q, got %v", utils.ErrInvalidAmount, text, err)
+		}
+	}
+}
+
+func TestParseRejectsExtraDecimals(t *testing.T) {
+	// Amounts must not be rounded to fit the scale
+	if _, err := money.Parse("0.001", 2); err != utils.ErrAmountPrecision {
+		t.Errorf("Expected error %v, got %v", utils.ErrAmountPrecision, err)
+	}
+	if _, err := money.Parse("1.5", 0); err != utils.ErrAmountPrecision {
+		t.Errorf("Expected error %v, got %v", utils.ErrAmountPrecision, err)
+	}
+}
+
+func TestRepeatedAdditionDoesNotDrift(t *testing.T) {
+	// Adding 0.1 ten times must give exactly 1
+	total := money.Zero(2)
+	step, _ := money.Parse("0.1", 2)
+	for index := 0; index < 10; index++ {
+		total, _ = total.Add(step)
+	}
+	if total.Cmp(money.New(100, 2)) != 0 {
+		t.Errorf("Expected 1.00, got %s", total)
+	}
+}
+
+func TestParseRejectsOverflow(t *testing.T) {
+	// Amounts must fit in the minor unit of every currency
+	if _, err := money.Parse("92233720368547758.07", 2); err != utils.ErrInvalidAmount {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidAmount, err)
+	}
+	if _, err := money.Parse("9223372036854775.80", 2); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestArithmeticOverflow(t *testing.T) {
+	// Sums and differences leaving the range of int64 fail
+	largest := money.New(math.MaxInt64, 2)
+	if _, err := largest.Add(money.New(1, 2)); err != utils.ErrInvalidAmount {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidAmount, err)
+	}
+	if _, err := largest.Neg().Sub(money.New(1, 2)); err != utils.ErrInvalidAmount {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidAmount, err)
+	}
+
+	// Aligning an amount to a larger scale fails once it no longer fits
+	if _, err := largest.Add(money.New(1, 3)); err != utils.ErrInvalidAmount {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidAmount, err)
+	}
+	if largest.Cmp(money.New(1, 3)) != 1 {
+		t.Errorf("Expected %s to compare above 0.001", largest)
+	}
+
+	// Sums within range still add up
+	sum, err := largest.Add(largest.Neg())
+	if err != nil || !sum.IsZero() {
+		t.Errorf("Expected zero, got %s and %v", sum, err)
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := map[string]money.Money{
+		"0.00":   money.Zero(2),
+		"0.05":   money.New(5, 2),
+		"-0.05":  money.New(-5, 2),
+		"123.45": money.New(12345, 2),
+		"500":    money.New(500, 0),
+		"1.000":  money.New(1000, 3),
+	}
+	for expected, amount := range cases {
+		if amount.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, amount.String())
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	// Encode an amount as a JSON number and decode it back
+	amount := money.New(10010, 2)
+	data, err := json.Marshal(amount)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(data) != "100.10" {
+		t.Errorf("Expected 100.10, got %s", data)
+	}
+
+	var decoded money.Money
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if decoded != amount {
+		t.Errorf("Expected %+v, got %+v", amount, decoded)
+	}
+}
+
+func TestDecimalKeepsExactText(t *testing.T) {
+	// Both JSON numbers and strings are accepted verbatim
+	var request struct {
+		Number money.Decimal `json:"number"`
+		Text   money.Decimal `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(`{"number": 0.10000000000000001, "text": "12.30"}`), &request); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if request.Number != "0.10000000000000001" || request.Text != "12.30" {
+		t.Errorf("Expected exact text, got %q and %q", request.Number, request.Text)
+	}
+	if _, err := request.Number.Parse(2); err != utils.ErrAmountPrecision {
+		t.Errorf("Expected error %v, got %v", utils.ErrAmountPrecision, err)
+	}
+}
+
+func TestLookupCurrency(t *testing.T) {
+	// Test data: currency code and expected minor unit digits
+	cases := map[string]int{"USD": 2, "eur": 2, "JPY": 0, "BHD": 3, "KWD": 3}
+	for code, scale := range cases {
+		currency, err := money.LookupCurrency(code)
+		if err != nil {
+			t.Fatalf("Expected no error for %s, got %v", code, err)
+		}
+		if currency.Scale != scale {
+			t.Errorf("Expected scale %d for %s, got %d", scale, code, currency.Scale)
+		}
+	}
+
+	// Unknown codes are rejected
+	for _, code := range []string{"", "US", "XXX", "DOLLAR"} {
+		if _, err := money.LookupCurrency(code); err != utils.ErrInvalidCurrency {
+			t.Errorf("Expected error %v for %q, got %v", utils.ErrInvalidCurrency, code, err)
+		}
+	}
+}
+
+func TestRateConvert(t *testing.T) {
+	// Test data: rate, amount and target scale with the expected result
+	cases := []struct {
+		rate     string
+		amount   money.Money
+		scale    int
+		expected string
+	}{
+		{"0.92", money.New(10000, 2), 2, "92.00"},
+		{"149.505", money.New(1001, 2), 0, "1497"},
+		{"0.5", money.New(1, 2), 2, "0.00"}, // 0.005 ties to even
+		{"1.5", money.New(1, 2), 2, "0.02"}, // 0.015 ties to even
+		{"0.377", money.New(1, 0), 3, "0.377"},
+	}
+
+	for _, testCase := range cases {
+		rate, err := money.ParseRate(testCase.rate)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		converted, err := rate.Convert(testCase.amount, testCase.scale)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if converted.String() != testCase.expected {
+			t.Errorf("Expected %s * %s = %s, got %s", testCase.amount, testCase.rate, testCase.expected, converted)
+		}
+	}
+}
+
+func TestRateProrate(t *testing.T) {
+	rate, _ := money.ParseRate("0.05")
+
+	// A 30/360 day of 5% on 12000.00, kept to eight decimal places
+	prorated, err := rate.Prorate(money.New(1200000, 2), 1, 360, 8)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if prorated.String() != "1.66666667" {
+		t.Errorf("Expected 1.66666667, got %s", prorated)
+	}
+
+	// The fraction needs a positive denominator
+	if _, err := rate.Prorate(money.New(100, 2), 1, 0, 2); err != utils.ErrInvalidAmount {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidAmount, err)
+	}
+}
+
+func TestRateInverse(t *testing.T) {
+	rate, _ := money.ParseRate("0.8")
+	inverse, err := rate.Inverse()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if inverse.String() != "1.25" {
+		t.Errorf("Expected 1.25, got %s", inverse)
+	}
+
+	rate, _ = money.ParseRate("3")
+	inverse, _ = rate.Inverse()
+	if inverse.String() != "0.3333333333" {
+		t.Errorf("Expected 0.3333333333, got %s", inverse)
+	}
+}
+
+func TestParseRateRejectsInvalid(t *testing.T) {
+	for _, text := range []string{"", "0", "-1.2", "1/3", "abc", "0.0000000000001"} {
+		if _, err := money.ParseRate(text); err != utils.ErrInvalidRate {
+			t.Errorf("Expected error %v for %q, got %v", utils.ErrInvalidRate, text, err)
+		}
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"bank-account-manager/requests"
 	"bank-account-manager/services"
 	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestCreateAccount(t *testing.T) {
@@ -15,7 +18,7 @@ func TestCreateAccount(t *testing.T) {
 	// Test data
 	request := requests.AccountRequest{
 		Owner:          "John Doe",
-		InitialBalance: 1000,
+		InitialBalance: "1000",
 	}
 
 	// Create account
@@ -28,8 +31,87 @@ func TestCreateAccount(t *testing.T) {
 	if account.Owner != request.Owner {
 		t.Errorf("Expected owner %s, got %s", request.Owner, account.Owner)
 	}
-	if account.Balance != request.InitialBalance {
-		t.Errorf("Expected balance %f, got %f", request.InitialBalance, account.Balance)
+	if account.Balance.String() != "1000.00" {
+		t.Errorf("Expected balance %s, got %s", request.InitialBalance, account.Balance)
+	}
+}
+
+// Test that accounts and customers are dated by the clock of their service
+func TestCreateAccount_Clock(t *testing.T) {
+	// Setup
+	openedAt := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	storage := storage.Create()
+	customerService := services.CreateCustomerService(storage)
+	customerService.Clock = utils.FixedClock{Time: openedAt}
+	accountService := services.CreateAccountService(storage)
+	accountService.Clock = utils.FixedClock{Time: openedAt}
+	eventService := services.CreateEventService(storage)
+
+	// Open an account for a new customer
+	customer, err := customerService.Create(requests.CustomerRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	account, err := accountService.Create(requests.AccountRequest{CustomerID: customer.ID.String(), InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !customer.CreatedAt.Equal(openedAt) || !account.CreatedAt.Equal(openedAt) {
+		t.Errorf("Expected both created at %s, got %s and %s", openedAt, customer.CreatedAt, account.CreatedAt)
+	}
+
+	// Later changes are dated by the clock as well
+	frozenAt := openedAt.Add(time.Hour)
+	accountService.Clock = utils.FixedClock{Time: frozenAt}
+	if _, err := accountService.Freeze(account.ID.String()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	events, err := eventService.ReadByAccount(account.ID.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Type != utils.EventAccountStatusChanged || !last.TimeStamp.Equal(frozenAt) {
+		t.Errorf("Expected the status change at %s, got %+v", frozenAt, last)
+	}
+}
+
+func TestCreateAccount_Currency(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	service := services.CreateAccountService(storage)
+
+	// Accounts without a currency default to USD
+	account, err := service.Create(requests.AccountRequest{Owner: "John Doe", InitialBalance: "10"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.Currency != "USD" || account.Balance.String() != "10.00" {
+		t.Errorf("Expected 10.00 USD, got %s %s", account.Balance, account.Currency)
+	}
+
+	// JPY has no minor unit and BHD has three decimals
+	account, err = service.Create(requests.AccountRequest{Owner: "John Doe", Currency: "jpy", InitialBalance: "500"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.Currency != "JPY" || account.Balance.String() != "500" {
+		t.Errorf("Expected 500 JPY, got %s %s", account.Balance, account.Currency)
+	}
+	if _, err := service.Create(requests.AccountRequest{Owner: "John Doe", Currency: "JPY", InitialBalance: "500.5"}); err != utils.ErrAmountPrecision {
+		t.Errorf("Expected error %v, got %v", utils.ErrAmountPrecision, err)
+	}
+	account, err = service.Create(requests.AccountRequest{Owner: "John Doe", Currency: "BHD", InitialBalance: "1.005"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.Balance.String() != "1.005" {
+		t.Errorf("Expected 1.005 BHD, got %s", account.Balance)
+	}
+
+	// Unknown currency codes are rejected
+	if _, err := service.Create(requests.AccountRequest{Owner: "John Doe", Currency: "ABC", InitialBalance: "1"}); err != utils.ErrInvalidCurrency {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidCurrency, err)
 	}
 }
 
@@ -41,7 +123,7 @@ func TestReadAccount(t *testing.T) {
 	// Create an account
 	request := requests.AccountRequest{
 		Owner:          "Jane Doe",
-		InitialBalance: 500,
+		InitialBalance: "500",
 	}
 	account, _ := service.Create(request)
 
@@ -64,8 +146,8 @@ func TestReadAllAccounts(t *testing.T) {
 
 	// Create test accounts
 	accounts := []requests.AccountRequest{
-		{Owner: "Alice", InitialBalance: 1000},
-		{Owner: "Bob", InitialBalance: 2000},
+		{Owner: "Alice", InitialBalance: "1000"},
+		{Owner: "Bob", InitialBalance: "2000"},
 	}
 
 	for _, req := range accounts {
@@ -87,6 +169,66 @@ func TestReadAllAccounts(t *testing.T) {
 	}
 }
 
+// Test that searches filter balances within one currency and page without gaps
+func TestSearchAccounts(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	service := services.CreateAccountService(storage)
+	for _, request := range []requests.AccountRequest{
+		{Owner: "Alice", InitialBalance: "50"},
+		{Owner: "Malik", Currency: "JPY", InitialBalance: "700"},
+		{Owner: "Alina", Currency: "KWD", InitialBalance: "300.125"},
+		{Owner: "Ali Baba", InitialBalance: "120"},
+		{Owner: "Alicia", InitialBalance: "250"},
+		{Owner: "Sally", InitialBalance: "1000"},
+	} {
+		if _, err := service.Create(request); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Page through owners containing "ali" with a balance of at least 100
+	// dollars, largest balance first, one account at a time
+	query := requests.AccountQuery{Owner: "ALI", Currency: "usd", MinBalance: "100", SortBy: "balance", Sort: "desc", Limit: 1, Status: "active"}
+	owners := []string{}
+	for {
+		page, err := service.Search(query)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, account := range page.Accounts {
+			owners = append(owners, account.Owner)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	// Validate the accounts and their order
+	if strings.Join(owners, ",") != "Alicia,Ali Baba" {
+		t.Errorf("Expected owners Alicia,Ali Baba, got %s", strings.Join(owners, ","))
+	}
+
+	// Balances of different currencies are never compared
+	for _, query := range []requests.AccountQuery{{MinBalance: "100"}, {MaxBalance: "100"}, {SortBy: "balance"}} {
+		if _, err := service.Search(query); err != utils.ErrCurrencyRequired {
+			t.Errorf("Expected error %v for %+v, got %v", utils.ErrCurrencyRequired, query, err)
+		}
+	}
+	if _, err := service.Search(requests.AccountQuery{Currency: "XYZ"}); err != utils.ErrInvalidCurrency {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidCurrency, err)
+	}
+	if _, err := service.Search(requests.AccountQuery{Currency: "JPY", MinBalance: "0.5"}); err != utils.ErrAmountPrecision {
+		t.Errorf("Expected error %v, got %v", utils.ErrAmountPrecision, err)
+	}
+
+	// Attempt a search with an unknown status
+	if _, err := service.Search(requests.AccountQuery{Status: "dormant"}); err != utils.ErrInvalidQuery {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidQuery, err)
+	}
+}
+
 func TestReadAccountError(t *testing.T) {
 	// Setup
 	storage := storage.Create()
@@ -100,3 +242,116 @@ func TestReadAccountError(t *testing.T) {
 }
 
 // ... additional tests for ReadAll and error cases ...
+
+// Test the status rules of frozen, closed and reopened accounts
+func TestAccountLifecycle(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	other, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "100"})
+	id := account.ID.String()
+
+	// A frozen account receives money but cannot send it
+	if _, err := accountService.Freeze(id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := transactionService.Create(id, requests.TransactionRequest{Type: "deposit", Amount: "10"}); err != nil {
+		t.Errorf("Expected frozen account to accept deposits, got %v", err)
+	}
+	if _, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: other.ID.String(), ToAccountID: id, Amount: "10"}); err != nil {
+		t.Errorf("Expected frozen account to receive transfers, got %v", err)
+	}
+	if _, err := transactionService.Create(id, requests.TransactionRequest{Type: "withdrawal", Amount: "10"}); err != utils.ErrAccountFrozen {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountFrozen, err)
+	}
+	if _, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: id, ToAccountID: other.ID.String(), Amount: "10"}); err != utils.ErrAccountFrozen {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountFrozen, err)
+	}
+	if _, err := accountService.Freeze(id); err != utils.ErrInvalidStatusTransition {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidStatusTransition, err)
+	}
+
+	// Only an empty account can be closed
+	if _, err := accountService.Close(id); err != utils.ErrAccountNotEmpty {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountNotEmpty, err)
+	}
+	accountService.Unfreeze(id)
+	transactionService.Create(id, requests.TransactionRequest{Type: "withdrawal", Amount: "120"})
+	closed, err := accountService.Close(id)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if closed.Status != utils.Closed {
+		t.Errorf("Expected status %s, got %s", utils.Closed, closed.Status)
+	}
+
+	// A closed account accepts nothing until it is reopened
+	if _, err := transactionService.Create(id, requests.TransactionRequest{Type: "deposit", Amount: "10"}); err != utils.ErrAccountClosed {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountClosed, err)
+	}
+	if _, err := accountService.Update(id, requests.AccountUpdateRequest{Owner: "Alice Smith"}); err != utils.ErrAccountClosed {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountClosed, err)
+	}
+	if _, err := accountService.Reopen(id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	updated, err := accountService.Update(id, requests.AccountUpdateRequest{Owner: "Alice Smith"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Owner != "Alice Smith" || updated.Status != utils.Active {
+		t.Errorf("Expected active account of Alice Smith, got %+v", updated)
+	}
+}
+
+func TestOverdraft(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	account, err := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100", OverdraftLimit: "50"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	available, _ := account.Available()
+	if account.Overdraft.String() != "50.00" || available.String() != "150.00" {
+		t.Errorf("Expected 150.00 available, got %+v", account)
+	}
+	id := account.ID.String()
+
+	// Withdraw into the overdraft but not beyond it
+	if _, err := transactionService.Create(id, requests.TransactionRequest{Type: "withdrawal", Amount: "150.01"}); err != utils.ErrInsufficientFunds {
+		t.Errorf("Expected error %v, got %v", utils.ErrInsufficientFunds, err)
+	}
+	if _, err := transactionService.Create(id, requests.TransactionRequest{Type: "withdrawal", Amount: "130"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	account, _ = accountService.ReadOne(id)
+	available, _ = account.Available()
+	if account.Balance.String() != "-30.00" || available.String() != "20.00" {
+		t.Errorf("Expected balance -30.00 with 20.00 available, got %+v", account)
+	}
+
+	// The limit cannot drop below the overdrawn balance
+	if _, err := accountService.SetOverdraft(id, requests.OverdraftRequest{Limit: "29.99"}); err != utils.ErrOverdraftInUse {
+		t.Errorf("Expected error %v, got %v", utils.ErrOverdraftInUse, err)
+	}
+	account, err = accountService.SetOverdraft(id, requests.OverdraftRequest{Limit: "30"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	available, _ = account.Available()
+	if !available.IsZero() {
+		t.Errorf("Expected nothing available, got %s", available)
+	}
+
+	// Only checking accounts can be overdrawn
+	if _, err := accountService.Create(requests.AccountRequest{Owner: "Alice", Type: "savings", InitialBalance: "0", OverdraftLimit: "10"}); err != utils.ErrOverdraftNotAllowed {
+		t.Errorf("Expected error %v, got %v", utils.ErrOverdraftNotAllowed, err)
+	}
+	if _, err := accountService.SetOverdraft(id, requests.OverdraftRequest{Limit: "-1"}); err != utils.ErrInvalidAmount {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidAmount, err)
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"testing"
+)
+
+func TestCustomerAccounts(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	customerService := services.CreateCustomerService(storage)
+	accountService := services.CreateAccountService(storage)
+	customer, err := customerService.Create(requests.CustomerRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Accounts of a customer are named after them unless an owner is given
+	checking, err := accountService.Create(requests.AccountRequest{CustomerID: customer.ID.String(), InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if checking.CustomerID != customer.ID || checking.Owner != "Alice" {
+		t.Errorf("Expected an account of Alice, got %+v", checking)
+	}
+	savings, _ := accountService.Create(requests.AccountRequest{CustomerID: customer.ID.String(), Owner: "Alice savings", InitialBalance: "50"})
+	accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "10"})
+
+	// Only the accounts of the customer are listed
+	accounts, err := customerService.ReadAccounts(customer.ID.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].ID != checking.ID || accounts[1].ID != savings.ID {
+		t.Errorf("Expected the checking and savings accounts, got %+v", accounts)
+	}
+
+	// Unknown customers cannot hold accounts
+	unknown := "00000000-0000-0000-0000-000000000001"
+	if _, err := accountService.Create(requests.AccountRequest{CustomerID: unknown, InitialBalance: "1"}); err != utils.ErrCustomerNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrCustomerNotFound, err)
+	}
+	if _, err := customerService.ReadAccounts(unknown); err != utils.ErrCustomerNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrCustomerNotFound, err)
+	}
+
+	// Customers holding accounts cannot be deleted
+	if err := customerService.Delete(customer.ID.String()); err != utils.ErrCustomerHasAccounts {
+		t.Errorf("Expected error %v, got %v", utils.ErrCustomerHasAccounts, err)
+	}
+}
+
+func TestCustomerUpdateAndDelete(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	service := services.CreateCustomerService(storage)
+	customer, _ := service.Create(requests.CustomerRequest{Name: "Alice"})
+
+	// Update the details
+	updated, err := service.Update(customer.ID.String(), requests.CustomerRequest{Name: "Alice Smith", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Name != "Alice Smith" || updated.Email != "alice@example.com" || !updated.CreatedAt.Equal(customer.CreatedAt) {
+		t.Errorf("Expected the updated customer, got %+v", updated)
+	}
+
+	// Delete the customer
+	if err := service.Delete(customer.ID.String()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.ReadOne(customer.ID.String()); err != utils.ErrCustomerNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrCustomerNotFound, err)
+	}
+	if err := service.Delete(customer.ID.String()); err != utils.ErrCustomerNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrCustomerNotFound, err)
+	}
+	if _, err := service.ReadOne("invalid-uuid"); err != utils.ErrInvalidUUID {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidUUID, err)
+	}
+}
+
+func TestCustomerNetWorth(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	customerService := services.CreateCustomerService(storage)
+	accountService := services.CreateAccountService(storage)
+	customerService.Exchange.Update(requests.ExchangeRatesRequest{Rates: []requests.ExchangeRateRequest{
+		{From: "EUR", To: "USD", Rate: "1.1"},
+	}})
+	customer, _ := customerService.Create(requests.CustomerRequest{Name: "Alice"})
+	id := customer.ID.String()
+	accountService.Create(requests.AccountRequest{CustomerID: id, Currency: "USD", InitialBalance: "100.50"})
+	accountService.Create(requests.AccountRequest{CustomerID: id, Currency: "USD", InitialBalance: "20"})
+	accountService.Create(requests.AccountRequest{CustomerID: id, Currency: "EUR", InitialBalance: "50"})
+
+	// Balances are summed per currency
+	netWorth, err := customerService.NetWorth(id, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(netWorth.Balances) != 2 || netWorth.Total != nil {
+		t.Fatalf("Expected two balances and no total, got %+v", netWorth)
+	}
+	if netWorth.Balances[0].Currency != "EUR" || netWorth.Balances[0].Amount.String() != "50.00" {
+		t.Errorf("Expected 50.00 EUR, got %+v", netWorth.Balances[0])
+	}
+	if netWorth.Balances[1].Currency != "USD" || netWorth.Balances[1].Amount.String() != "120.50" {
+		t.Errorf("Expected 120.50 USD, got %+v", netWorth.Balances[1])
+	}
+
+	// The total converts every currency: 120.50 + 50 * 1.1
+	netWorth, err = customerService.NetWorth(id, "usd")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if netWorth.Currency != "USD" || netWorth.Total == nil || netWorth.Total.String() != "175.50" {
+		t.Errorf("Expected a total of 175.50 USD, got %+v", netWorth)
+	}
+
+	// Totals need a rate for every currency held
+	if _, err := customerService.NetWorth(id, "JPY"); err != utils.ErrRateNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrRateNotFound, err)
+	}
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"testing"
+	"time"
+)
+
+func TestEvents_AccountHistory(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	alice, err := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bob, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "0"})
+
+	// Move money in, out and across
+	if _, err := transactionService.Create(alice.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "50"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := transactionService.Create(alice.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "30"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transfer, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: alice.ID.String(), ToAccountID: bob.ID.String(), Amount: "20"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Every change is an event of the account, in order
+	events, err := services.CreateEventService(store).ReadByAccount(alice.ID.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []utils.EventType{utils.EventAccountOpened, utils.EventFundsDeposited, utils.EventFundsWithdrawn, utils.EventFundsWithdrawn, utils.EventTransferCompleted}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for index, eventType := range expected {
+		if events[index].Type != eventType {
+			t.Errorf("Expected event %d to be %s, got %s", index, eventType, events[index].Type)
+		}
+	}
+	if events[4].ReferenceID != transfer.ID || events[4].CounterpartyID != bob.ID || events[4].Amount.String() != "20.00" {
+		t.Errorf("Expected the transfer of 20.00 to %s, got %+v", bob.ID, events[4])
+	}
+
+	// The receiving account sees the deposit leg and the transfer
+	events, _ = services.CreateEventService(store).ReadByAccount(bob.ID.String())
+	if len(events) != 3 || events[1].Type != utils.EventFundsDeposited || events[2].Type != utils.EventTransferCompleted {
+		t.Errorf("Expected opening, deposit and transfer events, got %+v", events)
+	}
+	if _, err := services.CreateEventService(store).ReadByAccount("invalid"); err != utils.ErrInvalidUUID {
+		t.Errorf("Expected ErrInvalidUUID, got %v", err)
+	}
+}
+
+func TestEvents_ProjectionMatchesAccounts(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	eventService := services.CreateEventService(store)
+	alice, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100", OverdraftLimit: "50"})
+	bob, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "10"})
+
+	// Overdraw, hold, capture, release, reverse and freeze
+	transactionService.Create(alice.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "120"})
+	captured, _ := transactionService.PlaceHold(alice.ID.String(), requests.HoldRequest{Amount: "15"})
+	transactionService.CaptureHold(captured.ID.String(), requests.CaptureRequest{Amount: "5"})
+	transactionService.PlaceHold(alice.ID.String(), requests.HoldRequest{Amount: "7"})
+	released, _ := transactionService.PlaceHold(bob.ID.String(), requests.HoldRequest{Amount: "3"})
+	transactionService.ReleaseHold(released.ID.String(), "")
+	deposit, _ := transactionService.Create(bob.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "40"})
+	transactionService.Reverse(deposit.ID.String(), requests.ReversalRequest{Amount: "15"})
+	transactionService.Transfer(requests.TransferRequest{FromAccountID: bob.ID.String(), ToAccountID: alice.ID.String(), Amount: "25"})
+	accountService.SetOverdraft(alice.ID.String(), requests.OverdraftRequest{Limit: "60"})
+	accountService.Freeze(bob.ID.String())
+
+	// Rebuilding the projection from scratch gives the stored state
+	balances, err := eventService.RebuildBalances()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	accounts, _ := store.FindAccounts()
+	if len(balances) != len(accounts) {
+		t.Fatalf("Expected %d balances, got %d", len(accounts), len(balances))
+	}
+	for index, account := range accounts {
+		balance := balances[index]
+		if balance.AccountID != account.ID || balance.Balance != account.Balance || balance.Held != account.Held ||
+			balance.Overdraft != account.Overdraft || balance.Status != account.Status {
+			t.Errorf("Expected the projection of %+v, got %+v", account, balance)
+		}
+	}
+
+	// Projecting one account gives the same result
+	balance, err := eventService.Balance(alice.ID.String(), requests.BalanceQuery{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	available, _ := balance.Available()
+	if balance != balances[0] || balance.Balance.String() != "0.00" || available.String() != "53.00" {
+		t.Errorf("Expected balance 0.00 with 53.00 available, got %+v", balance)
+	}
+}
+
+func TestEvents_BalanceAsOf(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	eventService := services.CreateEventService(store)
+	openedAt := time.Now()
+	alice, err := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Deposit one unit an hour, enough for several checkpoints
+	start := openedAt.Add(time.Hour).Truncate(time.Second)
+	for hour := 0; hour < 250; hour++ {
+		transactionService.Clock = utils.FixedClock{Time: start.Add(time.Duration(hour) * time.Hour)}
+		if _, err := transactionService.Create(alice.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "1"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if _, err := store.FindBalanceCheckpoint(alice.ID, time.Time{}); err != nil {
+		t.Fatalf("Expected a checkpoint, got %v", err)
+	}
+
+	// The balance as of a time counts the initial balance and the deposits made by then
+	cases := []struct {
+		asOf     time.Time
+		expected string
+	}{
+		{start.Add(-time.Minute), "100.00"},
+		{start, "101.00"},
+		{start.Add(149*time.Hour + time.Minute), "250.00"},
+		{start.Add(1000 * time.Hour), "350.00"},
+	}
+	for _, testCase := range cases {
+		balance, err := eventService.Balance(alice.ID.String(), requests.BalanceQuery{AsOf: testCase.asOf.Format(time.RFC3339Nano)})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if balance.Balance.String() != testCase.expected {
+			t.Errorf("Expected balance %s as of %s, got %s", testCase.expected, testCase.asOf, balance.Balance)
+		}
+	}
+	current, _ := eventService.Balance(alice.ID.String(), requests.BalanceQuery{})
+	if current.Balance.String() != "350.00" || current.Version != 251 {
+		t.Errorf("Expected balance 350.00 at version 251, got %+v", current)
+	}
+
+	// The account did not exist before it was opened and bad times are rejected
+	if _, err := eventService.Balance(alice.ID.String(), requests.BalanceQuery{AsOf: openedAt.Add(-time.Hour).Format(time.RFC3339)}); err != utils.ErrNotOpenAsOf {
+		t.Errorf("Expected ErrNotOpenAsOf, got %v", err)
+	}
+	if _, err := eventService.Balance(alice.ID.String(), requests.BalanceQuery{AsOf: "yesterday"}); err != utils.ErrInvalidQuery {
+		t.Errorf("Expected ErrInvalidQuery, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/utils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExchangeFind(t *testing.T) {
+	// Setup
+	service := services.CreateExchangeService()
+	_, err := service.Update(requests.ExchangeRatesRequest{Rates: []requests.ExchangeRateRequest{
+		{From: "usd", To: "EUR", Rate: "0.8"},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Direct, inverse and identical currency pairs
+	cases := map[[2]string]string{
+		{"USD", "EUR"}: "0.8",
+		{"EUR", "USD"}: "1.25",
+		{"GBP", "GBP"}: "1",
+	}
+	for pair, expected := range cases {
+		rate, err := service.Find(pair[0], pair[1])
+		if err != nil {
+			t.Fatalf("Expected no error for %v, got %v", pair, err)
+		}
+		if rate.Rate.String() != expected {
+			t.Errorf("Expected rate %s for %v, got %s", expected, pair, rate.Rate)
+		}
+	}
+
+	// Unknown pairs are reported
+	if _, err := service.Find("USD", "GBP"); err != utils.ErrRateNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrRateNotFound, err)
+	}
+}
+
+func TestExchangeUpdateIsAllOrNothing(t *testing.T) {
+	// Setup
+	service := services.CreateExchangeService()
+
+	// One invalid rate rejects the whole update
+	_, err := service.Update(requests.ExchangeRatesRequest{Rates: []requests.ExchangeRateRequest{
+		{From: "USD", To: "EUR", Rate: "0.8"},
+		{From: "USD", To: "GBP", Rate: "-1"},
+	}})
+	if err != utils.ErrInvalidRate {
+		t.Fatalf("Expected error %v, got %v", utils.ErrInvalidRate, err)
+	}
+	rates, _ := service.ReadAll()
+	if len(rates) != 0 {
+		t.Errorf("Expected 0 rates, got %d", len(rates))
+	}
+}
+
+func TestExchangeLoadAndSave(t *testing.T) {
+	// Setup a rate file
+	path := filepath.Join(t.TempDir(), "rates.json")
+	os.WriteFile(path, []byte(`[{"from": "USD", "to": "EUR", "rate": 0.92}]`), 0o644)
+	service := services.CreateExchangeService()
+	if err := service.Load(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Update the table, which saves it to the file
+	_, err := service.Update(requests.ExchangeRatesRequest{Rates: []requests.ExchangeRateRequest{
+		{From: "USD", To: "JPY", Rate: "150"},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Reload the file into a new table
+	reloaded := services.CreateExchangeService()
+	if err := reloaded.Load(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rates, _ := reloaded.ReadAll()
+	if len(rates) != 2 {
+		t.Fatalf("Expected 2 rates, got %d", len(rates))
+	}
+	if rates[0].To != "EUR" || rates[0].Rate.String() != "0.92" || rates[1].To != "JPY" || rates[1].Rate.String() != "150" {
+		t.Errorf("Unexpected rates %+v", rates)
+	}
+}
@@ -0,0 +1,199 @@
+package test
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"path/filepath"
+	"testing"
+)
+
+// feeSchedule charges checking withdrawals a flat fee, transfers 1% between
+// 1.00 and 25.00 and savings transfers by tiers
+var feeSchedule = requests.FeeScheduleRequest{Rules: []requests.FeeRuleRequest{
+	{Operation: "withdrawal", AccountType: "checking", Flat: "0.50"},
+	{Operation: "transfer", Rate: "0.01", Minimum: "1", Maximum: "25"},
+	{Operation: "transfer", AccountType: "savings", Tiers: []requests.FeeTierRequest{
+		{UpTo: "100", Flat: "2"},
+		{UpTo: "1000", Flat: "1", Rate: "0.005"},
+		{Rate: "0.001"},
+	}},
+}}
+
+func TestFeeQuote(t *testing.T) {
+	// Setup
+	service := services.CreateFeeService()
+	if _, err := service.Update(feeSchedule); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	checking := models.Account{Currency: "USD", Type: utils.Checking}
+	savings := models.Account{Currency: "USD", Type: utils.Savings}
+	yen := models.Account{Currency: "JPY", Type: utils.Checking}
+
+	// Test data: operation, account and amount with the expected fee
+	cases := []struct {
+		operation string
+		account   models.Account
+		amount    money.Money
+		expected  string
+	}{
+		{models.FeeOnWithdrawal, checking, money.New(1000, 2), "0.50"},   // Flat
+		{models.FeeOnDeposit, checking, money.New(1000, 2), "0.00"},      // No rule
+		{models.FeeOnWithdrawal, savings, money.New(1000, 2), "0.00"},    // Rule of another type
+		{models.FeeOnTransfer, checking, money.New(50000, 2), "5.00"},    // 1%
+		{models.FeeOnTransfer, checking, money.New(1000, 2), "1.00"},     // Minimum
+		{models.FeeOnTransfer, checking, money.New(1000000, 2), "25.00"}, // Maximum
+		{models.FeeOnTransfer, yen, money.New(12345, 0), "25"},           // Rounded to the currency
+		{models.FeeOnTransfer, yen, money.New(150, 0), "2"},              // 1.50 ties to even
+		{models.FeeOnTransfer, savings, money.New(10000, 2), "2.00"},     // First tier, bound included
+		{models.FeeOnTransfer, savings, money.New(50000, 2), "3.50"},     // Second tier
+		{models.FeeOnTransfer, savings, money.New(500000, 2), "5.00"},    // Last tier
+	}
+
+	for _, testCase := range cases {
+		fee, err := service.Quote(testCase.operation, testCase.account, testCase.amount)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if fee.String() != testCase.expected {
+			t.Errorf("Expected %s fee of %s on %s to be %s, got %s", testCase.operation, testCase.amount, testCase.account.Type, testCase.expected, fee)
+		}
+	}
+}
+
+func TestFeeReadAllCopies(t *testing.T) {
+	// Setup
+	service := services.CreateFeeService()
+	if _, err := service.Update(feeSchedule); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	checking := models.Account{Currency: "USD", Type: utils.Checking}
+
+	// Changing the rules read leaves the schedule alone
+	rules, _ := service.ReadAll()
+	rules[0].Tiers[0].Flat = money.New(900, 2)
+	*rules[1].Maximum = money.New(100, 2)
+	rules[2] = models.FeeRule{Operation: models.FeeOnDeposit}
+	fee, _ := service.Quote(models.FeeOnWithdrawal, checking, money.New(1000, 2))
+	if fee.String() != "0.50" {
+		t.Errorf("Expected withdrawal fee of 0.50, got %s", fee)
+	}
+	fee, _ = service.Quote(models.FeeOnTransfer, checking, money.New(1000000, 2))
+	if fee.String() != "25.00" {
+		t.Errorf("Expected transfer fee of 25.00, got %s", fee)
+	}
+	if current, _ := service.ReadAll(); current[2].Operation != models.FeeOnTransfer {
+		t.Errorf("Expected the savings transfer rule to remain, got %+v", current[2])
+	}
+}
+
+func TestFeeRulesValidation(t *testing.T) {
+	// Setup
+	service := services.CreateFeeService()
+	service.Update(feeSchedule)
+
+	// Each invalid schedule is rejected as a whole
+	invalid := [][]requests.FeeRuleRequest{
+		{{Operation: "refund", Flat: "1"}},
+		{{Operation: "deposit", AccountType: "gold", Flat: "1"}},
+		{{Operation: "deposit", Flat: "-1"}},
+		{{Operation: "deposit", Flat: "1"}, {Operation: "deposit", Rate: "0.01"}},
+		{{Operation: "deposit", Minimum: "5", Maximum: "1"}},
+		{{Operation: "deposit", Flat: "1", Tiers: []requests.FeeTierRequest{{Flat: "1"}}}},
+		{{Operation: "deposit", Tiers: []requests.FeeTierRequest{{Flat: "1"}, {UpTo: "10", Flat: "2"}}}},
+		{{Operation: "deposit", Tiers: []requests.FeeTierRequest{{UpTo: "10", Flat: "1"}, {UpTo: "5", Flat: "2"}, {Flat: "3"}}}},
+	}
+	for _, rules := range invalid {
+		if _, err := service.Update(requests.FeeScheduleRequest{Rules: rules}); err != utils.ErrInvalidFeeRule {
+			t.Errorf("Expected error %v for %+v, got %v", utils.ErrInvalidFeeRule, rules, err)
+		}
+	}
+	rules, _ := service.ReadAll()
+	if len(rules) != len(feeSchedule.Rules) {
+		t.Errorf("Expected the schedule unchanged, got %+v", rules)
+	}
+}
+
+func TestFeeLoadAndSave(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "fees.json")
+	service := services.CreateFeeService()
+	if err := service.Load(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.Update(feeSchedule); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A new service loads the saved schedule and quotes the same fees
+	reloaded := services.CreateFeeService()
+	if err := reloaded.Load(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	savings := models.Account{Currency: "USD", Type: utils.Savings}
+	for _, amount := range []money.Money{money.New(10000, 2), money.New(50000, 2), money.New(500000, 2)} {
+		expected, _ := service.Quote(models.FeeOnTransfer, savings, amount)
+		fee, err := reloaded.Quote(models.FeeOnTransfer, savings, amount)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if fee.Cmp(expected) != 0 {
+			t.Errorf("Expected fee %s on %s, got %s", expected, amount, fee)
+		}
+	}
+}
+
+func TestTransferFees(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	transactionService.Fees.Update(feeSchedule)
+	alice, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "500"})
+	bob, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "0"})
+
+	// The fee is a separate transaction linked to the withdrawal leg
+	transfer, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: alice.ID.String(), ToAccountID: bob.ID.String(), Amount: "200"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(transfer.Fees) != 1 {
+		t.Fatalf("Expected one fee, got %+v", transfer.Fees)
+	}
+	fee := transfer.Fees[0]
+	if fee.Type != utils.Fee || fee.AccountID != alice.ID || fee.RelatedID != transfer.Withdrawal.ID || fee.Amount.String() != "2.00" {
+		t.Errorf("Expected a fee of 2.00 for the withdrawal, got %+v", fee)
+	}
+	updated, _ := accountService.ReadOne(alice.ID.String())
+	if updated.Balance.String() != "298.00" {
+		t.Errorf("Expected balance 298.00, got %s", updated.Balance)
+	}
+
+	// A transfer that leaves nothing for its fee is rolled back
+	_, err = transactionService.Transfer(requests.TransferRequest{FromAccountID: alice.ID.String(), ToAccountID: bob.ID.String(), Amount: "298"})
+	if err != utils.ErrInsufficientFunds {
+		t.Errorf("Expected error %v, got %v", utils.ErrInsufficientFunds, err)
+	}
+	received, _ := accountService.ReadOne(bob.ID.String())
+	if received.Balance.String() != "200.00" {
+		t.Errorf("Expected balance 200.00, got %s", received.Balance)
+	}
+
+	// Withdrawals are charged their own fee and the books stay balanced
+	withdrawal, err := transactionService.Create(alice.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "10"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transactions, _ := transactionService.ReadByAccount(alice.ID.String())
+	last := transactions[len(transactions)-1]
+	if last.Type != utils.Fee || last.RelatedID != withdrawal.ID || last.Amount.String() != "0.50" {
+		t.Errorf("Expected a fee of 0.50 for the withdrawal, got %+v", last)
+	}
+	trialBalance, _ := services.CreateLedgerService(store).TrialBalance()
+	if !trialBalance.Balanced {
+		t.Errorf("Expected balanced books, got %+v", trialBalance)
+	}
+}
@@ -0,0 +1,299 @@
+package test
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"testing"
+	"time"
+)
+
+func TestPlaceHold(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account, err := services.CreateAccountService(store).Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transactionService := services.CreateTransactionService(store)
+
+	// The hold reduces the available balance but not the balance
+	hold, err := transactionService.PlaceHold(account.ID.String(), requests.HoldRequest{Amount: "70"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if hold.Status != utils.HoldPending || hold.Amount.String() != "70.00" {
+		t.Errorf("Expected a pending hold of 70.00, got %s of %s", hold.Status, hold.Amount)
+	}
+	updated, _ := store.FindAccount(account.ID)
+	available, _ := updated.Available()
+	if updated.Balance.String() != "100.00" || available.String() != "30.00" {
+		t.Errorf("Expected balance 100.00 and 30.00 available, got %s and %s", updated.Balance, available)
+	}
+
+	// Neither another hold nor a withdrawal can use the held funds
+	if _, err := transactionService.PlaceHold(account.ID.String(), requests.HoldRequest{Amount: "40"}); err != utils.ErrInsufficientFunds {
+		t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+	}
+	if _, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "40"}); err != utils.ErrInsufficientFunds {
+		t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
+func TestCaptureHold_Partial(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account, err := services.CreateAccountService(store).Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transactionService := services.CreateTransactionService(store)
+	hold, err := transactionService.PlaceHold(account.ID.String(), requests.HoldRequest{Amount: "70"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Captures cannot exceed the hold
+	if _, err := transactionService.CaptureHold(hold.ID.String(), requests.CaptureRequest{Amount: "70.01"}); err != utils.ErrCaptureExceedsHold {
+		t.Errorf("Expected ErrCaptureExceedsHold, got %v", err)
+	}
+
+	// Capturing part of the hold withdraws it and frees the rest
+	captured, err := transactionService.CaptureHold(hold.ID.String(), requests.CaptureRequest{Amount: "45"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if captured.Status != utils.HoldCaptured || captured.Captured.String() != "45.00" {
+		t.Errorf("Expected a hold captured for 45.00, got %s for %s", captured.Status, captured.Captured)
+	}
+	transactions, _ := store.FindTransactions(account.ID)
+	last := transactions[len(transactions)-1]
+	if last.ID != captured.TransactionID || last.Type != utils.Withdrawal || last.Amount.String() != "45.00" {
+		t.Errorf("Expected the capture to withdraw 45.00, got %+v", last)
+	}
+	updated, _ := store.FindAccount(account.ID)
+	available, _ := updated.Available()
+	if updated.Balance.String() != "55.00" || available.String() != "55.00" {
+		t.Errorf("Expected balance and available 55.00, got %s and %s", updated.Balance, available)
+	}
+
+	// A captured hold can be neither captured nor released again
+	if _, err := transactionService.CaptureHold(hold.ID.String(), requests.CaptureRequest{}); err != utils.ErrHoldNotPending {
+		t.Errorf("Expected ErrHoldNotPending, got %v", err)
+	}
+	if _, err := transactionService.ReleaseHold(hold.ID.String(), ""); err != utils.ErrHoldNotPending {
+		t.Errorf("Expected ErrHoldNotPending, got %v", err)
+	}
+
+	// The books stay balanced
+	trialBalance, _ := services.CreateLedgerService(store).TrialBalance()
+	if !trialBalance.Balanced {
+		t.Errorf("Expected a balanced trial balance, got %+v", trialBalance)
+	}
+}
+
+func TestReleaseHold(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account, err := services.CreateAccountService(store).Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transactionService := services.CreateTransactionService(store)
+	hold, err := transactionService.PlaceHold(account.ID.String(), requests.HoldRequest{Amount: "70"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Releasing the hold frees its funds without moving money
+	released, err := transactionService.ReleaseHold(hold.ID.String(), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if released.Status != utils.HoldReleased {
+		t.Errorf("Expected a released hold, got %s", released.Status)
+	}
+	updated, _ := store.FindAccount(account.ID)
+	available, _ := updated.Available()
+	if updated.Balance.String() != "100.00" || available.String() != "100.00" {
+		t.Errorf("Expected balance and available 100.00, got %s and %s", updated.Balance, available)
+	}
+	transactions, _ := store.FindTransactions(account.ID)
+	if len(transactions) != 0 {
+		t.Errorf("Expected no transactions, got %d", len(transactions))
+	}
+}
+
+func TestHold_Expiry(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account, err := services.CreateAccountService(store).Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	placedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	transactionService := services.CreateTransactionService(store)
+	transactionService.Clock = utils.FixedClock{Time: placedAt}
+	transactionService.HoldTTL = time.Hour
+	hold, err := transactionService.PlaceHold(account.ID.String(), requests.HoldRequest{Amount: "70"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Once the hold expires it can no longer be captured
+	transactionService.Clock = utils.FixedClock{Time: placedAt.Add(time.Hour)}
+	if _, err := transactionService.CaptureHold(hold.ID.String(), requests.CaptureRequest{}); err != utils.ErrHoldNotPending {
+		t.Errorf("Expected ErrHoldNotPending, got %v", err)
+	}
+
+	// Its funds are available again to a withdrawal
+	if _, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "90"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	holds, err := transactionService.ReadHolds(account.ID.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(holds) != 1 || holds[0].Status != utils.HoldExpired {
+		t.Errorf("Expected one expired hold, got %+v", holds)
+	}
+	updated, _ := store.FindAccount(account.ID)
+	available, _ := updated.Available()
+	if updated.Held.String() != "0.00" || available.String() != "10.00" {
+		t.Errorf("Expected nothing held and 10.00 available, got %s and %s", updated.Held, available)
+	}
+}
+
+// Test that reads show expired holds without storing anything
+func TestHold_ExpiryOnRead(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	placedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	transactionService := services.CreateTransactionService(store)
+	transactionService.Clock = utils.FixedClock{Time: placedAt}
+	transactionService.HoldTTL = time.Hour
+	hold, err := transactionService.PlaceHold(account.ID.String(), requests.HoldRequest{Amount: "70"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	events, _ := store.FindAccountEvents(models.EventFilter{AccountID: account.ID})
+
+	// Once the hold expires, reads show it expired and its funds freed
+	expiredAt := placedAt.Add(time.Hour)
+	accountService.Clock = utils.FixedClock{Time: expiredAt}
+	transactionService.Clock = utils.FixedClock{Time: expiredAt}
+	read, err := accountService.ReadOne(account.ID.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if read.Held.String() != "0.00" {
+		t.Errorf("Expected nothing held, got %s", read.Held)
+	}
+	holds, err := transactionService.ReadHolds(account.ID.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(holds) != 1 || holds[0].Status != utils.HoldExpired {
+		t.Errorf("Expected one expired hold, got %+v", holds)
+	}
+
+	// Neither read stored the expiry
+	stored, _ := store.FindHold(hold.ID)
+	updated, _ := store.FindAccount(account.ID)
+	after, _ := store.FindAccountEvents(models.EventFilter{AccountID: account.ID})
+	if stored.Status != utils.HoldPending || updated.Held.String() != "70.00" || len(after) != len(events) {
+		t.Errorf("Expected the stored hold and account unchanged, got %+v, %s held and %d events", stored, updated.Held, len(after))
+	}
+}
+
+// Test that listings show the funds of expired holds freed like reads do
+func TestHold_ExpiryOnListings(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	customerService := services.CreateCustomerService(store)
+	accountService := services.CreateAccountService(store)
+	customer, _ := customerService.Create(requests.CustomerRequest{Name: "Alice", Email: "alice@example.com"})
+	account, _ := accountService.Create(requests.AccountRequest{CustomerID: customer.ID.String(), InitialBalance: "100"})
+	placedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	transactionService := services.CreateTransactionService(store)
+	transactionService.Clock = utils.FixedClock{Time: placedAt}
+	transactionService.HoldTTL = time.Hour
+	if _, err := transactionService.PlaceHold(account.ID.String(), requests.HoldRequest{Amount: "70", ActingCustomerID: customer.ID.String()}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Once the hold expires, every listing shows nothing held
+	expiredAt := placedAt.Add(time.Hour)
+	accountService.Clock = utils.FixedClock{Time: expiredAt}
+	customerService.Clock = utils.FixedClock{Time: expiredAt}
+	all, err := accountService.ReadAll()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	page, err := accountService.Search(requests.AccountQuery{Owner: "Alice"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	owned, err := customerService.ReadAccounts(customer.ID.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for name, accounts := range map[string][]models.Account{"all": all, "search": page.Accounts, "customer": owned} {
+		if len(accounts) != 1 || accounts[0].Held.String() != "0.00" {
+			t.Errorf("Expected one account with nothing held in %s, got %+v", name, accounts)
+		}
+	}
+
+	// The listings did not store the expiry
+	if stored, _ := store.FindAccount(account.ID); stored.Held.String() != "70.00" {
+		t.Errorf("Expected 70.00 still stored as held, got %s", stored.Held)
+	}
+}
+
+// Test that an account with pending holds cannot be closed until they are
+// released or expire
+func TestHold_BlocksClose(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "0", OverdraftLimit: "50"})
+	placedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	transactionService := services.CreateTransactionService(store)
+	transactionService.Clock = utils.FixedClock{Time: placedAt}
+	transactionService.HoldTTL = time.Hour
+	accountService.Clock = utils.FixedClock{Time: placedAt}
+	id := account.ID.String()
+
+	// A released hold no longer keeps the account open
+	hold, err := transactionService.PlaceHold(id, requests.HoldRequest{Amount: "20"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := accountService.Close(id); err != utils.ErrAccountHasHolds {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountHasHolds, err)
+	}
+	if _, err := transactionService.ReleaseHold(hold.ID.String(), ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := accountService.Close(id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	accountService.Reopen(id)
+
+	// Neither does an expired one
+	if _, err := transactionService.PlaceHold(id, requests.HoldRequest{Amount: "20"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	accountService.Clock = utils.FixedClock{Time: placedAt.Add(time.Hour)}
+	closed, err := accountService.Close(id)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if closed.Status != utils.Closed || closed.Held.String() != "0.00" {
+		t.Errorf("Expected a closed account with nothing held, got %+v", closed)
+	}
+}
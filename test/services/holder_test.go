@@ -0,0 +1,209 @@
+package test
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"testing"
+)
+
+func TestJointAccountPermissions(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	customerService := services.CreateCustomerService(storage)
+	accountService := services.CreateAccountService(storage)
+	holderService := services.CreateHolderService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	owner, _ := customerService.Create(requests.CustomerRequest{Name: "Alice"})
+	signatory, _ := customerService.Create(requests.CustomerRequest{Name: "Bob"})
+	viewer, _ := customerService.Create(requests.CustomerRequest{Name: "Carol"})
+	account, err := accountService.Create(requests.AccountRequest{CustomerID: owner.ID.String(), InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	id := account.ID.String()
+
+	// Only an owner can add holders
+	signatoryRole := requests.HolderRequest{Role: "signatory"}
+	if _, err := holderService.Save(id, signatory.ID.String(), signatoryRole, signatory.ID.String()); err != utils.ErrHolderNotPermitted {
+		t.Errorf("Expected error %v, got %v", utils.ErrHolderNotPermitted, err)
+	}
+	if _, err := holderService.Save(id, signatory.ID.String(), signatoryRole, owner.ID.String()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := holderService.Save(id, viewer.ID.String(), requests.HolderRequest{Role: "view-only"}, owner.ID.String()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	holders, _ := holderService.ReadAll(id)
+	if len(holders) != 3 || holders[0].Role != utils.Owner || holders[1].Role != utils.Signatory || holders[2].Role != utils.Viewer {
+		t.Errorf("Expected an owner, a signatory and a viewer, got %+v", holders)
+	}
+
+	// Owners and signatories can withdraw, viewers and strangers cannot
+	withdrawal := requests.TransactionRequest{Type: "withdrawal", Amount: "10"}
+	for _, actor := range []string{owner.ID.String(), signatory.ID.String()} {
+		withdrawal.ActingCustomerID = actor
+		if _, err := transactionService.Create(id, withdrawal); err != nil {
+			t.Errorf("Expected no error for %s, got %v", actor, err)
+		}
+	}
+	for _, actor := range []string{viewer.ID.String(), "", "not-a-uuid"} {
+		withdrawal.ActingCustomerID = actor
+		if _, err := transactionService.Create(id, withdrawal); err != utils.ErrHolderNotPermitted {
+			t.Errorf("Expected error %v for %q, got %v", utils.ErrHolderNotPermitted, actor, err)
+		}
+	}
+
+	// Anyone can deposit
+	if _, err := transactionService.Create(id, requests.TransactionRequest{Type: "deposit", Amount: "5"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// Transfers check the acting customer on the source account only
+	other, _ := accountService.Create(requests.AccountRequest{Owner: "Dave", InitialBalance: "0"})
+	transfer := requests.TransferRequest{FromAccountID: id, ToAccountID: other.ID.String(), Amount: "5", ActingCustomerID: viewer.ID.String()}
+	if _, err := transactionService.Transfer(transfer); err != utils.ErrHolderNotPermitted {
+		t.Errorf("Expected error %v, got %v", utils.ErrHolderNotPermitted, err)
+	}
+	transfer.ActingCustomerID = signatory.ID.String()
+	if _, err := transactionService.Transfer(transfer); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	account, _ = accountService.ReadOne(id)
+	if account.Balance.String() != "80.00" {
+		t.Errorf("Expected balance 80.00, got %s", account.Balance)
+	}
+
+	// Holders of joint accounts cannot be deleted as customers
+	if err := customerService.Delete(viewer.ID.String()); err != utils.ErrCustomerHasAccounts {
+		t.Errorf("Expected error %v, got %v", utils.ErrCustomerHasAccounts, err)
+	}
+}
+
+// Test that only its customer acts on an account without holders
+func TestUnheldAccountPermissions(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	customerService := services.CreateCustomerService(store)
+	accountService := services.CreateAccountService(store)
+	holderService := services.CreateHolderService(store)
+	transactionService := services.CreateTransactionService(store)
+	alice, _ := customerService.Create(requests.CustomerRequest{Name: "Alice"})
+	mallory, _ := customerService.Create(requests.CustomerRequest{Name: "Mallory"})
+	account, _ := accountService.Create(requests.AccountRequest{CustomerID: alice.ID.String(), InitialBalance: "100"})
+	id := account.ID.String()
+
+	// Drop the holders the way accounts stored before them had none
+	if err := store.DeleteAccountHolder(account.ID, alice.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Strangers can neither withdraw nor make themselves its owner
+	withdrawal := requests.TransactionRequest{Type: "withdrawal", Amount: "10"}
+	for _, actor := range []string{mallory.ID.String(), ""} {
+		withdrawal.ActingCustomerID = actor
+		if _, err := transactionService.Create(id, withdrawal); err != utils.ErrHolderNotPermitted {
+			t.Errorf("Expected error %v for %q, got %v", utils.ErrHolderNotPermitted, actor, err)
+		}
+	}
+	ownerRole := requests.HolderRequest{Role: "owner"}
+	if _, err := holderService.Save(id, mallory.ID.String(), ownerRole, mallory.ID.String()); err != utils.ErrHolderNotPermitted {
+		t.Errorf("Expected error %v, got %v", utils.ErrHolderNotPermitted, err)
+	}
+	account, _ = accountService.ReadOne(id)
+	if account.CustomerID != alice.ID {
+		t.Errorf("Expected the account to stay with Alice, got %s", account.CustomerID)
+	}
+
+	// Its customer still can
+	withdrawal.ActingCustomerID = alice.ID.String()
+	if _, err := transactionService.Create(id, withdrawal); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if _, err := holderService.Save(id, alice.ID.String(), ownerRole, alice.ID.String()); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// Accounts without a customer take no holders at all
+	named, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "0"})
+	if _, err := holderService.Save(named.ID.String(), mallory.ID.String(), ownerRole, mallory.ID.String()); err != utils.ErrHolderNotPermitted {
+		t.Errorf("Expected error %v, got %v", utils.ErrHolderNotPermitted, err)
+	}
+}
+
+func TestAccountRelinkPermissions(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	customerService := services.CreateCustomerService(store)
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	alice, _ := customerService.Create(requests.CustomerRequest{Name: "Alice"})
+	bob, _ := customerService.Create(requests.CustomerRequest{Name: "Bob"})
+	mallory, _ := customerService.Create(requests.CustomerRequest{Name: "Mallory"})
+	account, _ := accountService.Create(requests.AccountRequest{CustomerID: alice.ID.String(), InitialBalance: "100"})
+	id := account.ID.String()
+
+	// Strangers cannot link the account to themselves
+	for _, actor := range []string{mallory.ID.String(), ""} {
+		relink := requests.AccountUpdateRequest{CustomerID: mallory.ID.String(), ActingCustomerID: actor}
+		if _, err := accountService.Update(id, relink); err != utils.ErrHolderNotPermitted {
+			t.Errorf("Expected error %v for %q, got %v", utils.ErrHolderNotPermitted, actor, err)
+		}
+	}
+	withdrawal := requests.TransactionRequest{Type: "withdrawal", Amount: "10", ActingCustomerID: mallory.ID.String()}
+	if _, err := transactionService.Create(id, withdrawal); err != utils.ErrHolderNotPermitted {
+		t.Errorf("Expected error %v, got %v", utils.ErrHolderNotPermitted, err)
+	}
+	withdrawal.ActingCustomerID = alice.ID.String()
+	if _, err := transactionService.Create(id, withdrawal); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// Its owner can, handing the account over
+	relinked, err := accountService.Update(id, requests.AccountUpdateRequest{CustomerID: bob.ID.String(), ActingCustomerID: alice.ID.String()})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if relinked.CustomerID != bob.ID || relinked.Owner != "Bob" {
+		t.Errorf("Expected the account of Bob, got %+v", relinked)
+	}
+	if _, err := transactionService.Create(id, withdrawal); err != utils.ErrHolderNotPermitted {
+		t.Errorf("Expected error %v, got %v", utils.ErrHolderNotPermitted, err)
+	}
+}
+
+func TestJointAccountKeepsOwner(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	customerService := services.CreateCustomerService(storage)
+	accountService := services.CreateAccountService(storage)
+	holderService := services.CreateHolderService(storage)
+	alice, _ := customerService.Create(requests.CustomerRequest{Name: "Alice"})
+	bob, _ := customerService.Create(requests.CustomerRequest{Name: "Bob"})
+	account, _ := accountService.Create(requests.AccountRequest{CustomerID: alice.ID.String(), InitialBalance: "0"})
+	id := account.ID.String()
+
+	// The last owner can neither leave nor be demoted
+	if err := holderService.Delete(id, alice.ID.String(), alice.ID.String()); err != utils.ErrLastAccountOwner {
+		t.Errorf("Expected error %v, got %v", utils.ErrLastAccountOwner, err)
+	}
+	if _, err := holderService.Save(id, alice.ID.String(), requests.HolderRequest{Role: "signatory"}, alice.ID.String()); err != utils.ErrLastAccountOwner {
+		t.Errorf("Expected error %v, got %v", utils.ErrLastAccountOwner, err)
+	}
+
+	// Once another owner joins, the account moves to them when Alice leaves
+	if _, err := holderService.Save(id, bob.ID.String(), requests.HolderRequest{Role: "owner"}, alice.ID.String()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := holderService.Delete(id, alice.ID.String(), bob.ID.String()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	account, _ = accountService.ReadOne(id)
+	if account.CustomerID != bob.ID {
+		t.Errorf("Expected the account to move to Bob, got %s", account.CustomerID)
+	}
+	if err := holderService.Delete(id, alice.ID.String(), bob.ID.String()); err != utils.ErrHolderNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrHolderNotFound, err)
+	}
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyBeginAndReplay(t *testing.T) {
+	// Setup
+	idempotencyService := services.CreateIdempotencyService(storage.Create())
+
+	// Claim a new key
+	record, replay, err := idempotencyService.Begin("key", "request")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if replay {
+		t.Fatalf("Expected a new key not to replay")
+	}
+
+	// A retry while the first request runs is rejected
+	if _, _, err := idempotencyService.Begin("key", "request"); err != utils.ErrIdempotencyKeyInProgress {
+		t.Errorf("Expected error %v, got %v", utils.ErrIdempotencyKeyInProgress, err)
+	}
+
+	// Complete the request and retry it
+	record.StatusCode = 201
+	record.Body = []byte(`{"id":"1"}`)
+	if err := idempotencyService.Complete(record); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, replay, err := idempotencyService.Begin("key", "request")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !replay || stored.StatusCode != 201 || string(stored.Body) != `{"id":"1"}` {
+		t.Errorf("Expected the stored response to replay, got %+v", stored)
+	}
+
+	// Reusing the key for another request is rejected
+	if _, _, err := idempotencyService.Begin("key", "other request"); err != utils.ErrIdempotencyKeyMismatch {
+		t.Errorf("Expected error %v, got %v", utils.ErrIdempotencyKeyMismatch, err)
+	}
+}
+
+// Test that keys can be used again once released or expired
+func TestIdempotencyReleaseAndExpiry(t *testing.T) {
+	// Setup
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	idempotencyService := services.CreateIdempotencyService(storage.Create())
+	idempotencyService.TTL = time.Hour
+	idempotencyService.Clock = utils.FixedClock{Time: now}
+
+	// A released key is claimed again
+	idempotencyService.Begin("released", "request")
+	if err := idempotencyService.Release("released"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, replay, err := idempotencyService.Begin("released", "other request"); err != nil || replay {
+		t.Errorf("Expected the released key to be claimed, got replay %v and error %v", replay, err)
+	}
+
+	// An expired key is claimed again, even for another request
+	record, _, _ := idempotencyService.Begin("expired", "request")
+	idempotencyService.Complete(record)
+	idempotencyService.Clock = utils.FixedClock{Time: now.Add(time.Hour)}
+	if _, replay, err := idempotencyService.Begin("expired", "other request"); err != nil || replay {
+		t.Errorf("Expected the expired key to be claimed, got replay %v and error %v", replay, err)
+	}
+}
+
+// Test that sweeping deletes the expired keys only
+func TestIdempotencySweep(t *testing.T) {
+	// Setup
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	store := storage.Create()
+	idempotencyService := services.CreateIdempotencyService(store)
+	idempotencyService.TTL = time.Hour
+	idempotencyService.Clock = utils.FixedClock{Time: now}
+	idempotencyService.Begin("old", "request")
+	idempotencyService.Clock = utils.FixedClock{Time: now.Add(30 * time.Minute)}
+	idempotencyService.Begin("recent", "request")
+
+	// Sweep once the first key expired
+	idempotencyService.Clock = utils.FixedClock{Time: now.Add(time.Hour)}
+	if err := idempotencyService.Sweep(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.FindIdempotencyRecord("old"); err != utils.ErrIdempotencyKeyNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrIdempotencyKeyNotFound, err)
+	}
+	if _, err := store.FindIdempotencyRecord("recent"); err != nil {
+		t.Errorf("Expected the recent key to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package test
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// openInterestAccount creates a savings account earning interest and backdates
+// its opening so that interest can be accrued for past days
+func openInterestAccount(t *testing.T, store storage.Storage, request requests.AccountRequest, openedAt time.Time) models.Account {
+	t.Helper()
+	request.Owner = "Alice"
+	request.Type = "savings"
+	account, err := services.CreateAccountService(store).Create(request)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	account.CreatedAt = openedAt
+	if err := store.UpdateAccount(account); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return account
+}
+
+// Test that an account failing to accrue leaves the others accrued
+func TestInterestContinuesPastFailures(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	openedAt := time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	failing := openInterestAccount(t, store, requests.AccountRequest{InitialBalance: "9000000000000000", InterestRate: "0.5"}, openedAt)
+	account := openInterestAccount(t, store, requests.AccountRequest{InitialBalance: "36500", InterestRate: "0.01"}, openedAt)
+	interestService := services.CreateInterestService(store, utils.FixedClock{Time: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)})
+
+	// The interest of the first account is too large to hold
+	run, err := interestService.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(run.Failed) != 1 || run.Failed[0].AccountID != failing.ID || run.Failed[0].Err != utils.ErrInvalidAmount {
+		t.Errorf("Expected the first account to fail with %v, got %+v", utils.ErrInvalidAmount, run.Failed)
+	}
+
+	// The other account is still accrued and paid, the failed one untouched
+	if run.Accrued != 31 || len(run.Paid) != 1 || run.Paid[0].AccountID != account.ID {
+		t.Errorf("Expected 31 days accrued and paid for the second account, got %+v", run)
+	}
+	if accruals, _ := store.FindInterestAccruals(failing.ID, time.Time{}, time.Time{}); len(accruals) != 0 {
+		t.Errorf("Expected no accruals for the failed account, got %d", len(accruals))
+	}
+}
+
+func TestInterestActual365(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account := openInterestAccount(t, store, requests.AccountRequest{InitialBalance: "36500", InterestRate: "0.01"}, time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC))
+	transactionService := services.CreateTransactionService(store)
+	interestService := services.CreateInterestService(store, utils.FixedClock{Time: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)})
+
+	// A later deposit does not count towards the balances of January
+	if _, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "1000"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Every day of January earns one 365th of 1% of 36500.00
+	run, err := interestService.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Accrued != 31 {
+		t.Errorf("Expected 31 accrued days, got %d", run.Accrued)
+	}
+	if len(run.Paid) != 1 {
+		t.Fatalf("Expected one interest payment, got %d", len(run.Paid))
+	}
+	paid := run.Paid[0]
+	if paid.Type != utils.Interest || paid.Amount.String() != "31.00" {
+		t.Errorf("Expected interest of 31.00, got %s of %s", paid.Type, paid.Amount)
+	}
+	if !paid.TimeStamp.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the payment dated 2024-02-01, got %s", paid.TimeStamp)
+	}
+
+	// The interest is credited and every accrual is marked paid
+	updated, _ := store.FindAccount(account.ID)
+	if updated.Balance.String() != "37531.00" {
+		t.Errorf("Expected balance 37531.00, got %s", updated.Balance)
+	}
+	accruals, _ := store.FindInterestAccruals(account.ID, time.Time{}, time.Time{})
+	if len(accruals) != 31 {
+		t.Fatalf("Expected 31 accruals, got %d", len(accruals))
+	}
+	for _, accrual := range accruals {
+		if accrual.Balance.String() != "36500.00" || accrual.Amount.String() != "1.00000000" || accrual.TransactionID != paid.ID {
+			t.Errorf("Expected a paid accrual of 1.00000000 on 36500.00, got %+v", accrual)
+		}
+	}
+
+	// The books stay balanced with the interest account paying
+	trialBalance, _ := services.CreateLedgerService(store).TrialBalance()
+	if !trialBalance.Balanced {
+		t.Errorf("Expected balanced books, got %+v", trialBalance)
+	}
+}
+
+func TestInterestThirty360(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account := openInterestAccount(t, store, requests.AccountRequest{InitialBalance: "12000", InterestRate: "0.05", DayCount: "30/360"}, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	interestService := services.CreateInterestService(store, utils.FixedClock{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)})
+
+	// February counts as 30 days, paying a twelfth of 5% of 12000.00
+	run, err := interestService.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Accrued != 29 || len(run.Paid) != 1 {
+		t.Fatalf("Expected 29 accrued days and one payment, got %+v", run)
+	}
+	if run.Paid[0].Amount.String() != "50.00" {
+		t.Errorf("Expected interest of 50.00, got %s", run.Paid[0].Amount)
+	}
+
+	// The last day of February makes up the missing days
+	last, err := store.FindLatestInterestAccrual(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !last.Date.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) || last.Amount.String() != "3.33333333" {
+		t.Errorf("Expected 3.33333333 on 2024-02-29, got %s on %s", last.Amount, last.Date)
+	}
+}
+
+func TestInterestRunIsIdempotent(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account := openInterestAccount(t, store, requests.AccountRequest{InitialBalance: "36500", InterestRate: "0.01"}, time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC))
+	clock := utils.FixedClock{Time: time.Date(2024, 2, 3, 8, 0, 0, 0, time.UTC)}
+	interestService := services.CreateInterestService(store, clock)
+
+	// The first run accrues up to yesterday and pays January
+	run, err := interestService.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Accrued != 14 || len(run.Paid) != 1 || run.Paid[0].Amount.String() != "12.00" {
+		t.Fatalf("Expected 14 accrued days and 12.00 paid, got %+v", run)
+	}
+
+	// Running again the same day or accruing the same range does nothing
+	run, err = interestService.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Accrued != 0 || len(run.Paid) != 0 {
+		t.Errorf("Expected nothing accrued, got %+v", run)
+	}
+	run, err = interestService.Accrue(requests.InterestAccrualRequest{From: "2024-01-01", To: "2024-02-03"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Accrued != 0 || len(run.Paid) != 0 {
+		t.Errorf("Expected nothing accrued, got %+v", run)
+	}
+
+	// A later run picks up where the last one stopped, paying once a month
+	interestService.Clock = utils.FixedClock{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	run, err = interestService.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Accrued != 27 || len(run.Paid) != 1 {
+		t.Fatalf("Expected 27 accrued days and one payment, got %+v", run)
+	}
+	transactions, _ := store.FindTransactions(account.ID)
+	if len(transactions) != 2 {
+		t.Errorf("Expected two interest transactions, got %d", len(transactions))
+	}
+}
+
+func TestInterestAccrueRange(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account := openInterestAccount(t, store, requests.AccountRequest{InitialBalance: "36500", InterestRate: "0.01"}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	interestService := services.CreateInterestService(store, utils.FixedClock{Time: time.Date(2024, 1, 11, 15, 0, 0, 0, time.UTC)})
+
+	// Ranges must be dates in order
+	for _, request := range []requests.InterestAccrualRequest{
+		{From: "2024-01-05", To: "2024-01-05"},
+		{From: "2024-01-05", To: "2024-01-01"},
+		{From: "January", To: "2024-01-01"},
+	} {
+		if _, err := interestService.Accrue(request); err != utils.ErrInvalidDateRange {
+			t.Errorf("Expected error %v for %+v, got %v", utils.ErrInvalidDateRange, request, err)
+		}
+	}
+
+	// The range stops at yesterday and a month is only paid once it ended
+	run, err := interestService.Accrue(requests.InterestAccrualRequest{From: "2024-01-05", To: "2024-12-31"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Accrued != 6 || len(run.Paid) != 0 {
+		t.Errorf("Expected 6 accrued days and no payment, got %+v", run)
+	}
+
+	// Running later fills the days before the range and pays the whole month
+	interestService.Clock = utils.FixedClock{Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+	run, err = interestService.Accrue(requests.InterestAccrualRequest{From: "2024-01-01", To: "2024-02-01"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.Accrued != 25 || len(run.Paid) != 1 || run.Paid[0].Amount.String() != "31.00" {
+		t.Errorf("Expected 25 accrued days and 31.00 paid, got %+v", run)
+	}
+	accruals, _ := store.FindInterestAccruals(account.ID, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+	if len(accruals) != 2 || accruals[0].TransactionID == uuid.Nil {
+		t.Errorf("Expected the first two days paid, got %+v", accruals)
+	}
+}
+
+func TestInterestOnlyForSavings(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+
+	// Checking accounts earn no interest and rates must be positive
+	_, err := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100", InterestRate: "0.02"})
+	if err != utils.ErrInterestNotAllowed {
+		t.Errorf("Expected error %v, got %v", utils.ErrInterestNotAllowed, err)
+	}
+	_, err = accountService.Create(requests.AccountRequest{Owner: "Alice", Type: "savings", InitialBalance: "100", InterestRate: "-0.02"})
+	if err != utils.ErrInvalidInterestRate {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidInterestRate, err)
+	}
+
+	// Customers cannot book interest themselves
+	account, err := accountService.Create(requests.AccountRequest{Owner: "Alice", Type: "savings", InitialBalance: "100", InterestRate: "0.02"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.InterestRate != (money.Rate{Units: 2, Scale: 2}) || account.DayCount != utils.Actual365 {
+		t.Errorf("Expected 0.02 ACT/365, got %s %s", account.InterestRate, account.DayCount)
+	}
+	_, err = services.CreateTransactionService(store).Create(account.ID.String(), requests.TransactionRequest{Type: "interest", Amount: "1"})
+	if err != utils.ErrInvalidTxType {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidTxType, err)
+	}
+}
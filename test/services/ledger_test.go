@@ -0,0 +1,112 @@
+package test
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"testing"
+)
+
+func TestTrialBalance(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	ledgerService := services.CreateLedgerService(storage)
+
+	// Open two accounts and move money between them
+	alice, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	bob, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "0"})
+	if _, err := transactionService.Create(alice.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "30"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: alice.ID.String(), ToAccountID: bob.ID.String(), Amount: "20"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Compute the trial balance
+	trialBalance, err := ledgerService.TrialBalance()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Validate the books balance and each line matches its account
+	if !trialBalance.Balanced {
+		t.Errorf("Expected balanced books, got %+v", trialBalance)
+	}
+	expected := map[string]string{"external": "-70.00", "Alice": "50.00", "Bob": "20.00"}
+	if len(trialBalance.Lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d", len(expected), len(trialBalance.Lines))
+	}
+	for _, line := range trialBalance.Lines {
+		if line.Balance.String() != expected[line.Name] {
+			t.Errorf("Expected %s balance %s, got %s", line.Name, expected[line.Name], line.Balance)
+		}
+	}
+	if len(trialBalance.Totals) != 1 || trialBalance.Totals[0].Debits.String() != "150.00" {
+		t.Errorf("Expected USD debits of 150.00, got %+v", trialBalance.Totals)
+	}
+}
+
+// Test that a cross-currency transfer balances each currency through the
+// exchange account
+func TestTrialBalance_CrossCurrency(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	ledgerService := services.CreateLedgerService(storage)
+	transactionService.Exchange.Update(requests.ExchangeRatesRequest{
+		Rates: []requests.ExchangeRateRequest{{From: "USD", To: "EUR", Rate: "0.9"}},
+	})
+
+	// Transfer from a dollar account into a euro account
+	alice, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	bob, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", Currency: "EUR", InitialBalance: "0"})
+	if _, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: alice.ID.String(), ToAccountID: bob.ID.String(), Amount: "10"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Compute the trial balance
+	trialBalance, err := ledgerService.TrialBalance()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Validate the exchange account holds both currency positions
+	if !trialBalance.Balanced {
+		t.Errorf("Expected balanced books, got %+v", trialBalance)
+	}
+	positions := map[string]string{}
+	for _, line := range trialBalance.Lines {
+		if line.AccountID == models.ExchangeAccountID {
+			positions[line.Currency] = line.Balance.String()
+		}
+	}
+	if positions["USD"] != "10.00" || positions["EUR"] != "-9.00" {
+		t.Errorf("Expected exchange positions of 10.00 USD and -9.00 EUR, got %v", positions)
+	}
+}
+
+// Test that a balance changed outside the ledger is reported
+func TestTrialBalance_DetectsDrift(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	ledgerService := services.CreateLedgerService(storage)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+
+	// Change the balance without posting a journal entry
+	account.Balance, _ = account.Balance.Add(account.Balance)
+	storage.UpdateAccount(account)
+
+	// Validate the trial balance no longer balances
+	trialBalance, err := ledgerService.TrialBalance()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if trialBalance.Balanced {
+		t.Errorf("Expected unbalanced books, got %+v", trialBalance)
+	}
+}
@@ -0,0 +1,183 @@
+package test
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"testing"
+)
+
+func TestReverse_PartialRefund(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account, err := services.CreateAccountService(store).Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transactionService := services.CreateTransactionService(store)
+	withdrawal, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "60"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Refund part of the withdrawal
+	reversals, err := transactionService.Reverse(withdrawal.ID.String(), requests.ReversalRequest{Amount: "25"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(reversals) != 1 || reversals[0].Type != utils.Refund || reversals[0].Amount.String() != "25.00" || reversals[0].RelatedID != withdrawal.ID {
+		t.Errorf("Expected a refund of 25.00 linked to %s, got %+v", withdrawal.ID, reversals)
+	}
+
+	// The rest cannot be exceeded but can be refunded by default
+	if _, err := transactionService.Reverse(withdrawal.ID.String(), requests.ReversalRequest{Amount: "35.01"}); err != utils.ErrReversalExceedsAmount {
+		t.Errorf("Expected ErrReversalExceedsAmount, got %v", err)
+	}
+	reversals, err = transactionService.Reverse(withdrawal.ID.String(), requests.ReversalRequest{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reversals[0].Amount.String() != "35.00" {
+		t.Errorf("Expected a refund of 35.00, got %s", reversals[0].Amount)
+	}
+
+	// Nothing is left to refund a second time
+	if _, err := transactionService.Reverse(withdrawal.ID.String(), requests.ReversalRequest{}); err != utils.ErrReversalExceedsAmount {
+		t.Errorf("Expected ErrReversalExceedsAmount, got %v", err)
+	}
+	updated, _ := store.FindAccount(account.ID)
+	if updated.Balance.String() != "100.00" {
+		t.Errorf("Expected balance 100.00, got %s", updated.Balance)
+	}
+}
+
+func TestReverse_Deposit(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account, err := services.CreateAccountService(store).Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "0"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transactionService := services.CreateTransactionService(store)
+	deposit, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "50"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A deposit that was spent cannot be reversed
+	if _, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "30"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := transactionService.Reverse(deposit.ID.String(), requests.ReversalRequest{}); err != utils.ErrInsufficientFunds {
+		t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+	}
+
+	// What is still there can be taken back
+	reversals, err := transactionService.Reverse(deposit.ID.String(), requests.ReversalRequest{Amount: "20"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reversals[0].Type != utils.Reversal || reversals[0].Amount.String() != "20.00" {
+		t.Errorf("Expected a reversal of 20.00, got %+v", reversals[0])
+	}
+	updated, _ := store.FindAccount(account.ID)
+	if !updated.Balance.IsZero() {
+		t.Errorf("Expected a zero balance, got %s", updated.Balance)
+	}
+
+	// Reversals themselves cannot be reversed
+	if _, err := transactionService.Reverse(reversals[0].ID.String(), requests.ReversalRequest{}); err != utils.ErrNotReversible {
+		t.Errorf("Expected ErrNotReversible, got %v", err)
+	}
+}
+
+func TestReverse_CrossCurrencyTransfer(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	transactionService.Exchange.Update(requests.ExchangeRatesRequest{Rates: []requests.ExchangeRateRequest{
+		{From: "USD", To: "JPY", Rate: "149.505"},
+	}})
+	dollars, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", Currency: "USD", InitialBalance: "100"})
+	yen, _ := accountService.Create(requests.AccountRequest{Owner: "Charlie", Currency: "JPY", InitialBalance: "0"})
+	transfer, err := transactionService.Transfer(requests.TransferRequest{
+		FromAccountID: dollars.ID.String(),
+		ToAccountID:   yen.ID.String(),
+		Amount:        "10.01",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Reversing from the deposit leg undoes both legs at the transfer rate:
+	// 5.00 * 149.505 = 747.525
+	reversals, err := transactionService.Reverse(transfer.Deposit.ID.String(), requests.ReversalRequest{Amount: "5"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(reversals) != 2 {
+		t.Fatalf("Expected two reversals, got %d", len(reversals))
+	}
+	if reversals[0].Type != utils.Refund || reversals[0].Amount.String() != "5.00" || reversals[0].RelatedID != transfer.Withdrawal.ID {
+		t.Errorf("Expected a refund of 5.00 to the source, got %+v", reversals[0])
+	}
+	if reversals[1].Type != utils.Reversal || reversals[1].Amount.String() != "748" || reversals[1].RelatedID != transfer.Deposit.ID {
+		t.Errorf("Expected a reversal of 748 from the destination, got %+v", reversals[1])
+	}
+
+	// The rest of the transfer restores both balances exactly
+	if _, err := transactionService.Reverse(transfer.Withdrawal.ID.String(), requests.ReversalRequest{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	dollars, _ = accountService.ReadOne(dollars.ID.String())
+	yen, _ = accountService.ReadOne(yen.ID.String())
+	if dollars.Balance.String() != "100.00" || !yen.Balance.IsZero() {
+		t.Errorf("Expected balances 100.00 and 0, got %s and %s", dollars.Balance, yen.Balance)
+	}
+	trialBalance, _ := services.CreateLedgerService(store).TrialBalance()
+	if !trialBalance.Balanced {
+		t.Errorf("Expected a balanced trial balance, got %+v", trialBalance)
+	}
+}
+
+func TestReverse_FrozenAccount(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	account, err := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transactionService := services.CreateTransactionService(store)
+	transactionService.Fees.Update(requests.FeeScheduleRequest{Rules: []requests.FeeRuleRequest{{Operation: "deposit", Flat: "1"}}})
+	deposit, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "50"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	withdrawal, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "20"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := accountService.Freeze(account.ID.String()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Reversing a deposit takes money out of the frozen account
+	if _, err := transactionService.Reverse(deposit.ID.String(), requests.ReversalRequest{}); err != utils.ErrAccountFrozen {
+		t.Errorf("Expected ErrAccountFrozen, got %v", err)
+	}
+
+	// Refunds and deposits still come in, without being charged a fee
+	if _, err := transactionService.Reverse(withdrawal.ID.String(), requests.ReversalRequest{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "10"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	updated, _ := store.FindAccount(account.ID)
+	if updated.Balance.String() != "159.00" {
+		t.Errorf("Expected balance 159.00, got %s", updated.Balance)
+	}
+}
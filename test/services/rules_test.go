@@ -0,0 +1,147 @@
+package test
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"testing"
+	"time"
+)
+
+func TestSavingsWithdrawalLimit(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	transactionService.Rules.SavingsWithdrawals = 2
+	savings, err := accountService.Create(requests.AccountRequest{Owner: "Alice", Type: "savings", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if savings.Type != utils.Savings {
+		t.Errorf("Expected a savings account, got %s", savings.Type)
+	}
+	id := savings.ID.String()
+
+	// Two withdrawals a month are allowed, deposits are not limited
+	withdrawal := requests.TransactionRequest{Type: "withdrawal", Amount: "10"}
+	for i := 0; i < 2; i++ {
+		if _, err := transactionService.Create(id, withdrawal); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if _, err := transactionService.Create(id, requests.TransactionRequest{Type: "deposit", Amount: "10"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := transactionService.Create(id, withdrawal); err != utils.ErrWithdrawalLimit {
+		t.Errorf("Expected error %v, got %v", utils.ErrWithdrawalLimit, err)
+	}
+
+	// Transfers out count as withdrawals
+	other, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "0"})
+	_, err = transactionService.Transfer(requests.TransferRequest{FromAccountID: id, ToAccountID: other.ID.String(), Amount: "1"})
+	if err != utils.ErrWithdrawalLimit {
+		t.Errorf("Expected error %v, got %v", utils.ErrWithdrawalLimit, err)
+	}
+}
+
+func TestTermDepositLocked(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+
+	// Term deposits need a maturity date in the future, other types none
+	if _, err := accountService.Create(requests.AccountRequest{Owner: "Alice", Type: "term", MaturityDate: "2001-01-01", InitialBalance: "100"}); err != utils.ErrInvalidMaturity {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidMaturity, err)
+	}
+	if _, err := accountService.Create(requests.AccountRequest{Owner: "Alice", MaturityDate: "2101-01-01", InitialBalance: "100"}); err != utils.ErrInvalidMaturity {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidMaturity, err)
+	}
+	term, err := accountService.Create(requests.AccountRequest{Owner: "Alice", Type: "term", MaturityDate: "2101-01-01", InitialBalance: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !term.MaturesAt.Equal(time.Date(2101, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected maturity on 2101-01-01, got %s", term.MaturesAt)
+	}
+
+	// Nothing can be withdrawn before maturity, deposits are accepted
+	if _, err := transactionService.Create(term.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "1"}); err != utils.ErrAccountLocked {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountLocked, err)
+	}
+	if _, err := transactionService.Create(term.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "1"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// Once matured the deposit pays out
+	term, _ = accountService.ReadOne(term.ID.String())
+	term.MaturesAt = time.Now().Add(-time.Second)
+	storage.UpdateAccount(term)
+	if _, err := transactionService.Create(term.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "101"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestCheckingTransactionFee(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	transactionService.Rules.CheckingFreeTransactions = 2
+	transactionService.Rules.CheckingTransactionFees = map[string]money.Money{"USD": money.New(50, 2), "JPY": money.New(200, 0)}
+	checking, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	yen, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", Currency: "JPY", InitialBalance: "100"})
+	id := checking.ID.String()
+
+	// The first two transactions of the cycle are free, the opening balance is not counted
+	deposit := requests.TransactionRequest{Type: "deposit", Amount: "10"}
+	for i := 0; i < 3; i++ {
+		if _, err := transactionService.Create(id, deposit); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	checking, _ = accountService.ReadOne(id)
+	if checking.Balance.String() != "129.50" {
+		t.Errorf("Expected balance 129.50, got %s", checking.Balance)
+	}
+
+	// The fee is its own transaction and is not counted against the allowance
+	feeType := utils.Fee
+	fees, _ := storage.SearchTransactions(models.TransactionFilter{AccountID: checking.ID, Type: &feeType, Limit: 10})
+	if len(fees) != 1 || fees[0].Amount.String() != "0.50" {
+		t.Errorf("Expected one fee of 0.50, got %+v", fees)
+	}
+
+	// Every currency is charged its own fee, and currencies without one none
+	euro, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", Currency: "EUR", InitialBalance: "100"})
+	for i := 0; i < 3; i++ {
+		transactionService.Create(yen.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "1000"})
+		transactionService.Create(euro.ID.String(), deposit)
+	}
+	yen, _ = accountService.ReadOne(yen.ID.String())
+	if yen.Balance.String() != "2900" {
+		t.Errorf("Expected balance 2900, got %s", yen.Balance)
+	}
+	euro, _ = accountService.ReadOne(euro.ID.String())
+	if euro.Balance.String() != "130.00" {
+		t.Errorf("Expected balance 130.00, got %s", euro.Balance)
+	}
+
+	// Customers cannot create fees themselves
+	if _, err := transactionService.Create(id, requests.TransactionRequest{Type: "fee", Amount: "1"}); err != utils.ErrInvalidTxType {
+		t.Errorf("Expected error %v, got %v", utils.ErrInvalidTxType, err)
+	}
+
+	// Fees keep the books balanced
+	trialBalance, err := services.CreateLedgerService(storage).TrialBalance()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !trialBalance.Balanced {
+		t.Errorf("Expected a balanced trial balance, got %+v", trialBalance)
+	}
+}
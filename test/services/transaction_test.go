@@ -1,11 +1,20 @@
 package test
 
 import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
 	"bank-account-manager/requests"
 	"bank-account-manager/services"
 	"bank-account-manager/storage"
 	"bank-account-manager/utils"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 func TestCreateTransaction(t *testing.T) {
@@ -17,14 +26,14 @@ func TestCreateTransaction(t *testing.T) {
 	// Create an account
 	accountRequest := requests.AccountRequest{
 		Owner:          "Alice",
-		InitialBalance: 2000,
+		InitialBalance: "2000",
 	}
 	account, _ := accountService.Create(accountRequest)
 
 	// Test data for transaction
 	transactionRequest := requests.TransactionRequest{
 		Type:   "deposit",
-		Amount: 500,
+		Amount: "500",
 	}
 
 	// Create transaction
@@ -39,6 +48,192 @@ func TestCreateTransaction(t *testing.T) {
 	}
 }
 
+// Test that repeated small deposits add up exactly
+func TestCreateTransaction_ExactArithmetic(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "0"})
+
+	// Deposit 0.1 ten times
+	for index := 0; index < 10; index++ {
+		_, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "0.1"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Validate the balance
+	account, _ = accountService.ReadOne(account.ID.String())
+	if account.Balance.String() != "1.00" {
+		t.Errorf("Expected balance 1.00, got %s", account.Balance)
+	}
+}
+
+// Test that deposits overflowing the balance are rejected and leave the
+// books balanced
+func TestCreateTransaction_BalanceOverflow(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	ledgerService := services.CreateLedgerService(storage)
+	account, err := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "9000000000000000"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Deposit the largest amount until the balance no longer holds it
+	for index := 0; ; index++ {
+		_, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "9000000000000000"})
+		if err == utils.ErrInvalidAmount {
+			break
+		}
+		if err != nil || index == 10 {
+			t.Fatalf("Expected the balance to overflow, got %v after %d deposits", err, index)
+		}
+	}
+
+	// Validate the balance never wrapped around and the books still balance
+	account, _ = accountService.ReadOne(account.ID.String())
+	if account.Balance.String() != "90000000000000000.00" {
+		t.Errorf("Expected balance 90000000000000000.00, got %s", account.Balance)
+	}
+	trialBalance, err := ledgerService.TrialBalance()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !trialBalance.Balanced {
+		t.Errorf("Expected balanced books, got %+v", trialBalance)
+	}
+}
+
+// Test that amounts with too many decimal places are rejected
+func TestCreateTransaction_AmountPrecision(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+
+	// Attempt to deposit a fraction of a cent
+	_, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "0.001"})
+	if err != utils.ErrAmountPrecision {
+		t.Fatalf("Expected error %v, got %v", utils.ErrAmountPrecision, err)
+	}
+
+	// Attempt to deposit a negative amount
+	_, err = transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "-5"})
+	if err != utils.ErrInvalidAmount {
+		t.Fatalf("Expected error %v, got %v", utils.ErrInvalidAmount, err)
+	}
+}
+
+// Test that transactions in a different currency than the account are rejected
+func TestCreateTransaction_CurrencyMismatch(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", Currency: "EUR", InitialBalance: "100"})
+
+	// Attempt to deposit dollars into a euro account
+	_, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "10", Currency: "USD"})
+	if err != utils.ErrCurrencyMismatch {
+		t.Fatalf("Expected error %v, got %v", utils.ErrCurrencyMismatch, err)
+	}
+
+	// Deposits in the account currency are recorded with it
+	transaction, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "10", Currency: "eur"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if transaction.Currency != "EUR" {
+		t.Errorf("Expected currency EUR, got %s", transaction.Currency)
+	}
+}
+
+// Test that transfers between currencies without an exchange rate are rejected
+func TestTransfer_RateNotFound(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	fromAccount, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", Currency: "USD", InitialBalance: "100"})
+	toAccount, _ := accountService.Create(requests.AccountRequest{Owner: "Charlie", Currency: "JPY", InitialBalance: "100"})
+
+	// Attempt the transfer
+	_, err := transactionService.Transfer(requests.TransferRequest{
+		FromAccountID: fromAccount.ID.String(),
+		ToAccountID:   toAccount.ID.String(),
+		Amount:        "10",
+	})
+	if err != utils.ErrRateNotFound {
+		t.Fatalf("Expected error %v, got %v", utils.ErrRateNotFound, err)
+	}
+}
+
+// Test that cross-currency transfers convert the amount and record the rate
+func TestTransfer_CrossCurrency(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	transactionService.Exchange.Update(requests.ExchangeRatesRequest{Rates: []requests.ExchangeRateRequest{
+		{From: "USD", To: "JPY", Rate: "149.505"},
+	}})
+	dollars, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", Currency: "USD", InitialBalance: "100"})
+	yen, _ := accountService.Create(requests.AccountRequest{Owner: "Charlie", Currency: "JPY", InitialBalance: "0"})
+
+	// Transfer dollars to the yen account: 10.01 * 149.505 = 1496.54505
+	_, err := transactionService.Transfer(requests.TransferRequest{
+		FromAccountID: dollars.ID.String(),
+		ToAccountID:   yen.ID.String(),
+		Amount:        "10.01",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Validate the converted balances
+	dollars, _ = accountService.ReadOne(dollars.ID.String())
+	yen, _ = accountService.ReadOne(yen.ID.String())
+	if dollars.Balance.String() != "89.99" {
+		t.Errorf("Expected balance 89.99, got %s", dollars.Balance)
+	}
+	if yen.Balance.String() != "1497" {
+		t.Errorf("Expected balance 1497, got %s", yen.Balance)
+	}
+
+	// Validate the exchange recorded on both legs
+	withdrawals, _ := transactionService.ReadByAccount(dollars.ID.String())
+	deposits, _ := transactionService.ReadByAccount(yen.ID.String())
+	for _, transaction := range []models.Transaction{withdrawals[0], deposits[0]} {
+		exchange := transaction.Exchange
+		if exchange == nil {
+			t.Fatalf("Expected an exchange record on %s leg", transaction.Type)
+		}
+		if exchange.Rate.String() != "149.505" || exchange.SourceAmount.String() != "10.01" || exchange.DestinationAmount.String() != "1497" {
+			t.Errorf("Unexpected exchange record %+v", exchange)
+		}
+	}
+
+	// Transfer back using the inverse rate: 1497 / 149.505 = 10.01304...
+	_, err = transactionService.Transfer(requests.TransferRequest{
+		FromAccountID: yen.ID.String(),
+		ToAccountID:   dollars.ID.String(),
+		Amount:        "1497",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	dollars, _ = accountService.ReadOne(dollars.ID.String())
+	if dollars.Balance.String() != "100.00" {
+		t.Errorf("Expected balance 100.00, got %s", dollars.Balance)
+	}
+}
+
 func TestTransfer(t *testing.T) {
 	// Setup
 	storage := storage.Create()
@@ -46,17 +241,17 @@ func TestTransfer(t *testing.T) {
 	transactionService := services.CreateTransactionService(storage)
 
 	// Create accounts
-	fromAccount, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: 1000})
-	toAccount, _ := accountService.Create(requests.AccountRequest{Owner: "Charlie", InitialBalance: 500})
+	fromAccount, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "1000"})
+	toAccount, _ := accountService.Create(requests.AccountRequest{Owner: "Charlie", InitialBalance: "500"})
 
 	// Test transfer
 	transferRequest := requests.TransferRequest{
 		FromAccountID: fromAccount.ID.String(),
 		ToAccountID:   toAccount.ID.String(),
-		Amount:        300,
+		Amount:        "300",
 	}
 
-	err := transactionService.Transfer(transferRequest)
+	_, err := transactionService.Transfer(transferRequest)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -65,14 +260,305 @@ func TestTransfer(t *testing.T) {
 	toAccount, _ = accountService.ReadOne(toAccount.ID.String())
 
 	// Validate balances
-	if fromAccount.Balance != 700 {
-		t.Errorf("Expected from account balance 700, got %f", fromAccount.Balance)
+	if fromAccount.Balance.String() != "700.00" {
+		t.Errorf("Expected from account balance 700.00, got %s", fromAccount.Balance)
 	}
-	if toAccount.Balance != 800 {
-		t.Errorf("Expected to account balance 800, got %f", toAccount.Balance)
+	if toAccount.Balance.String() != "800.00" {
+		t.Errorf("Expected to account balance 800.00, got %s", toAccount.Balance)
 	}
 }
 
+// Test that a failed deposit leg leaves both accounts untouched
+func TestTransfer_RollbackOnFailedDeposit(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+
+	// Create the source account only
+	fromAccount, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "1000"})
+
+	// Attempt to transfer to a non-existent account
+	_, err := transactionService.Transfer(requests.TransferRequest{
+		FromAccountID: fromAccount.ID.String(),
+		ToAccountID:   uuid.New().String(),
+		Amount:        "300",
+	})
+	if err != utils.ErrAccountNotFound {
+		t.Fatalf("Expected error %v, got %v", utils.ErrAccountNotFound, err)
+	}
+
+	// Validate that the withdrawal was rolled back
+	fromAccount, _ = accountService.ReadOne(fromAccount.ID.String())
+	if fromAccount.Balance.String() != "1000.00" {
+		t.Errorf("Expected from account balance 1000.00, got %s", fromAccount.Balance)
+	}
+	transactions, _ := transactionService.ReadByAccount(fromAccount.ID.String())
+	if len(transactions) != 0 {
+		t.Errorf("Expected 0 transactions, got %d", len(transactions))
+	}
+}
+
+// Test that concurrent transfers never create or destroy money
+func TestTransfer_ConcurrentConservesMoney(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+
+	// Create accounts with a known total balance
+	const accountCount = 10
+	accountIDs := []string{}
+	for index := 0; index < accountCount; index++ {
+		account, _ := accountService.Create(requests.AccountRequest{Owner: "Owner", InitialBalance: "1000"})
+		accountIDs = append(accountIDs, account.ID.String())
+	}
+
+	// Run random transfers from many goroutines, including ones that fail
+	// for insufficient funds or an unknown destination
+	var wait sync.WaitGroup
+	for worker := 0; worker < 20; worker++ {
+		wait.Add(1)
+		go func(seed int64) {
+			defer wait.Done()
+			random := rand.New(rand.NewSource(seed))
+			for step := 0; step < 200; step++ {
+				toAccountID := accountIDs[random.Intn(accountCount)]
+				if random.Intn(10) == 0 {
+					toAccountID = uuid.New().String()
+				}
+				transactionService.Transfer(requests.TransferRequest{
+					FromAccountID: accountIDs[random.Intn(accountCount)],
+					ToAccountID:   toAccountID,
+					Amount:        money.Decimal(money.New(int64(random.Intn(50000)+1), money.DefaultScale).String()),
+				})
+			}
+		}(int64(worker))
+	}
+	wait.Wait()
+
+	// Validate that the total balance is unchanged and never negative
+	accounts, _ := accountService.ReadAll()
+	total := money.Zero(money.DefaultScale)
+	for _, account := range accounts {
+		if account.Balance.IsNegative() {
+			t.Errorf("Expected non-negative balance, got %s", account.Balance)
+		}
+		total, _ = total.Add(account.Balance)
+	}
+	if total.String() != "10000.00" {
+		t.Errorf("Expected total balance 10000.00, got %s", total)
+	}
+}
+
+// meetingClock holds the first calls to Now until as many were made as it
+// expects, so that callers inside their units of work only get past it
+// together. Callers that cannot all be inside at once give up after a
+// second, which it remembers
+type meetingClock struct {
+	mutex    sync.Mutex
+	expected int
+	met      chan struct{}
+	missed   bool
+}
+
+func (clock *meetingClock) Now() time.Time {
+	clock.mutex.Lock()
+	if clock.expected--; clock.expected == 0 {
+		close(clock.met)
+	}
+	clock.mutex.Unlock()
+
+	select {
+	case <-clock.met:
+	case <-time.After(time.Second):
+		clock.mutex.Lock()
+		clock.missed = true
+		clock.mutex.Unlock()
+	}
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Test that deposits into different accounts run at the same time while
+// deposits into the same account take turns
+func TestCreateTransaction_DisjointAccountsOverlap(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	first, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "0"})
+	second, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "0"})
+
+	// Deposit twice at once, every deposit reading the clock inside its
+	// unit of work
+	depositTwice := func(firstID string, secondID string) bool {
+		clock := &meetingClock{expected: 2, met: make(chan struct{})}
+		transactionService.Clock = clock
+		var wait sync.WaitGroup
+		for _, accountID := range []string{firstID, secondID} {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				if _, err := transactionService.Create(accountID, requests.TransactionRequest{Type: "deposit", Amount: "10"}); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			}()
+		}
+		wait.Wait()
+		return !clock.missed
+	}
+
+	// Validate that only the deposits into different accounts overlapped
+	if !depositTwice(first.ID.String(), second.ID.String()) {
+		t.Error("Expected deposits into different accounts to run at the same time")
+	}
+	if depositTwice(first.ID.String(), first.ID.String()) {
+		t.Error("Expected deposits into the same account to take turns")
+	}
+	if account, _ := accountService.ReadOne(first.ID.String()); account.Balance.String() != "30.00" {
+		t.Errorf("Expected balance 30.00, got %s", account.Balance)
+	}
+}
+
+// Test that deposits, withdrawals, transfers, holds and reversals running
+// alongside reads on many goroutines keep the books balanced. Run with -race
+// to also check the store for data races
+func TestConcurrentOperations_Stress(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	ledgerService := services.CreateLedgerService(storage)
+	eventService := services.CreateEventService(storage)
+
+	// Create accounts with a known total balance
+	const accountCount = 16
+	accountIDs := []string{}
+	for index := 0; index < accountCount; index++ {
+		account, _ := accountService.Create(requests.AccountRequest{Owner: "Owner", InitialBalance: "1000"})
+		accountIDs = append(accountIDs, account.ID.String())
+	}
+
+	// Track the money entering and leaving the accounts
+	var flowMutex sync.Mutex
+	flow := money.Zero(money.DefaultScale)
+	move := func(amount money.Money) {
+		flowMutex.Lock()
+		defer flowMutex.Unlock()
+		flow, _ = flow.Add(amount)
+	}
+
+	// Run random operations from many goroutines, checking the books
+	// whenever one reads them
+	var wait sync.WaitGroup
+	for worker := 0; worker < 16; worker++ {
+		wait.Add(1)
+		go func(seed int64) {
+			defer wait.Done()
+			random := rand.New(rand.NewSource(seed))
+			for step := 0; step < 150; step++ {
+				accountID := accountIDs[random.Intn(accountCount)]
+				amount := money.Decimal(money.New(int64(random.Intn(20000)+1), money.DefaultScale).String())
+				switch random.Intn(8) {
+				case 0:
+					if transaction, err := transactionService.Create(accountID, requests.TransactionRequest{Type: "deposit", Amount: amount}); err == nil {
+						move(transaction.Amount)
+					}
+				case 1:
+					if transaction, err := transactionService.Create(accountID, requests.TransactionRequest{Type: "withdrawal", Amount: amount}); err == nil {
+						move(transaction.Amount.Neg())
+					}
+				case 2:
+					transactionService.Transfer(requests.TransferRequest{
+						FromAccountID: accountID,
+						ToAccountID:   accountIDs[random.Intn(accountCount)],
+						Amount:        amount,
+					})
+				case 3:
+					hold, err := transactionService.PlaceHold(accountID, requests.HoldRequest{Amount: amount})
+					if err != nil {
+						continue
+					}
+					if random.Intn(2) == 0 {
+						transactionService.ReleaseHold(hold.ID.String(), "")
+					} else if captured, err := transactionService.CaptureHold(hold.ID.String(), requests.CaptureRequest{}); err == nil {
+						move(captured.Captured.Neg())
+					}
+				case 4:
+					transaction, err := transactionService.Create(accountID, requests.TransactionRequest{Type: "deposit", Amount: amount})
+					if err != nil {
+						continue
+					}
+					move(transaction.Amount)
+					if reversals, err := transactionService.Reverse(transaction.ID.String(), requests.ReversalRequest{}); err == nil {
+						move(reversals[0].Amount.Neg())
+					}
+				case 5:
+					if trialBalance, err := ledgerService.TrialBalance(); err != nil || !trialBalance.Balanced {
+						t.Errorf("Expected balanced books, got %+v and %v", trialBalance, err)
+					}
+				case 6:
+					account, err := accountService.ReadOne(accountID)
+					_, balanceErr := eventService.Balance(accountID, requests.BalanceQuery{})
+					if err != nil || balanceErr != nil || account.Balance.IsNegative() {
+						t.Errorf("Expected a non-negative account, got %+v and %v, %v", account, err, balanceErr)
+					}
+				default:
+					if _, err := transactionService.Search(accountID, requests.TransactionQuery{Limit: 10}); err != nil {
+						t.Errorf("Expected no error, got %v", err)
+					}
+				}
+			}
+		}(int64(worker))
+	}
+	wait.Wait()
+
+	// Validate that the accounts hold what they started with and the money
+	// that entered or left them, and that their events agree
+	accounts, _ := accountService.ReadAll()
+	total := money.Zero(money.DefaultScale)
+	for _, account := range accounts {
+		total, _ = total.Add(account.Balance)
+		balance, err := eventService.Balance(account.ID.String(), requests.BalanceQuery{})
+		if err != nil || balance.Balance != account.Balance || balance.Held != account.Held {
+			t.Errorf("Expected projected balance %s held %s, got %+v and %v", account.Balance, account.Held, balance, err)
+		}
+	}
+	expected, _ := money.New(1000_00*accountCount, money.DefaultScale).Add(flow)
+	if total != expected {
+		t.Errorf("Expected total balance %s, got %s", expected, total)
+	}
+	if trialBalance, _ := ledgerService.TrialBalance(); !trialBalance.Balanced {
+		t.Errorf("Expected balanced books, got %+v", trialBalance)
+	}
+}
+
+// BenchmarkParallelDeposits measures deposits into distinct accounts from
+// parallel goroutines. Units of work on different accounts do not wait on
+// each other, so throughput grows with the cores given by -cpu 1,2,4,8
+func BenchmarkParallelDeposits(b *testing.B) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+
+	// Every goroutine deposits into an account of its own
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		account, err := accountService.Create(requests.AccountRequest{Owner: "Owner", InitialBalance: "0"})
+		if err != nil {
+			b.Errorf("Expected no error, got %v", err)
+			return
+		}
+		for pb.Next() {
+			if _, err := transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: "1"}); err != nil {
+				b.Errorf("Expected no error, got %v", err)
+				return
+			}
+		}
+	})
+}
+
 func TestReadByAccount(t *testing.T) {
 	// Setup
 	storage := storage.Create()
@@ -82,20 +568,20 @@ func TestReadByAccount(t *testing.T) {
 	// Create an account
 	accountRequest := requests.AccountRequest{
 		Owner:          "Alice",
-		InitialBalance: 2000,
+		InitialBalance: "2000",
 	}
 	account, _ := accountService.Create(accountRequest)
 
 	// Create transactions
 	transactionRequest1 := requests.TransactionRequest{
 		Type:   "deposit",
-		Amount: 500,
+		Amount: "500",
 	}
 	transactionService.Create(account.ID.String(), transactionRequest1)
 
 	transactionRequest2 := requests.TransactionRequest{
 		Type:   "withdrawal",
-		Amount: 200,
+		Amount: "200",
 	}
 	transactionService.Create(account.ID.String(), transactionRequest2)
 
@@ -124,6 +610,77 @@ func TestReadByAccount_NotFound(t *testing.T) {
 	}
 }
 
+// Test that filtered pages follow each other without gaps or repeats
+func TestSearch_FilterAndPaginate(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "0"})
+
+	// Deposit 1 to 7 and withdraw 1 in between
+	for amount := 1; amount <= 7; amount++ {
+		transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "deposit", Amount: money.Decimal(strconv.Itoa(amount))})
+	}
+	transactionService.Create(account.ID.String(), requests.TransactionRequest{Type: "withdrawal", Amount: "1"})
+
+	// Page through deposits of at least 2, newest first, three at a time
+	query := requests.TransactionQuery{Type: "deposit", MinAmount: "2", Sort: "desc", Limit: 3}
+	amounts := []string{}
+	pages := 0
+	for {
+		page, err := transactionService.Search(account.ID.String(), query)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		pages++
+		for _, transaction := range page.Transactions {
+			amounts = append(amounts, transaction.Amount.String())
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	// Validate the pages
+	expected := "7.00 6.00 5.00 4.00 3.00 2.00"
+	if strings.Join(amounts, " ") != expected {
+		t.Errorf("Expected amounts %s, got %s", expected, strings.Join(amounts, " "))
+	}
+	if pages != 2 {
+		t.Errorf("Expected 2 pages, got %d", pages)
+	}
+}
+
+// Test for error cases of a transaction search
+func TestSearch_InvalidQuery(t *testing.T) {
+	// Setup
+	storage := storage.Create()
+	accountService := services.CreateAccountService(storage)
+	transactionService := services.CreateTransactionService(storage)
+	account, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "0"})
+
+	// Attempt searches with invalid parameters
+	cases := []struct {
+		query    requests.TransactionQuery
+		expected error
+	}{
+		{requests.TransactionQuery{Cursor: "not a cursor"}, utils.ErrInvalidCursor},
+		{requests.TransactionQuery{From: "yesterday"}, utils.ErrInvalidQuery},
+		{requests.TransactionQuery{Type: "gift"}, utils.ErrInvalidTxType},
+		{requests.TransactionQuery{MinAmount: "0.001"}, utils.ErrAmountPrecision},
+	}
+	for _, testCase := range cases {
+		if _, err := transactionService.Search(account.ID.String(), testCase.query); err != testCase.expected {
+			t.Errorf("Expected error %v for %+v, got %v", testCase.expected, testCase.query, err)
+		}
+	}
+	if _, err := transactionService.Search(uuid.New().String(), requests.TransactionQuery{}); err != utils.ErrAccountNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountNotFound, err)
+	}
+}
+
 // Test for error case when creating a transaction with an invalid account ID
 func TestCreateTransaction_InvalidAccountID(t *testing.T) {
 	// Setup
@@ -133,7 +690,7 @@ func TestCreateTransaction_InvalidAccountID(t *testing.T) {
 	// Attempt to create a transaction with an invalid account ID
 	transactionRequest := requests.TransactionRequest{
 		Type:   "deposit",
-		Amount: 500,
+		Amount: "500",
 	}
 	_, err := transactionService.Create("invalid-account-id", transactionRequest)
 	if err != utils.ErrInvalidUUID {
@@ -151,14 +708,14 @@ func TestCreateTransaction_InsufficientFunds(t *testing.T) {
 	// Create an account with a low balance
 	accountRequest := requests.AccountRequest{
 		Owner:          "Alice",
-		InitialBalance: 100,
+		InitialBalance: "100",
 	}
 	account, _ := accountService.Create(accountRequest)
 
 	// Attempt to create a withdrawal transaction that exceeds the balance
 	transactionRequest := requests.TransactionRequest{
 		Type:   "withdrawal",
-		Amount: 200,
+		Amount: "200",
 	}
 	_, err := transactionService.Create(account.ID.String(), transactionRequest)
 	if err != utils.ErrInsufficientFunds {
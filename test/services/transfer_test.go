@@ -0,0 +1,128 @@
+package test
+
+import (
+	"bank-account-manager/requests"
+	"bank-account-manager/services"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"strings"
+	"testing"
+)
+
+func TestTransfer_Record(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	alice, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	bob, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "0"})
+
+	// The transfer is recorded with both legs referencing it
+	created, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: alice.ID.String(), ToAccountID: bob.ID.String(), Amount: "40"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.Status != utils.TransferCompleted || created.Amount.String() != "40.00" || created.FromAccountID != alice.ID || created.ToAccountID != bob.ID {
+		t.Errorf("Expected a completed transfer of 40.00 from Alice to Bob, got %+v", created)
+	}
+	if created.Withdrawal.TransferID != created.ID || created.Deposit.TransferID != created.ID {
+		t.Errorf("Expected both legs to reference %s, got %+v", created.ID, created)
+	}
+
+	// Reading it back returns the same legs
+	transfer, err := transactionService.ReadTransfer(created.ID.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if transfer.Withdrawal.ID != created.Withdrawal.ID || transfer.Deposit.ID != created.Deposit.ID || len(transfer.Fees) != 0 {
+		t.Errorf("Expected %+v, got %+v", created, transfer)
+	}
+	if _, err := transactionService.ReadTransfer("not-a-uuid"); err != utils.ErrInvalidUUID {
+		t.Errorf("Expected ErrInvalidUUID, got %v", err)
+	}
+
+	// Reversing part of it and then the rest updates its status
+	if _, err := transactionService.Reverse(created.Deposit.ID.String(), requests.ReversalRequest{Amount: "10"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transfer, _ = transactionService.ReadTransfer(created.ID.String())
+	if transfer.Status != utils.TransferPartiallyReversed {
+		t.Errorf("Expected a partially reversed transfer, got %s", transfer.Status)
+	}
+	if _, err := transactionService.Reverse(created.Withdrawal.ID.String(), requests.ReversalRequest{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	transfer, _ = transactionService.ReadTransfer(created.ID.String())
+	if transfer.Status != utils.TransferReversed {
+		t.Errorf("Expected a reversed transfer, got %s", transfer.Status)
+	}
+}
+
+func TestTransfer_SameAccount(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	alice, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+
+	// The same account spelled differently is still the same account
+	from := alice.ID.String()
+	to := strings.ToUpper(from)
+	if _, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: from, ToAccountID: to, Amount: "40"}); err != utils.ErrSameAccountTransfer {
+		t.Errorf("Expected ErrSameAccountTransfer, got %v", err)
+	}
+
+	// Nothing was moved
+	account, err := accountService.ReadOne(from)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.Balance.String() != "100.00" {
+		t.Errorf("Expected a balance of 100.00, got %s", account.Balance)
+	}
+}
+
+func TestSearchTransfers(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	accountService := services.CreateAccountService(store)
+	transactionService := services.CreateTransactionService(store)
+	alice, _ := accountService.Create(requests.AccountRequest{Owner: "Alice", InitialBalance: "100"})
+	bob, _ := accountService.Create(requests.AccountRequest{Owner: "Bob", InitialBalance: "100"})
+	carol, _ := accountService.Create(requests.AccountRequest{Owner: "Carol", InitialBalance: "100"})
+	for _, pair := range [][2]string{{alice.ID.String(), bob.ID.String()}, {bob.ID.String(), carol.ID.String()}, {carol.ID.String(), alice.ID.String()}} {
+		if _, err := transactionService.Transfer(requests.TransferRequest{FromAccountID: pair[0], ToAccountID: pair[1], Amount: "1"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Page through every transfer two at a time
+	page, err := transactionService.SearchTransfers(requests.TransferQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(page.Transfers) != 2 || page.NextCursor == "" {
+		t.Fatalf("Expected a full first page with a cursor, got %+v", page)
+	}
+	page, err = transactionService.SearchTransfers(requests.TransferQuery{Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(page.Transfers) != 1 || page.NextCursor != "" {
+		t.Errorf("Expected one last transfer, got %+v", page)
+	}
+
+	// Only the transfers from or to Alice are listed for her account
+	page, err = transactionService.SearchTransfers(requests.TransferQuery{AccountID: alice.ID.String()})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(page.Transfers) != 2 {
+		t.Errorf("Expected two transfers of Alice, got %d", len(page.Transfers))
+	}
+	for _, transfer := range page.Transfers {
+		if transfer.FromAccountID != alice.ID && transfer.ToAccountID != alice.ID {
+			t.Errorf("Expected a transfer of Alice, got %+v", transfer)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestJournalReplaysAcrossReopen(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "bank.journal")
+	store, err := storage.CreateJournaled(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Commit an account with a transaction in one unit of work, then change it
+	account := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "EUR", Balance: money.New(5000, 2), Held: money.New(0, 2), CreatedAt: time.Unix(1700000000, 0).UTC()}
+	transaction := models.Transaction{ID: uuid.New(), AccountID: account.ID, Type: utils.Deposit, Currency: "EUR", Amount: money.New(5000, 2), TimeStamp: time.Unix(1700000000, 0).UTC()}
+	err = store.Atomic(func(store storage.Storage) error {
+		if err := store.CreateAccount(account); err != nil {
+			return err
+		}
+		return store.CreateTransaction(transaction)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	account.Status = utils.Frozen
+	store.UpdateAccount(account)
+
+	// A unit of work that fails leaves nothing in the journal
+	store.Atomic(func(store storage.Storage) error {
+		store.CreateAccount(models.Account{ID: uuid.New(), Owner: "Bob"})
+		return errors.New("failed")
+	})
+	store.Close()
+
+	// Reopen the journal and validate the replayed data
+	store, err = storage.CreateJournaled(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	accounts, _ := store.FindAccounts()
+	if len(accounts) != 1 || accounts[0] != account {
+		t.Errorf("Expected account %+v, got %+v", account, accounts)
+	}
+	transactions, _ := store.FindTransactions(account.ID)
+	if len(transactions) != 1 || transactions[0].ID != transaction.ID || transactions[0].Amount != transaction.Amount {
+		t.Errorf("Expected transaction %+v, got %+v", transaction, transactions)
+	}
+}
+
+func TestJournalTruncatesCorruptedTail(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "bank.journal")
+	store, err := storage.CreateJournaled(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first := models.Customer{ID: uuid.New(), Name: "Alice"}
+	second := models.Customer{ID: uuid.New(), Name: "Bob"}
+	store.CreateCustomer(first)
+	info, _ := os.Stat(path)
+	intact := info.Size()
+	store.CreateCustomer(second)
+	store.Close()
+
+	// Flip a byte of the last record as a crash mid-write would leave it
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path, data, 0o644)
+
+	// The corrupted record is dropped and cut off the journal
+	store, err = storage.CreateJournaled(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	customers, _ := store.FindCustomers()
+	if len(customers) != 1 || customers[0].ID != first.ID {
+		t.Errorf("Expected only customer %s, got %+v", first.ID, customers)
+	}
+	info, _ = os.Stat(path)
+	if info.Size() != intact {
+		t.Errorf("Expected the journal truncated to %d bytes, got %d", intact, info.Size())
+	}
+
+	// New records follow the last intact one and survive another reopen
+	store.CreateCustomer(second)
+	store.Close()
+	store, err = storage.CreateJournaled(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	customers, _ = store.FindCustomers()
+	if len(customers) != 2 || customers[1].ID != second.ID {
+		t.Errorf("Expected customers %s and %s, got %+v", first.ID, second.ID, customers)
+	}
+}
+
+func TestJournalCompactsIntoSnapshot(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "bank.journal")
+	store, err := storage.CreateJournaled(path, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The third record triggers a snapshot and empties the journal
+	for _, name := range []string{"Alice", "Bob", "Charlie", "Dave"} {
+		if err := store.CreateCustomer(models.Customer{ID: uuid.New(), Name: name}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	store.Close()
+	if _, err := os.Stat(path + ".snapshot"); err != nil {
+		t.Fatalf("Expected a snapshot, got %v", err)
+	}
+	info, _ := os.Stat(path)
+	if info.Size() == 0 {
+		t.Errorf("Expected the fourth record in the journal, got an empty journal")
+	}
+
+	// The snapshot and the journal together hold every customer in order
+	store, err = storage.CreateJournaled(path, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	customers, _ := store.FindCustomers()
+	if len(customers) != 4 || customers[0].Name != "Alice" || customers[3].Name != "Dave" {
+		t.Errorf("Expected four customers from Alice to Dave, got %+v", customers)
+	}
+
+	// Customers restored from the snapshot can be looked up by ID
+	for _, customer := range customers {
+		if found, err := store.FindCustomer(customer.ID); err != nil || found != customer {
+			t.Errorf("Expected customer %+v, got %+v and %v", customer, found, err)
+		}
+	}
+}
+
+func TestJournalKeepsEventSequences(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "bank.journal")
+	store, err := storage.CreateJournaled(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first, second := uuid.New(), uuid.New()
+
+	// Number an event in a unit of work that commits after a unit numbering
+	// the next one, and roll back a third
+	appended, committed := make(chan struct{}), make(chan struct{})
+	go func() {
+		<-appended
+		store.Lock([]uuid.UUID{second}, func(tx storage.Storage) error {
+			return tx.AppendEvent(models.Event{AccountID: second, Type: utils.EventAccountOpened})
+		})
+		store.Lock([]uuid.UUID{second}, func(tx storage.Storage) error {
+			tx.AppendEvent(models.Event{AccountID: second, Type: utils.EventFundsDeposited})
+			return errors.New("failed")
+		})
+		close(committed)
+	}()
+	err = store.Lock([]uuid.UUID{first}, func(tx storage.Storage) error {
+		if err := tx.AppendEvent(models.Event{AccountID: first, Type: utils.EventAccountOpened}); err != nil {
+			return err
+		}
+		close(appended)
+		<-committed
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	store.AppendEvent(models.Event{AccountID: first, Type: utils.EventFundsDeposited})
+	store.Close()
+
+	// Reopen the journal and validate that every event kept its number
+	store, err = storage.CreateJournaled(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	events, _ := store.FindEvents(0, 10)
+	expected := []struct {
+		accountID uuid.UUID
+		sequence  int64
+	}{{first, 1}, {second, 2}, {first, 4}}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for index, event := range events {
+		if event.AccountID != expected[index].accountID || event.Sequence != expected[index].sequence {
+			t.Errorf("Expected event %d of %s, got %d of %s", expected[index].sequence, expected[index].accountID, event.Sequence, event.AccountID)
+		}
+	}
+	accountEvents, _ := store.FindAccountEvents(models.EventFilter{AccountID: first, After: 1})
+	if len(accountEvents) != 1 || accountEvents[0].Sequence != 4 {
+		t.Errorf("Expected event 4 after 1, got %+v", accountEvents)
+	}
+}
@@ -0,0 +1,542 @@
+package test
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAtomicCommit(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account := models.Account{ID: uuid.New(), Owner: "Alice", Balance: money.New(10000, 2)}
+
+	// Create and update an account in one unit of work
+	err := store.Atomic(func(tx storage.Storage) error {
+		if err := tx.CreateAccount(account); err != nil {
+			return err
+		}
+		account.Balance = money.New(15000, 2)
+		return tx.UpdateAccount(account)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Validate the committed state
+	stored, err := store.FindAccount(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.Balance.String() != "150.00" {
+		t.Errorf("Expected balance 150.00, got %s", stored.Balance)
+	}
+}
+
+func TestAtomicRollback(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account := models.Account{ID: uuid.New(), Owner: "Bob", Balance: money.New(10000, 2)}
+	store.CreateAccount(account)
+	failure := errors.New("failure")
+
+	// Change the account and add records, then fail the unit of work
+	err := store.Atomic(func(tx storage.Storage) error {
+		updated := account
+		updated.Balance = money.Zero(2)
+		tx.UpdateAccount(updated)
+		tx.CreateAccount(models.Account{ID: uuid.New(), Owner: "Charlie"})
+		tx.CreateTransaction(models.Transaction{ID: uuid.New(), AccountID: account.ID, Amount: money.New(10000, 2)})
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("Expected error %v, got %v", failure, err)
+	}
+
+	// Validate that nothing was committed
+	stored, _ := store.FindAccount(account.ID)
+	if stored.Balance.String() != "100.00" {
+		t.Errorf("Expected balance 100.00, got %s", stored.Balance)
+	}
+	accounts, _ := store.FindAccounts()
+	if len(accounts) != 1 {
+		t.Errorf("Expected 1 account, got %d", len(accounts))
+	}
+	transactions, _ := store.FindTransactions(account.ID)
+	if len(transactions) != 0 {
+		t.Errorf("Expected 0 transactions, got %d", len(transactions))
+	}
+}
+
+func TestFindAccountsReturnsCopy(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	account := models.Account{ID: uuid.New(), Owner: "Dave", Balance: money.New(10000, 2)}
+	store.CreateAccount(account)
+
+	// Modify the returned slice
+	accounts, _ := store.FindAccounts()
+	accounts[0].Balance = money.Zero(2)
+
+	// Validate that the store is unaffected
+	stored, _ := store.FindAccount(account.ID)
+	if stored.Balance.String() != "100.00" {
+		t.Errorf("Expected balance 100.00, got %s", stored.Balance)
+	}
+}
+
+func TestOpenUnknownDriver(t *testing.T) {
+	// Attempt to open an unsupported backend
+	_, err := storage.Open(storage.Config{Driver: "unknown"})
+	if !errors.Is(err, utils.ErrUnknownStorageDriver) {
+		t.Fatalf("Expected error %v, got %v", utils.ErrUnknownStorageDriver, err)
+	}
+}
+
+func TestIndexedLookups(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	alice := models.Customer{ID: uuid.New(), Name: "Alice"}
+	bob := models.Customer{ID: uuid.New(), Name: "Bob"}
+	charlie := models.Customer{ID: uuid.New(), Name: "Charlie"}
+	for _, customer := range []models.Customer{alice, bob, charlie} {
+		store.CreateCustomer(customer)
+	}
+	first := models.Account{ID: uuid.New(), CustomerID: alice.ID, Owner: "Alice"}
+	second := models.Account{ID: uuid.New(), CustomerID: bob.ID, Owner: "Bob"}
+	store.CreateAccount(first)
+	store.CreateAccount(second)
+
+	// Customers after a deleted one are still found
+	if err := store.DeleteCustomer(alice.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.FindCustomer(alice.ID); err != utils.ErrCustomerNotFound {
+		t.Errorf("Expected ErrCustomerNotFound, got %v", err)
+	}
+	if found, err := store.FindCustomer(charlie.ID); err != nil || found != charlie {
+		t.Errorf("Expected customer %+v, got %+v and %v", charlie, found, err)
+	}
+
+	// An account moved to another customer is listed under it in creation order
+	first.CustomerID = bob.ID
+	store.UpdateAccount(first)
+	accounts, _ := store.FindCustomerAccounts(bob.ID)
+	if len(accounts) != 2 || accounts[0].ID != first.ID || accounts[1].ID != second.ID {
+		t.Errorf("Expected accounts %s and %s, got %+v", first.ID, second.ID, accounts)
+	}
+
+	// A failed unit of work leaves every index as it was
+	failure := errors.New("failure")
+	store.Atomic(func(tx storage.Storage) error {
+		moved := second
+		moved.CustomerID = charlie.ID
+		tx.UpdateAccount(moved)
+		tx.CreateTransaction(models.Transaction{ID: uuid.New(), AccountID: second.ID, RelatedID: uuid.New()})
+		tx.DeleteCustomer(bob.ID)
+		return failure
+	})
+	accounts, _ = store.FindCustomerAccounts(charlie.ID)
+	if len(accounts) != 0 {
+		t.Errorf("Expected no accounts of %s, got %+v", charlie.ID, accounts)
+	}
+	accounts, _ = store.FindCustomerAccounts(bob.ID)
+	if len(accounts) != 2 {
+		t.Errorf("Expected 2 accounts of %s, got %+v", bob.ID, accounts)
+	}
+	transactions, _ := store.FindTransactions(second.ID)
+	if len(transactions) != 0 {
+		t.Errorf("Expected 0 transactions, got %d", len(transactions))
+	}
+	if _, err := store.FindCustomer(bob.ID); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// waitFor reports whether done is closed within a second
+func waitFor(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestLockIsolatesAccounts(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	first := models.Account{ID: uuid.New(), Owner: "Alice", Balance: money.New(10000, 2)}
+	second := models.Account{ID: uuid.New(), Owner: "Bob", Balance: money.New(10000, 2)}
+	store.CreateAccount(first)
+	store.CreateAccount(second)
+
+	// Hold a unit of work on the first account open
+	locked, release, finished := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(finished)
+		store.Lock([]uuid.UUID{first.ID}, func(tx storage.Storage) error {
+			updated := first
+			updated.Balance = money.New(5000, 2)
+			tx.UpdateAccount(updated)
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+
+	// A unit of work on the second account runs meanwhile
+	changed := make(chan struct{})
+	go func() {
+		defer close(changed)
+		store.Lock([]uuid.UUID{second.ID}, func(tx storage.Storage) error {
+			updated := second
+			updated.Balance = money.New(20000, 2)
+			return tx.UpdateAccount(updated)
+		})
+	}()
+	if !waitFor(changed) {
+		t.Fatal("Expected the unit of work on another account to finish")
+	}
+
+	// Views of the first account and of the whole store wait for the unit
+	viewed, listed := make(chan models.Account, 1), make(chan []models.Account, 1)
+	go func() {
+		account, _ := store.FindAccount(first.ID)
+		viewed <- account
+	}()
+	go func() {
+		accounts, _ := store.FindAccounts()
+		listed <- accounts
+	}()
+	select {
+	case <-viewed:
+		t.Fatal("Expected the view of a locked account to wait")
+	case <-listed:
+		t.Fatal("Expected the view of the whole store to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Once committed, the views see the change
+	close(release)
+	<-finished
+	if account := <-viewed; account.Balance.String() != "50.00" {
+		t.Errorf("Expected balance 50.00, got %s", account.Balance)
+	}
+	if accounts := <-listed; len(accounts) != 2 || accounts[1].Balance.String() != "200.00" {
+		t.Errorf("Expected 2 accounts with the second at 200.00, got %+v", accounts)
+	}
+}
+
+func TestFindByIDWaitsOnlyForOwners(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	first := models.Account{ID: uuid.New(), Owner: "Alice"}
+	second := models.Account{ID: uuid.New(), Owner: "Bob"}
+	third := models.Account{ID: uuid.New(), Owner: "Carol"}
+	for _, account := range []models.Account{first, second, third} {
+		store.CreateAccount(account)
+	}
+
+	// Add a transaction to the first account, and a transaction with a fee,
+	// a hold and a transfer to the third account to the second one
+	locked := models.Transaction{ID: uuid.New(), AccountID: first.ID, Amount: money.New(100, 2)}
+	free := models.Transaction{ID: uuid.New(), AccountID: second.ID, Amount: money.New(100, 2)}
+	fee := models.Transaction{ID: uuid.New(), AccountID: second.ID, Type: utils.Fee, RelatedID: free.ID, Amount: money.New(10, 2)}
+	hold := models.Hold{ID: uuid.New(), AccountID: second.ID, Amount: money.New(100, 2)}
+	transfer := models.Transfer{ID: uuid.New(), FromAccountID: second.ID, ToAccountID: third.ID, Amount: money.New(100, 2)}
+	leg := models.Transaction{ID: uuid.New(), AccountID: third.ID, TransferID: transfer.ID, Amount: money.New(100, 2)}
+	for _, transaction := range []models.Transaction{locked, free, fee, leg} {
+		store.CreateTransaction(transaction)
+	}
+	store.CreateHold(hold)
+	store.CreateTransfer(transfer)
+
+	// Hold a unit of work on the first account open
+	inside, release, finished := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(finished)
+		store.Lock([]uuid.UUID{first.ID}, func(tx storage.Storage) error {
+			close(inside)
+			<-release
+			return nil
+		})
+	}()
+	<-inside
+
+	// Records of the other accounts are found by ID meanwhile
+	found := make(chan struct{})
+	go func() {
+		defer close(found)
+		if transaction, err := store.FindTransaction(free.ID); err != nil || transaction.ID != free.ID {
+			t.Errorf("Expected transaction %s, got %+v and %v", free.ID, transaction, err)
+		}
+		if related, err := store.FindRelatedTransactions(free.ID); err != nil || len(related) != 1 {
+			t.Errorf("Expected 1 related transaction, got %+v and %v", related, err)
+		}
+		if found, err := store.FindHold(hold.ID); err != nil || found.ID != hold.ID {
+			t.Errorf("Expected hold %s, got %+v and %v", hold.ID, found, err)
+		}
+		if found, err := store.FindTransfer(transfer.ID); err != nil || found.ID != transfer.ID {
+			t.Errorf("Expected transfer %s, got %+v and %v", transfer.ID, found, err)
+		}
+		if legs, err := store.FindTransferTransactions(transfer.ID); err != nil || len(legs) != 1 {
+			t.Errorf("Expected 1 transfer transaction, got %+v and %v", legs, err)
+		}
+		if _, err := store.FindTransaction(uuid.New()); err != utils.ErrTransactionNotFound {
+			t.Errorf("Expected error %v, got %v", utils.ErrTransactionNotFound, err)
+		}
+	}()
+	if !waitFor(found) {
+		t.Fatal("Expected the records of other accounts to be found")
+	}
+
+	// A transaction of the first account waits for the unit
+	viewed := make(chan struct{})
+	go func() {
+		defer close(viewed)
+		store.FindTransaction(locked.ID)
+	}()
+	select {
+	case <-viewed:
+		t.Fatal("Expected the view of a transaction of a locked account to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-finished
+	if !waitFor(viewed) {
+		t.Fatal("Expected the transaction to be found once the unit committed")
+	}
+}
+
+func TestExpiredIdempotencySweepWaitsForKeys(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	for _, key := range []string{"claimed", "idle"} {
+		store.SaveIdempotencyRecord(models.IdempotencyRecord{Key: key, ExpiresAt: now})
+	}
+
+	// Hold a unit of work on the first key open, which claims it again
+	inside, release, finished := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(finished)
+		store.Lock([]uuid.UUID{storage.KeyLockID("claimed")}, func(tx storage.Storage) error {
+			close(inside)
+			<-release
+			return tx.SaveIdempotencyRecord(models.IdempotencyRecord{Key: "claimed", ExpiresAt: now.Add(time.Hour)})
+		})
+	}()
+	<-inside
+
+	// The sweep waits for the unit and keeps the key it claimed
+	swept := make(chan struct{})
+	go func() {
+		defer close(swept)
+		if err := store.DeleteExpiredIdempotencyRecords(now); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}()
+	select {
+	case <-swept:
+		t.Fatal("Expected the sweep to wait for the unit on the key")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-finished
+	if !waitFor(swept) {
+		t.Fatal("Expected the sweep to finish once the unit committed")
+	}
+	if _, err := store.FindIdempotencyRecord("claimed"); err != nil {
+		t.Errorf("Expected the claimed key to be kept, got %v", err)
+	}
+	if _, err := store.FindIdempotencyRecord("idle"); err != utils.ErrIdempotencyKeyNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrIdempotencyKeyNotFound, err)
+	}
+}
+
+func TestLockRunsUnitsConcurrently(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	const units = 8
+
+	// Every unit of work on its own account waits inside its lock until all
+	// of them are inside theirs, which only finishes if they overlap
+	var inside sync.WaitGroup
+	inside.Add(units)
+	all := make(chan struct{})
+	go func() {
+		inside.Wait()
+		close(all)
+	}()
+	results := make(chan error, units)
+	for unit := 0; unit < units; unit++ {
+		go func() {
+			account := models.Account{ID: uuid.New(), Owner: "Owner"}
+			results <- store.Lock([]uuid.UUID{account.ID}, func(tx storage.Storage) error {
+				if err := tx.CreateAccount(account); err != nil {
+					return err
+				}
+				inside.Done()
+				if !waitFor(all) {
+					return errors.New("units of work did not overlap")
+				}
+				return nil
+			})
+		}()
+	}
+
+	// Validate that every unit committed
+	for unit := 0; unit < units; unit++ {
+		if err := <-results; err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	accounts, _ := store.FindAccounts()
+	if len(accounts) != units {
+		t.Errorf("Expected %d accounts, got %d", units, len(accounts))
+	}
+}
+
+func TestLockRollbackKeepsConcurrentWork(t *testing.T) {
+	// Setup
+	store := storage.Create()
+	first := models.Account{ID: uuid.New(), Owner: "Alice"}
+	second := models.Account{ID: uuid.New(), Owner: "Bob"}
+	store.CreateAccount(first)
+	store.CreateAccount(second)
+
+	// Add records in a unit of work that fails after another unit added
+	// records behind them and committed
+	failure := errors.New("failure")
+	added, committed := make(chan struct{}), make(chan struct{})
+	kept := models.Transaction{ID: uuid.New(), AccountID: second.ID, Amount: money.New(100, 2)}
+	go func() {
+		<-added
+		store.Lock([]uuid.UUID{second.ID}, func(tx storage.Storage) error {
+			if err := tx.CreateTransaction(kept); err != nil {
+				return err
+			}
+			return tx.AppendEvent(models.Event{AccountID: second.ID, Type: utils.EventFundsDeposited})
+		})
+		close(committed)
+	}()
+	err := store.Lock([]uuid.UUID{first.ID}, func(tx storage.Storage) error {
+		tx.CreateTransaction(models.Transaction{ID: uuid.New(), AccountID: first.ID, Amount: money.New(100, 2)})
+		tx.AppendEvent(models.Event{AccountID: first.ID, Type: utils.EventFundsDeposited})
+		close(added)
+		<-committed
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("Expected error %v, got %v", failure, err)
+	}
+
+	// Validate that only the failed work is gone
+	if transactions, _ := store.FindTransactions(first.ID); len(transactions) != 0 {
+		t.Errorf("Expected 0 transactions, got %d", len(transactions))
+	}
+	if found, err := store.FindTransaction(kept.ID); err != nil || found.ID != kept.ID {
+		t.Errorf("Expected transaction %s, got %+v and %v", kept.ID, found, err)
+	}
+	if transactions, _ := store.FindTransactions(second.ID); len(transactions) != 1 {
+		t.Errorf("Expected 1 transaction, got %d", len(transactions))
+	}
+	events, _ := store.FindEvents(0, 10)
+	if len(events) != 1 || events[0].AccountID != second.ID || events[0].Sequence != 2 {
+		t.Errorf("Expected the event of %s numbered 2, got %+v", second.ID, events)
+	}
+}
+
+// benchmarkSizes are the numbers of accounts the benchmarks are run with,
+// each account having benchmarkTransactions transactions
+var benchmarkSizes = []int{10_000, 100_000, 1_000_000}
+
+const benchmarkTransactions = 10
+
+// populate fills a store with accounts and their transactions in units of
+// work of a few thousand records, returning the account IDs and the ID of
+// the last transaction of every account
+func populate(b *testing.B, size int) (*storage.Memory, []uuid.UUID, []uuid.UUID) {
+	store := storage.Create()
+	accountIDs := make([]uuid.UUID, size)
+	transactionIDs := make([]uuid.UUID, size)
+	timestamp := time.Unix(1700000000, 0)
+	for start := 0; start < size; start += 1000 {
+		err := store.Atomic(func(tx storage.Storage) error {
+			for index := start; index < min(start+1000, size); index++ {
+				accountIDs[index] = uuid.New()
+				if err := tx.CreateAccount(models.Account{ID: accountIDs[index], Owner: "Owner", Currency: "USD", Balance: money.New(0, 2)}); err != nil {
+					return err
+				}
+				for count := 0; count < benchmarkTransactions; count++ {
+					transaction := models.Transaction{ID: uuid.New(), AccountID: accountIDs[index], Type: utils.Deposit, Currency: "USD", Amount: money.New(100, 2), TimeStamp: timestamp}
+					if err := tx.CreateTransaction(transaction); err != nil {
+						return err
+					}
+					transactionIDs[index] = transaction.ID
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	return store, accountIDs, transactionIDs
+}
+
+// BenchmarkMemoryLookups measures finding accounts and transactions and
+// listing the transactions of an account as the store grows. The cost per
+// operation should stay flat across sizes
+func BenchmarkMemoryLookups(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("accounts=%d/transactions=%d", size, size*benchmarkTransactions), func(b *testing.B) {
+			if testing.Short() && size > 100_000 {
+				b.Skip("Skipping the largest store in short mode")
+			}
+			store, accountIDs, transactionIDs := populate(b, size)
+
+			b.Run("FindAccount", func(b *testing.B) {
+				for index := 0; index < b.N; index++ {
+					if _, err := store.FindAccount(accountIDs[index%len(accountIDs)]); err != nil {
+						b.Fatalf("Expected no error, got %v", err)
+					}
+				}
+			})
+			b.Run("FindTransaction", func(b *testing.B) {
+				for index := 0; index < b.N; index++ {
+					if _, err := store.FindTransaction(transactionIDs[index%len(transactionIDs)]); err != nil {
+						b.Fatalf("Expected no error, got %v", err)
+					}
+				}
+			})
+			b.Run("FindTransactions", func(b *testing.B) {
+				for index := 0; index < b.N; index++ {
+					transactions, _ := store.FindTransactions(accountIDs[index%len(accountIDs)])
+					if len(transactions) != benchmarkTransactions {
+						b.Fatalf("Expected %d transactions, got %d", benchmarkTransactions, len(transactions))
+					}
+				}
+			})
+			b.Run("SearchTransactions", func(b *testing.B) {
+				for index := 0; index < b.N; index++ {
+					filter := models.TransactionFilter{AccountID: accountIDs[index%len(accountIDs)], Limit: 5}
+					transactions, _ := store.SearchTransactions(filter)
+					if len(transactions) != 5 {
+						b.Fatalf("Expected 5 transactions, got %d", len(transactions))
+					}
+				}
+			})
+		})
+	}
+}
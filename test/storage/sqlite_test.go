@@ -0,0 +1,768 @@
+package test
+
+import (
+	"bank-account-manager/models"
+	"bank-account-manager/money"
+	"bank-account-manager/storage"
+	"bank-account-manager/utils"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLitePersistsAcrossReopen(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "bank.db")
+	store, err := storage.CreateSQLite(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Store an account with a transaction
+	account := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "EUR", Balance: money.New(10000, 2), Overdraft: money.New(5000, 2), Held: money.New(0, 2), Type: utils.TermDeposit, MaturesAt: time.Unix(1900000000, 0)}
+	transaction := models.Transaction{
+		ID:        uuid.New(),
+		AccountID: account.ID,
+		Type:      utils.Deposit,
+		Currency:  "EUR",
+		Amount:    money.New(5000, 2),
+		Exchange: &models.Exchange{
+			Rate:                money.Rate{Units: 92, Scale: 2},
+			SourceCurrency:      "USD",
+			SourceAmount:        money.New(5435, 2),
+			DestinationCurrency: "EUR",
+			DestinationAmount:   money.New(5000, 2),
+		},
+		TimeStamp: time.Now(),
+	}
+	store.CreateAccount(account)
+	store.CreateTransaction(transaction)
+	store.Close()
+
+	// Reopen the database, which must not reapply migrations
+	store, err = storage.CreateSQLite(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	// Validate the stored data
+	stored, err := store.FindAccount(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored != account {
+		t.Errorf("Expected account %+v, got %+v", account, stored)
+	}
+	transactions, _ := store.FindTransactions(account.ID)
+	if len(transactions) != 1 {
+		t.Fatalf("Expected 1 transaction, got %d", len(transactions))
+	}
+	if transactions[0].Type != utils.Deposit || transactions[0].Currency != "EUR" || !transactions[0].TimeStamp.Equal(transaction.TimeStamp) {
+		t.Errorf("Expected transaction %+v, got %+v", transaction, transactions[0])
+	}
+	if transactions[0].Exchange == nil || *transactions[0].Exchange != *transaction.Exchange {
+		t.Errorf("Expected transaction %+v, got %+v", transaction, transactions[0])
+	}
+}
+
+func TestSQLiteAtomicRollback(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	account := models.Account{ID: uuid.New(), Owner: "Bob", Balance: money.New(10000, 2)}
+	store.CreateAccount(account)
+	failure := errors.New("failure")
+
+	// Change the account, then fail the unit of work
+	err = store.Atomic(func(tx storage.Storage) error {
+		updated := account
+		updated.Balance = money.Zero(2)
+		tx.UpdateAccount(updated)
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("Expected error %v, got %v", failure, err)
+	}
+
+	// Validate that nothing was committed
+	stored, _ := store.FindAccount(account.ID)
+	if stored.Balance.String() != "100.00" {
+		t.Errorf("Expected balance 100.00, got %s", stored.Balance)
+	}
+}
+
+func TestSQLiteAccountNotFound(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	// Attempt to read and update a non-existent account
+	if _, err := store.FindAccount(uuid.New()); err != utils.ErrAccountNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountNotFound, err)
+	}
+	if err := store.UpdateAccount(models.Account{ID: uuid.New()}); err != utils.ErrAccountNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccountNotFound, err)
+	}
+}
+
+func TestSQLiteMigratesFloatAmounts(t *testing.T) {
+	// Setup a database at schema version 1 with floating point amounts
+	path := filepath.Join(t.TempDir(), "bank.db")
+	database, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	id := uuid.New()
+	_, err = database.Exec(`
+		CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at INTEGER NOT NULL);
+		INSERT INTO schema_migrations VALUES (1, 0);
+		CREATE TABLE accounts (id TEXT PRIMARY KEY, owner TEXT NOT NULL, balance REAL NOT NULL);
+		CREATE TABLE transactions (
+			id TEXT PRIMARY KEY, account_id TEXT NOT NULL REFERENCES accounts (id),
+			type TEXT NOT NULL, amount REAL NOT NULL, timestamp INTEGER NOT NULL
+		);
+		CREATE INDEX transactions_account_id ON transactions (account_id);
+		INSERT INTO accounts VALUES (?, 'Alice', 100.3);`, id.String())
+	database.Close()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Open the database, which applies the remaining migrations
+	store, err := storage.CreateSQLite(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	// Validate the converted balance
+	account, err := store.FindAccount(id)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.Balance.String() != "100.30" {
+		t.Errorf("Expected balance 100.30, got %s", account.Balance)
+	}
+
+	// Validate the opening entry posted for the existing balance
+	balances, err := store.FindLedgerBalances()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(balances) != 2 {
+		t.Fatalf("Expected 2 ledger balances, got %d", len(balances))
+	}
+	if balances[0].AccountID != models.ExternalAccountID || balances[0].Debits.String() != "100.30" {
+		t.Errorf("Expected external debit of 100.30, got %+v", balances[0])
+	}
+	if balances[1].AccountID != id || balances[1].Credits.String() != "100.30" {
+		t.Errorf("Expected account credit of 100.30, got %+v", balances[1])
+	}
+
+	// Validate the opening event recorded for the existing account
+	events, err := store.FindAccountEvents(models.EventFilter{AccountID: id})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 1 || events[0].Type != utils.EventAccountOpened || events[0].Amount.String() != "100.30" {
+		t.Errorf("Expected an opening event of 100.30, got %+v", events)
+	}
+}
+
+func TestSQLiteLedgerBalances(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	// Post two entries against the same account
+	accountID := uuid.New()
+	for _, amount := range []int64{1500, 2500} {
+		err := store.CreateJournalEntry(models.JournalEntry{
+			ID:          uuid.New(),
+			Description: "deposit",
+			Postings: []models.Posting{
+				{AccountID: models.ExternalAccountID, Side: utils.Debit, Currency: "USD", Amount: money.New(amount, 2)},
+				{AccountID: accountID, TransactionID: uuid.New(), Side: utils.Credit, Currency: "USD", Amount: money.New(amount, 2)},
+			},
+			TimeStamp: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Validate the totals per ledger account
+	balances, err := store.FindLedgerBalances()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []models.LedgerBalance{
+		{AccountID: models.ExternalAccountID, Currency: "USD", Debits: money.New(4000, 2), Credits: money.New(0, 2)},
+		{AccountID: accountID, Currency: "USD", Debits: money.New(0, 2), Credits: money.New(4000, 2)},
+	}
+	if len(balances) != len(expected) {
+		t.Fatalf("Expected %d ledger balances, got %d", len(expected), len(balances))
+	}
+	for index := range expected {
+		if balances[index] != expected[index] {
+			t.Errorf("Expected ledger balance %+v, got %+v", expected[index], balances[index])
+		}
+	}
+}
+
+func TestSQLiteSearchTransactions(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	// Store deposits and withdrawals one second apart
+	account := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "USD", Balance: money.New(0, 2)}
+	store.CreateAccount(account)
+	start := time.Unix(1700000000, 0)
+	for index := 0; index < 6; index++ {
+		transactionType := utils.Deposit
+		if index%2 == 1 {
+			transactionType = utils.Withdrawal
+		}
+		store.CreateTransaction(models.Transaction{
+			ID:        uuid.New(),
+			AccountID: account.ID,
+			Type:      transactionType,
+			Currency:  "USD",
+			Amount:    money.New(int64(index+1)*100, 2),
+			TimeStamp: start.Add(time.Duration(index) * time.Second),
+		})
+	}
+
+	// Search deposits within the first four seconds, after the first one
+	deposit := utils.Deposit
+	first, _ := store.SearchTransactions(models.TransactionFilter{AccountID: account.ID, Type: &deposit, Limit: 1})
+	transactions, err := store.SearchTransactions(models.TransactionFilter{
+		AccountID: account.ID,
+		Type:      &deposit,
+		To:        start.Add(4 * time.Second),
+		After:     &models.Cursor{Value: first[0].TimeStamp.UnixNano(), ID: first[0].ID},
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Validate only the third deposit is left
+	if len(transactions) != 1 || transactions[0].Amount.String() != "3.00" {
+		t.Errorf("Expected the deposit of 3.00, got %+v", transactions)
+	}
+}
+
+func TestSQLiteSearchAccounts(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	// Store accounts in currencies with different minor units
+	start := time.Unix(1700000000, 0)
+	accounts := []models.Account{
+		{ID: uuid.New(), Owner: "Alice", Currency: "USD", Balance: money.New(15050, 2), CreatedAt: start},
+		{ID: uuid.New(), Owner: "100%_Bob", Currency: "JPY", Balance: money.New(150, 0), CreatedAt: start.Add(time.Second)},
+		{ID: uuid.New(), Owner: "Carol", Currency: "KWD", Balance: money.New(150100, 3), Status: utils.Frozen, CreatedAt: start.Add(2 * time.Second)},
+		{ID: uuid.New(), Owner: "Dave", Currency: "USD", Balance: money.New(15000, 2), CreatedAt: start.Add(3 * time.Second)},
+		{ID: uuid.New(), Owner: "Erin", Currency: "USD", Balance: money.New(20000, 2), CreatedAt: start.Add(4 * time.Second)},
+	}
+	for _, account := range accounts {
+		if err := store.CreateAccount(account); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Search by balance range within one currency, largest first
+	minBalance, maxBalance := money.New(15000, 2), money.New(15050, 2)
+	found, err := store.SearchAccounts(models.AccountFilter{
+		Currency:   "USD",
+		MinBalance: &minBalance,
+		MaxBalance: &maxBalance,
+		SortBy:     models.AccountSortBalance,
+		Descending: true,
+		Limit:      10,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(found) != 2 || found[0].Owner != "Alice" || found[1].Owner != "Dave" {
+		t.Errorf("Expected Alice and Dave, got %+v", found)
+	}
+
+	// Search owners with LIKE wildcards taken literally and by status
+	found, _ = store.SearchAccounts(models.AccountFilter{Owner: "%_b", Limit: 10})
+	if len(found) != 1 || found[0].ID != accounts[1].ID {
+		t.Errorf("Expected only 100%%_Bob, got %+v", found)
+	}
+	found, _ = store.SearchAccounts(models.AccountFilter{Statuses: []utils.AccountStatus{utils.Frozen}, Limit: 10})
+	if len(found) != 1 || found[0].ID != accounts[2].ID {
+		t.Errorf("Expected only Carol, got %+v", found)
+	}
+
+	// Continue after the first account by creation time
+	found, _ = store.SearchAccounts(models.AccountFilter{
+		SortBy: models.AccountSortCreated,
+		After:  &models.Cursor{Value: start.UnixNano(), ID: accounts[0].ID},
+		Limit:  1,
+	})
+	if len(found) != 1 || found[0].ID != accounts[1].ID {
+		t.Errorf("Expected 100%%_Bob after Alice, got %+v", found)
+	}
+}
+
+func TestSQLiteCustomers(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	// Store a customer holding one of two accounts
+	customer := models.Customer{ID: uuid.New(), Name: "Alice", Email: "alice@example.com", CreatedAt: time.Unix(1700000000, 0)}
+	if err := store.CreateCustomer(customer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	held := models.Account{ID: uuid.New(), CustomerID: customer.ID, Owner: "Alice", Currency: "USD", Balance: money.New(100, 2), Overdraft: money.New(0, 2), Held: money.New(0, 2), Status: utils.Active}
+	other := models.Account{ID: uuid.New(), Owner: "Bob", Currency: "USD", Balance: money.New(100, 2), Status: utils.Active}
+	store.CreateAccount(held)
+	store.CreateAccount(other)
+
+	// Validate the stored data
+	stored, err := store.FindCustomer(customer.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored != customer {
+		t.Errorf("Expected customer %+v, got %+v", customer, stored)
+	}
+	accounts, _ := store.FindCustomerAccounts(customer.ID)
+	if len(accounts) != 1 || accounts[0] != held {
+		t.Errorf("Expected only account %+v, got %+v", held, accounts)
+	}
+	if account, _ := store.FindAccount(other.ID); account.CustomerID != uuid.Nil {
+		t.Errorf("Expected no customer, got %s", account.CustomerID)
+	}
+
+	// Update and delete the customer
+	customer.Name = "Alice Smith"
+	if err := store.UpdateCustomer(customer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	customers, _ := store.FindCustomers()
+	if len(customers) != 1 || customers[0].Name != "Alice Smith" {
+		t.Errorf("Expected the updated customer, got %+v", customers)
+	}
+	held.CustomerID = uuid.Nil
+	store.UpdateAccount(held)
+	if err := store.DeleteCustomer(customer.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.FindCustomer(customer.ID); err != utils.ErrCustomerNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrCustomerNotFound, err)
+	}
+	if err := store.DeleteCustomer(customer.ID); err != utils.ErrCustomerNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrCustomerNotFound, err)
+	}
+}
+
+func TestSQLiteAccountHolders(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	alice := models.Customer{ID: uuid.New(), Name: "Alice"}
+	bob := models.Customer{ID: uuid.New(), Name: "Bob"}
+	account := models.Account{ID: uuid.New(), CustomerID: alice.ID, Owner: "Alice", Currency: "USD", Balance: money.New(0, 2)}
+	store.CreateCustomer(alice)
+	store.CreateCustomer(bob)
+	store.CreateAccount(account)
+
+	// Add two holders and change the role of the first
+	owner := models.AccountHolder{AccountID: account.ID, CustomerID: alice.ID, Role: utils.Signatory}
+	signatory := models.AccountHolder{AccountID: account.ID, CustomerID: bob.ID, Role: utils.Signatory}
+	store.SaveAccountHolder(owner)
+	store.SaveAccountHolder(signatory)
+	owner.Role = utils.Owner
+	if err := store.SaveAccountHolder(owner); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Validate the stored holders in the order they were added
+	holders, err := store.FindAccountHolders(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(holders) != 2 || holders[0] != owner || holders[1] != signatory {
+		t.Errorf("Expected holders %+v and %+v, got %+v", owner, signatory, holders)
+	}
+	holdings, _ := store.FindCustomerHoldings(bob.ID)
+	if len(holdings) != 1 || holdings[0] != signatory {
+		t.Errorf("Expected holding %+v, got %+v", signatory, holdings)
+	}
+
+	// Remove a holder
+	if err := store.DeleteAccountHolder(account.ID, bob.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.DeleteAccountHolder(account.ID, bob.ID); err != utils.ErrHolderNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrHolderNotFound, err)
+	}
+}
+
+func TestSQLiteInterestAccruals(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	rate, _ := money.ParseRate("0.035")
+	account := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "USD", Balance: money.New(100000, 2), Overdraft: money.New(0, 2), Held: money.New(0, 2), InterestRate: rate, DayCount: utils.Thirty360, Type: utils.Savings}
+	if err := store.CreateAccount(account); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The rate and day count convention are stored with the account
+	found, err := store.FindAccount(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if found.InterestRate != rate || found.DayCount != utils.Thirty360 {
+		t.Errorf("Expected %s %s, got %s %s", rate, utils.Thirty360, found.InterestRate, found.DayCount)
+	}
+	if _, err := store.FindLatestInterestAccrual(account.ID); err != utils.ErrAccrualNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrAccrualNotFound, err)
+	}
+
+	// Save three days out of order and replace the second one
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	accruals := []models.InterestAccrual{}
+	for _, offset := range []int{2, 0, 1} {
+		accrual := models.InterestAccrual{AccountID: account.ID, Date: day.AddDate(0, 0, offset), Balance: account.Balance, Amount: money.New(9722222, 8)}
+		if err := store.SaveInterestAccrual(accrual); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		accruals = append(accruals, accrual)
+	}
+	accruals[2].Amount = money.New(0, 8)
+	store.SaveInterestAccrual(accruals[2])
+
+	// Validate the range in date order with an exclusive end
+	inRange, err := store.FindInterestAccruals(account.ID, day, day.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(inRange) != 2 || inRange[0] != accruals[1] || inRange[1] != accruals[2] {
+		t.Errorf("Expected %+v and %+v, got %+v", accruals[1], accruals[2], inRange)
+	}
+	latest, err := store.FindLatestInterestAccrual(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if latest != accruals[0] {
+		t.Errorf("Expected %+v, got %+v", accruals[0], latest)
+	}
+}
+
+func TestSQLiteRelatedTransactions(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	account := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "USD", Balance: money.New(0, 2)}
+	store.CreateAccount(account)
+
+	// Store a withdrawal and the fee charged for it
+	timestamp := time.Unix(1700000000, 0)
+	withdrawal := models.Transaction{ID: uuid.New(), AccountID: account.ID, Type: utils.Withdrawal, Currency: "USD", Amount: money.New(1000, 2), TimeStamp: timestamp}
+	fee := models.Transaction{ID: uuid.New(), AccountID: account.ID, Type: utils.Fee, Currency: "USD", Amount: money.New(50, 2), RelatedID: withdrawal.ID, TimeStamp: timestamp}
+	for _, transaction := range []models.Transaction{withdrawal, fee} {
+		if err := store.CreateTransaction(transaction); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Validate only the fee links to another transaction
+	transactions, err := store.FindTransactions(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(transactions) != 2 || transactions[0].RelatedID != uuid.Nil || transactions[1].RelatedID != withdrawal.ID {
+		t.Errorf("Expected the fee linked to %s, got %+v", withdrawal.ID, transactions)
+	}
+
+	// Validate the lookups by ID and by link
+	found, err := store.FindTransaction(fee.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if found.ID != fee.ID || found.RelatedID != withdrawal.ID {
+		t.Errorf("Expected %+v, got %+v", fee, found)
+	}
+	related, err := store.FindRelatedTransactions(withdrawal.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(related) != 1 || related[0].ID != fee.ID {
+		t.Errorf("Expected only the fee, got %+v", related)
+	}
+	if _, err := store.FindTransaction(uuid.New()); err != utils.ErrTransactionNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrTransactionNotFound, err)
+	}
+}
+
+func TestSQLiteHolds(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	account := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "USD", Balance: money.New(10000, 2), Overdraft: money.New(0, 2), Held: money.New(7000, 2)}
+	if err := store.CreateAccount(account); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The held amount is stored with the account
+	found, err := store.FindAccount(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	available, _ := found.Available()
+	if found.Held.String() != "70.00" || available.String() != "30.00" {
+		t.Errorf("Expected 70.00 held and 30.00 available, got %s and %s", found.Held, available)
+	}
+
+	// Store two holds and capture the first one
+	createdAt := time.Unix(1700000000, 0)
+	first := models.Hold{ID: uuid.New(), AccountID: account.ID, Amount: money.New(5000, 2), Captured: money.New(0, 2), Status: utils.HoldPending, CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour)}
+	second := models.Hold{ID: uuid.New(), AccountID: account.ID, Amount: money.New(2000, 2), Captured: money.New(0, 2), Status: utils.HoldPending, CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour)}
+	for _, hold := range []models.Hold{first, second} {
+		if err := store.CreateHold(hold); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	withdrawal := models.Transaction{ID: uuid.New(), AccountID: account.ID, Type: utils.Withdrawal, Currency: "USD", Amount: money.New(4500, 2), TimeStamp: createdAt}
+	if err := store.CreateTransaction(withdrawal); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first.Status = utils.HoldCaptured
+	first.Captured = withdrawal.Amount
+	first.TransactionID = withdrawal.ID
+	if err := store.UpdateHold(first); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Validate the holds in creation order
+	holds, err := store.FindAccountHolds(account.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(holds) != 2 || holds[0].ID != first.ID || holds[1].ID != second.ID {
+		t.Fatalf("Expected holds %s and %s, got %+v", first.ID, second.ID, holds)
+	}
+	if holds[0].Status != utils.HoldCaptured || holds[0].Captured.String() != "45.00" || holds[0].TransactionID != first.TransactionID {
+		t.Errorf("Expected %+v, got %+v", first, holds[0])
+	}
+	if holds[1].TransactionID != uuid.Nil || !holds[1].ExpiresAt.Equal(second.ExpiresAt) {
+		t.Errorf("Expected %+v, got %+v", second, holds[1])
+	}
+
+	// Missing holds cannot be found or updated
+	if _, err := store.FindHold(uuid.New()); err != utils.ErrHoldNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrHoldNotFound, err)
+	}
+	if err := store.UpdateHold(models.Hold{ID: uuid.New()}); err != utils.ErrHoldNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrHoldNotFound, err)
+	}
+}
+
+func TestSQLiteTransfers(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	from := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "USD", Balance: money.New(0, 2)}
+	to := models.Account{ID: uuid.New(), Owner: "Bob", Currency: "USD", Balance: money.New(0, 2)}
+	store.CreateAccount(from)
+	store.CreateAccount(to)
+
+	// Store a transfer and its withdrawal leg
+	transfer := models.Transfer{ID: uuid.New(), FromAccountID: from.ID, ToAccountID: to.ID, Currency: "USD", Amount: money.New(1000, 2), TimeStamp: time.Unix(1700000000, 0)}
+	if err := store.CreateTransfer(transfer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	withdrawal := models.Transaction{ID: uuid.New(), AccountID: from.ID, Type: utils.Withdrawal, Currency: "USD", Amount: transfer.Amount, TransferID: transfer.ID, TimeStamp: transfer.TimeStamp}
+	if err := store.CreateTransaction(withdrawal); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Validate the transfer, its status change and its legs
+	transfer.Status = utils.TransferReversed
+	if err := store.UpdateTransfer(transfer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found, err := store.FindTransfer(transfer.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if found.Status != utils.TransferReversed || found.Amount != transfer.Amount || !found.TimeStamp.Equal(transfer.TimeStamp) {
+		t.Errorf("Expected %+v, got %+v", transfer, found)
+	}
+	legs, err := store.FindTransferTransactions(transfer.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(legs) != 1 || legs[0].ID != withdrawal.ID || legs[0].TransferID != transfer.ID {
+		t.Errorf("Expected the withdrawal leg, got %+v", legs)
+	}
+
+	// Validate the listing by account
+	for accountID, expected := range map[uuid.UUID]int{to.ID: 1, uuid.New(): 0} {
+		transfers, err := store.SearchTransfers(models.TransferFilter{AccountID: accountID, Limit: 10})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(transfers) != expected {
+			t.Errorf("Expected %d transfers, got %d", expected, len(transfers))
+		}
+	}
+	if _, err := store.FindTransfer(uuid.New()); err != utils.ErrTransferNotFound {
+		t.Errorf("Expected error %v, got %v", utils.ErrTransferNotFound, err)
+	}
+}
+
+func TestSQLiteEvents(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	from := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "USD", Balance: money.New(0, 2)}
+	to := models.Account{ID: uuid.New(), Owner: "Bob", Currency: "USD", Balance: money.New(0, 2)}
+	store.CreateAccount(from)
+	store.CreateAccount(to)
+
+	// Append events, which are numbered in order
+	timestamp := time.Unix(1700000000, 0)
+	appended := []models.Event{
+		{Type: utils.EventAccountOpened, AccountID: from.ID, Currency: "USD", Amount: money.New(5000, 2), TimeStamp: timestamp},
+		{Type: utils.EventAccountOpened, AccountID: to.ID, Currency: "USD", Amount: money.New(0, 2), TimeStamp: timestamp},
+		{Type: utils.EventTransferCompleted, AccountID: from.ID, CounterpartyID: to.ID, ReferenceID: uuid.New(), Currency: "USD", Amount: money.New(1000, 2), Status: utils.Frozen, TimeStamp: timestamp},
+	}
+	for _, event := range appended {
+		if err := store.AppendEvent(event); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Validate reading the store from a sequence
+	events, err := store.FindEvents(1, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 2 || events[0].Sequence != 2 || events[1].Sequence != 3 {
+		t.Fatalf("Expected events 2 and 3, got %+v", events)
+	}
+	transfer := events[1]
+	if transfer.Type != utils.EventTransferCompleted || transfer.CounterpartyID != to.ID || transfer.ReferenceID != appended[2].ReferenceID ||
+		transfer.Amount != appended[2].Amount || transfer.Status != utils.Frozen || !transfer.TimeStamp.Equal(timestamp) {
+		t.Errorf("Expected %+v, got %+v", appended[2], transfer)
+	}
+
+	// The receiving account sees the transfer in its events
+	events, err = store.FindAccountEvents(models.EventFilter{AccountID: to.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 2 || events[1].Sequence != 3 {
+		t.Errorf("Expected the opening and the transfer, got %+v", events)
+	}
+}
+
+func TestSQLiteBalanceCheckpoints(t *testing.T) {
+	// Setup
+	store, err := storage.CreateSQLite(filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+	account := models.Account{ID: uuid.New(), Owner: "Alice", Currency: "USD", Balance: money.New(0, 2)}
+	store.CreateAccount(account)
+	if _, err := store.FindBalanceCheckpoint(account.ID, time.Time{}); err != utils.ErrCheckpointNotFound {
+		t.Errorf("Expected ErrCheckpointNotFound, got %v", err)
+	}
+
+	// Save two checkpoints a day apart
+	timestamp := time.Unix(1700000000, 0)
+	for version, units := range []int64{1000, 2500} {
+		store.AppendEvent(models.Event{Type: utils.EventFundsDeposited, AccountID: account.ID, Currency: "USD", Amount: money.New(units, 2), TimeStamp: timestamp})
+		checkpoint := models.AccountBalance{
+			AccountID: account.ID, Currency: "USD", Balance: money.New(units, 2), Held: money.New(100, 2), Overdraft: money.New(0, 2),
+			Status: utils.Active, Version: int64(version + 1), UpdatedAt: timestamp.Add(time.Duration(version) * 24 * time.Hour),
+		}
+		if err := store.SaveBalanceCheckpoint(checkpoint); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Validate finding the latest checkpoint overall and as of a time
+	latest, err := store.FindBalanceCheckpoint(account.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if latest.Version != 2 || latest.Balance.String() != "25.00" || latest.Held.String() != "1.00" || latest.Status != utils.Active {
+		t.Errorf("Expected version 2 with balance 25.00, got %+v", latest)
+	}
+	earlier, err := store.FindBalanceCheckpoint(account.ID, timestamp.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if earlier.Version != 1 || earlier.Balance.String() != "10.00" || !earlier.UpdatedAt.Equal(timestamp) {
+		t.Errorf("Expected version 1 with balance 10.00, got %+v", earlier)
+	}
+	if _, err := store.FindBalanceCheckpoint(account.ID, timestamp.Add(-time.Hour)); err != utils.ErrCheckpointNotFound {
+		t.Errorf("Expected ErrCheckpointNotFound, got %v", err)
+	}
+
+	// Account events are filtered by sequence and time
+	events, _ := store.FindAccountEvents(models.EventFilter{AccountID: account.ID, After: 1})
+	if len(events) != 1 || events[0].Sequence != 2 {
+		t.Errorf("Expected event 2, got %+v", events)
+	}
+	events, _ = store.FindAccountEvents(models.EventFilter{AccountID: account.ID, Until: timestamp.Add(-time.Hour)})
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %+v", events)
+	}
+}
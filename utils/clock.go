@@ -0,0 +1,25 @@
+package utils
+
+import "time"
+
+// Clock tells the current time. Services that depend on the date take one
+// so that tests can run them at a fixed time
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock tells the time of the system
+type SystemClock struct{}
+
+func (clock SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always tells the same time
+type FixedClock struct {
+	Time time.Time
+}
+
+func (clock FixedClock) Now() time.Time {
+	return clock.Time
+}
@@ -7,6 +7,10 @@ type TransactionType int8
 const (
 	Deposit TransactionType = iota
 	Withdrawal
+	Fee
+	Interest
+	Refund   // Returns money taken by a withdrawal
+	Reversal // Takes back money brought by a deposit
 	Invalid
 )
 
@@ -15,16 +19,314 @@ func (value TransactionType) String() string {
 		return "deposit"
 	} else if value == Withdrawal {
 		return "withdrawal"
+	} else if value == Fee {
+		return "fee"
+	} else if value == Interest {
+		return "interest"
+	} else if value == Refund {
+		return "refund"
+	} else if value == Reversal {
+		return "reversal"
 	}
 	return "invalid"
 }
 
+// IsDebit reports whether transactions of the type take money out of the account
+func (value TransactionType) IsDebit() bool {
+	return value == Withdrawal || value == Fee || value == Reversal
+}
+
 func ParseTransactionType(value string) (TransactionType, error) {
 	switch value {
 	case "deposit":
 		return Deposit, nil
 	case "withdrawal":
 		return Withdrawal, nil
+	case "fee":
+		return Fee, nil
+	case "interest":
+		return Interest, nil
+	case "refund":
+		return Refund, nil
+	case "reversal":
+		return Reversal, nil
 	}
 	return Invalid, fmt.Errorf("invalid transaction type: %s", value)
 }
+
+// PostingSide tells whether a ledger posting debits or credits its account
+type PostingSide int8
+
+const (
+	Debit PostingSide = iota
+	Credit
+)
+
+func (value PostingSide) String() string {
+	if value == Debit {
+		return "debit"
+	}
+	return "credit"
+}
+
+func ParsePostingSide(value string) (PostingSide, error) {
+	switch value {
+	case "debit":
+		return Debit, nil
+	case "credit":
+		return Credit, nil
+	}
+	return Debit, fmt.Errorf("invalid posting side: %s", value)
+}
+
+// AccountStatus tells which operations an account accepts
+type AccountStatus int8
+
+const (
+	Active AccountStatus = iota
+	Frozen
+	Closed
+)
+
+func (value AccountStatus) String() string {
+	switch value {
+	case Frozen:
+		return "frozen"
+	case Closed:
+		return "closed"
+	}
+	return "active"
+}
+
+func ParseAccountStatus(value string) (AccountStatus, error) {
+	switch value {
+	case "active":
+		return Active, nil
+	case "frozen":
+		return Frozen, nil
+	case "closed":
+		return Closed, nil
+	}
+	return Active, fmt.Errorf("invalid account status: %s", value)
+}
+
+// HolderRole tells what a customer holding an account may do with it
+type HolderRole int8
+
+const (
+	Owner HolderRole = iota
+	Signatory
+	Viewer
+)
+
+func (value HolderRole) String() string {
+	switch value {
+	case Signatory:
+		return "signatory"
+	case Viewer:
+		return "view-only"
+	}
+	return "owner"
+}
+
+// CanDebit reports whether the role may send money from the account
+func (value HolderRole) CanDebit() bool {
+	return value == Owner || value == Signatory
+}
+
+// CanManage reports whether the role may change the holders of the account
+func (value HolderRole) CanManage() bool {
+	return value == Owner
+}
+
+func ParseHolderRole(value string) (HolderRole, error) {
+	switch value {
+	case "owner":
+		return Owner, nil
+	case "signatory":
+		return Signatory, nil
+	case "view-only":
+		return Viewer, nil
+	}
+	return Viewer, fmt.Errorf("invalid holder role: %s", value)
+}
+
+// AccountType selects the rules an account enforces on its transactions
+type AccountType int8
+
+const (
+	Checking AccountType = iota
+	Savings
+	TermDeposit
+)
+
+func (value AccountType) String() string {
+	switch value {
+	case Savings:
+		return "savings"
+	case TermDeposit:
+		return "term"
+	}
+	return "checking"
+}
+
+func ParseAccountType(value string) (AccountType, error) {
+	switch value {
+	case "checking":
+		return Checking, nil
+	case "savings":
+		return Savings, nil
+	case "term":
+		return TermDeposit, nil
+	}
+	return Checking, fmt.Errorf("invalid account type: %s", value)
+}
+
+// DayCount is the convention that turns an annual interest rate into the
+// interest of one day
+type DayCount int8
+
+const (
+	Actual365 DayCount = iota // Every day earns 1/365 of the annual rate
+	Thirty360                 // Every month earns 30/360, whatever its length
+)
+
+func (value DayCount) String() string {
+	if value == Thirty360 {
+		return "30/360"
+	}
+	return "ACT/365"
+}
+
+func ParseDayCount(value string) (DayCount, error) {
+	switch value {
+	case "ACT/365":
+		return Actual365, nil
+	case "30/360":
+		return Thirty360, nil
+	}
+	return Actual365, fmt.Errorf("invalid day count convention: %s", value)
+}
+
+// TransferStatus tells how much of a transfer was reversed
+type TransferStatus int8
+
+const (
+	TransferCompleted TransferStatus = iota
+	TransferPartiallyReversed
+	TransferReversed
+)
+
+func (value TransferStatus) String() string {
+	switch value {
+	case TransferPartiallyReversed:
+		return "partially_reversed"
+	case TransferReversed:
+		return "reversed"
+	}
+	return "completed"
+}
+
+func ParseTransferStatus(value string) (TransferStatus, error) {
+	switch value {
+	case "completed":
+		return TransferCompleted, nil
+	case "partially_reversed":
+		return TransferPartiallyReversed, nil
+	case "reversed":
+		return TransferReversed, nil
+	}
+	return TransferCompleted, fmt.Errorf("invalid transfer status: %s", value)
+}
+
+// HoldStatus tells whether a hold still reserves funds
+type HoldStatus int8
+
+const (
+	HoldPending HoldStatus = iota
+	HoldCaptured
+	HoldReleased
+	HoldExpired
+)
+
+func (value HoldStatus) String() string {
+	switch value {
+	case HoldCaptured:
+		return "captured"
+	case HoldReleased:
+		return "released"
+	case HoldExpired:
+		return "expired"
+	}
+	return "pending"
+}
+
+func ParseHoldStatus(value string) (HoldStatus, error) {
+	switch value {
+	case "pending":
+		return HoldPending, nil
+	case "captured":
+		return HoldCaptured, nil
+	case "released":
+		return HoldReleased, nil
+	case "expired":
+		return HoldExpired, nil
+	}
+	return HoldPending, fmt.Errorf("invalid hold status: %s", value)
+}
+
+// EventType names the change to an account recorded by an event
+type EventType int8
+
+const (
+	EventAccountOpened EventType = iota
+	EventFundsDeposited
+	EventFundsWithdrawn
+	EventTransferCompleted
+	EventFundsHeld
+	EventFundsReleased
+	EventOverdraftChanged
+	EventAccountStatusChanged
+)
+
+func (value EventType) String() string {
+	switch value {
+	case EventFundsDeposited:
+		return "funds_deposited"
+	case EventFundsWithdrawn:
+		return "funds_withdrawn"
+	case EventTransferCompleted:
+		return "transfer_completed"
+	case EventFundsHeld:
+		return "funds_held"
+	case EventFundsReleased:
+		return "funds_released"
+	case EventOverdraftChanged:
+		return "overdraft_changed"
+	case EventAccountStatusChanged:
+		return "account_status_changed"
+	}
+	return "account_opened"
+}
+
+func ParseEventType(value string) (EventType, error) {
+	switch value {
+	case "account_opened":
+		return EventAccountOpened, nil
+	case "funds_deposited":
+		return EventFundsDeposited, nil
+	case "funds_withdrawn":
+		return EventFundsWithdrawn, nil
+	case "transfer_completed":
+		return EventTransferCompleted, nil
+	case "funds_held":
+		return EventFundsHeld, nil
+	case "funds_released":
+		return EventFundsReleased, nil
+	case "overdraft_changed":
+		return EventOverdraftChanged, nil
+	case "account_status_changed":
+		return EventAccountStatusChanged, nil
+	}
+	return EventAccountOpened, fmt.Errorf("invalid event type: %s", value)
+}
@@ -3,9 +3,55 @@ package utils
 import "fmt"
 
 var (
-	ErrInvalidUUID        = fmt.Errorf("invalid UUID")
-	ErrAccountNotFound    = fmt.Errorf("account not found")
-	ErrInvalidTxType      = fmt.Errorf("invalid transaction type")
-	ErrInsufficientFunds  = fmt.Errorf("insufficient funds")
-	ErrInvalidRequestBody = fmt.Errorf("invalid request body")
+	ErrInvalidUUID              = fmt.Errorf("invalid UUID")
+	ErrAccountNotFound          = fmt.Errorf("account not found")
+	ErrCustomerNotFound         = fmt.Errorf("customer not found")
+	ErrCustomerHasAccounts      = fmt.Errorf("customer still holds accounts")
+	ErrHolderNotFound           = fmt.Errorf("account holder not found")
+	ErrHolderNotPermitted       = fmt.Errorf("acting customer is not permitted on the account")
+	ErrLastAccountOwner         = fmt.Errorf("account must keep at least one owner")
+	ErrInvalidTxType            = fmt.Errorf("invalid transaction type")
+	ErrTransactionNotFound      = fmt.Errorf("transaction not found")
+	ErrTransferNotFound         = fmt.Errorf("transfer not found")
+	ErrSameAccountTransfer      = fmt.Errorf("cannot transfer to the same account")
+	ErrNotReversible            = fmt.Errorf("transaction cannot be reversed")
+	ErrReversalExceedsAmount    = fmt.Errorf("reversal exceeds the amount not yet reversed")
+	ErrInsufficientFunds        = fmt.Errorf("insufficient funds")
+	ErrAccountFrozen            = fmt.Errorf("account is frozen")
+	ErrAccountClosed            = fmt.Errorf("account is closed")
+	ErrAccountNotEmpty          = fmt.Errorf("account balance is not zero")
+	ErrAccountHasHolds          = fmt.Errorf("account has pending holds")
+	ErrOverdraftNotAllowed      = fmt.Errorf("only checking accounts can have an overdraft")
+	ErrOverdraftInUse           = fmt.Errorf("overdraft limit is below the overdrawn balance")
+	ErrInterestNotAllowed       = fmt.Errorf("only savings accounts and term deposits earn interest")
+	ErrInvalidInterestRate      = fmt.Errorf("invalid interest rate")
+	ErrInvalidDayCount          = fmt.Errorf("invalid day count convention")
+	ErrInvalidFeeRule           = fmt.Errorf("invalid fee rule")
+	ErrHoldNotFound             = fmt.Errorf("hold not found")
+	ErrHoldNotPending           = fmt.Errorf("hold is no longer pending")
+	ErrCaptureExceedsHold       = fmt.Errorf("capture amount exceeds the hold")
+	ErrAccrualNotFound          = fmt.Errorf("interest accrual not found")
+	ErrInvalidDateRange         = fmt.Errorf("invalid date range")
+	ErrAccountLocked            = fmt.Errorf("term deposit is locked until maturity")
+	ErrWithdrawalLimit          = fmt.Errorf("monthly withdrawal limit reached")
+	ErrInvalidAccountType       = fmt.Errorf("invalid account type")
+	ErrInvalidMaturity          = fmt.Errorf("invalid maturity date")
+	ErrInvalidStatusTransition  = fmt.Errorf("invalid account status transition")
+	ErrInvalidAmount            = fmt.Errorf("invalid amount")
+	ErrAmountPrecision          = fmt.Errorf("amount has too many decimal places")
+	ErrCurrencyRequired         = fmt.Errorf("currency required to filter or sort by balance")
+	ErrInvalidCurrency          = fmt.Errorf("invalid currency")
+	ErrCurrencyMismatch         = fmt.Errorf("currency mismatch")
+	ErrInvalidRate              = fmt.Errorf("invalid exchange rate")
+	ErrRateNotFound             = fmt.Errorf("exchange rate not found")
+	ErrUnbalancedEntry          = fmt.Errorf("journal entry debits and credits differ")
+	ErrIdempotencyKeyNotFound   = fmt.Errorf("idempotency key not found")
+	ErrIdempotencyKeyMismatch   = fmt.Errorf("idempotency key reused with a different request")
+	ErrIdempotencyKeyInProgress = fmt.Errorf("idempotency key request still in progress")
+	ErrInvalidRequestBody       = fmt.Errorf("invalid request body")
+	ErrInvalidQuery             = fmt.Errorf("invalid query parameter")
+	ErrCheckpointNotFound       = fmt.Errorf("balance checkpoint not found")
+	ErrNotOpenAsOf              = fmt.Errorf("account was not open at the given time")
+	ErrInvalidCursor            = fmt.Errorf("invalid cursor")
+	ErrUnknownStorageDriver     = fmt.Errorf("unknown storage driver")
 )
@@ -4,20 +4,105 @@ const (
 	// Common messages
 	MsgInvalidRequestBody = "Invalid request body"
 	MsgValidationFailed   = "Validation failed"
+	MsgInvalidQuery       = "Invalid query parameter"
+	MsgInvalidCursor      = "Invalid cursor"
 	MsgIDCannotBeEmpty    = "ID cannot be empty"
 	MsgInvalidUUID        = "Invalid Account UUID"
 	MsgAccountNotFound    = "Account not found"
+	MsgInvalidAdminToken  = "Invalid admin token"
+	MsgAdminDisabled      = "Admin endpoints are disabled until an admin token is configured"
+	MsgInvalidAmount      = "Invalid amount"
+	MsgAmountPrecision    = "Amount has more decimal places than the currency allows"
+	MsgInvalidCurrency    = "Invalid ISO 4217 currency code"
+	MsgCurrencyRequired   = "A currency is required to filter or sort by balance"
+	MsgCurrencyMismatch   = "Currency does not match the account currency"
+
+	// Idempotency specific messages
+	MsgInvalidIdempotencyKey    = "Idempotency key must be between 1 and 255 characters"
+	MsgIdempotencyKeyMismatch   = "Idempotency key was already used with a different request"
+	MsgIdempotencyKeyInProgress = "A request with this idempotency key is still in progress"
+	MsgFailedIdempotency        = "Failed to process idempotency key"
 
 	// Transaction specific messages
-	MsgFailedCreateTx      = "Failed to create transaction"
-	MsgInvalidTxType       = "Invalid transaction type"
-	MsgInsufficientFunds   = "Insufficient funds"
-	MsgFailedRetrieveTx    = "Failed to retrieve transactions"
-	MsgTransferSuccess     = "Successfully transferred"
-	MsgSameAccountTransfer = "From and To account IDs cannot be the same"
+	MsgFailedCreateTx          = "Failed to create transaction"
+	MsgInvalidTxType           = "Invalid transaction type"
+	MsgInsufficientFunds       = "Insufficient funds"
+	MsgFailedRetrieveTx        = "Failed to retrieve transactions"
+	MsgTransferNotFound        = "Transfer not found"
+	MsgInvalidTransferUUID     = "Invalid transfer ID format"
+	MsgFailedRetrieveTransfers = "Failed to retrieve transfers"
+	MsgSameAccountTransfer     = "From and To account IDs cannot be the same"
+	MsgRateNotFound            = "No exchange rate between the account currencies"
+	MsgTxNotFound              = "Transaction not found"
+	MsgInvalidTxUUID           = "Invalid transaction ID format"
+	MsgNotReversible           = "Only deposits, withdrawals and transfers can be reversed"
+	MsgReversalExceeds         = "Reversal exceeds the amount not yet reversed"
+	MsgReversalSuccess         = "Successfully reversed"
+	MsgFailedReverseTx         = "Failed to reverse transaction"
 
 	// Account specific messages
 	MsgFailedCreateAccount    = "Failed to create account"
 	MsgFailedRetrieveAccount  = "Failed to retrieve account"
 	MsgFailedRetrieveAccounts = "Failed to retrieve accounts"
+	MsgFailedUpdateAccount    = "Failed to update account"
+	MsgAccountFrozen          = "Account is frozen and cannot send money"
+	MsgAccountClosed          = "Account is closed"
+	MsgAccountNotEmpty        = "Only accounts with a zero balance can be closed"
+	MsgAccountHasHolds        = "Accounts with pending holds cannot be closed"
+	MsgInvalidStatusChange    = "Account status does not allow this change"
+	MsgAccountLocked          = "Term deposit is locked until its maturity date"
+	MsgWithdrawalLimit        = "Savings account reached its monthly withdrawal limit"
+	MsgInvalidMaturity        = "Term deposits need a maturity date in the future, other accounts none"
+	MsgOverdraftNotAllowed    = "Only checking accounts can have an overdraft"
+	MsgOverdraftInUse         = "Overdraft limit cannot be lower than the overdrawn balance"
+
+	// Customer specific messages
+	MsgInvalidCustomerUUID     = "Invalid Customer UUID"
+	MsgCustomerNotFound        = "Customer not found"
+	MsgCustomerHasAccounts     = "Customers holding accounts cannot be deleted"
+	MsgFailedCreateCustomer    = "Failed to create customer"
+	MsgFailedRetrieveCustomer  = "Failed to retrieve customer"
+	MsgFailedRetrieveCustomers = "Failed to retrieve customers"
+	MsgFailedUpdateCustomer    = "Failed to update customer"
+	MsgFailedDeleteCustomer    = "Failed to delete customer"
+
+	// Account holder specific messages
+	MsgHolderNotFound        = "Account holder not found"
+	MsgHolderNotPermitted    = "Acting customer is not permitted to do this on the account"
+	MsgLastAccountOwner      = "An account must keep at least one owner"
+	MsgFailedRetrieveHolders = "Failed to retrieve account holders"
+	MsgFailedUpdateHolders   = "Failed to update account holders"
+
+	// Exchange rate specific messages
+	MsgInvalidRate         = "Invalid exchange rate"
+	MsgFailedUpdateRates   = "Failed to update exchange rates"
+	MsgFailedRetrieveRates = "Failed to retrieve exchange rates"
+
+	// Fee specific messages
+	MsgInvalidFeeRule     = "Invalid fee rule: check the operation, account type, amounts and tier bounds"
+	MsgFailedRetrieveFees = "Failed to retrieve the fee schedule"
+	MsgFailedUpdateFees   = "Failed to update the fee schedule"
+
+	// Hold specific messages
+	MsgHoldNotFound        = "Hold not found"
+	MsgInvalidHoldUUID     = "Invalid hold ID format"
+	MsgHoldNotPending      = "Hold was already captured, released or expired"
+	MsgCaptureExceedsHold  = "Capture amount exceeds the hold"
+	MsgFailedPlaceHold     = "Failed to place hold"
+	MsgFailedRetrieveHolds = "Failed to retrieve holds"
+	MsgFailedUpdateHold    = "Failed to update hold"
+
+	// Interest specific messages
+	MsgInterestNotAllowed  = "Only savings accounts and term deposits earn interest"
+	MsgInvalidInterestRate = "Interest rate must be a positive annual fraction, e.g. 0.035"
+	MsgInvalidDateRange    = "Dates must be YYYY-MM-DD with from before to"
+	MsgFailedAccrual       = "Failed to accrue interest"
+
+	// Ledger specific messages
+	MsgFailedTrialBalance = "Failed to compute trial balance"
+
+	// Event specific messages
+	MsgFailedRetrieveEvents = "Failed to retrieve events"
+	MsgFailedProjectBalance = "Failed to project balances from events"
+	MsgNotOpenAsOf          = "Account was not open yet at the given time"
 )